@@ -31,6 +31,7 @@ func NewRootCommand() *cli.App {
 		Commands: []*cli.Command{
 			newAddRoleCommand(),
 			newBootstrapCommand(),
+			newRosterCommand(),
 			newServerCommand(),
 			newUpdateRosterCommand(),
 		},
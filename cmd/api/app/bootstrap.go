@@ -81,7 +81,17 @@ func newBootstrapCommand() *cli.Command {
 					&models.DelayedJob{},
 					&models.Document{},
 					&models.EventPosition{},
+					&models.EventPositionCertification{},
+					&models.EventShift{},
+					&models.EventNotification{},
+					&models.EventNoShow{},
+					&models.EventTemplate{},
+					&models.EventTemplatePosition{},
+					&models.StaffingRequest{},
+					&models.FlightMovement{},
+					&models.FlightLog{},
 					&models.Event{},
+					&models.EventFacility{},
 					&models.EventSignup{},
 					&models.Feedback{},
 					&models.Flights{},
@@ -95,6 +105,19 @@ func newBootstrapCommand() *cli.Command {
 					&models.VisitorApplication{},
 					&models.TrainingRequest{},
 					&models.TrainingRequestSlot{},
+					&models.TrainingAvailability{},
+					&models.SyllabusItem{},
+					&models.TrainingNoteProgress{},
+					&models.SoloEndorsement{},
+					&models.CertificationChange{},
+					&models.OTSRubric{},
+					&models.OTSRubricCategory{},
+					&models.OTSEvaluation{},
+					&models.OTSEvaluationScore{},
+					&models.PromotionRequest{},
+					&models.MentorAssignment{},
+					&models.CertificationPrerequisite{},
+					&models.RosterImport{},
 				)
 				if err != nil {
 					return err
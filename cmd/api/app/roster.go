@@ -0,0 +1,180 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/adh-partnership/api/pkg/config"
+	"github.com/adh-partnership/api/pkg/database"
+	"github.com/adh-partnership/api/pkg/logger"
+	"github.com/adh-partnership/api/pkg/rosterfile"
+)
+
+func newRosterCommand() *cli.Command {
+	configFlag := &cli.StringFlag{
+		Name:  "config",
+		Value: "config.yaml",
+		Usage: "Path to the configuration file",
+	}
+	formatFlag := &cli.StringFlag{
+		Name:  "format",
+		Value: rosterfile.FormatCSV,
+		Usage: "File format (accepted values: csv, json)",
+	}
+
+	return &cli.Command{
+		Name:  "roster",
+		Usage: "Export or import roster certifications",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "export",
+				Usage: "Export the roster with every certification",
+				Flags: []cli.Flag{
+					configFlag,
+					formatFlag,
+					&cli.StringFlag{
+						Name:    "output",
+						Aliases: []string{"o"},
+						Usage:   "File to write to, defaults to stdout",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					if !rosterfile.IsValidFormat(c.String("format")) {
+						return fmt.Errorf("invalid format: %s", c.String("format"))
+					}
+					if err := connectRosterDatabase(c.String("config")); err != nil {
+						return err
+					}
+
+					var w io.Writer = os.Stdout
+					if c.String("output") != "" {
+						f, err := os.Create(c.String("output"))
+						if err != nil {
+							return err
+						}
+						defer f.Close()
+						w = f
+					}
+
+					return rosterfile.Export(w, c.String("format"))
+				},
+			},
+			{
+				Name:  "import",
+				Usage: "Import certifications from a roster file, as a dry run unless --apply is set",
+				Flags: []cli.Flag{
+					configFlag,
+					formatFlag,
+					&cli.StringFlag{
+						Name:     "file",
+						Aliases:  []string{"f"},
+						Required: true,
+						Usage:    "Roster file to import",
+					},
+					&cli.UintFlag{
+						Name:  "actor",
+						Usage: "CID recorded as making the changes",
+					},
+					&cli.StringFlag{
+						Name:  "reason",
+						Usage: "Reason recorded against every change",
+					},
+					&cli.BoolFlag{
+						Name:  "override",
+						Usage: "Set certifications even if their prerequisites are not met, requires --reason",
+					},
+					&cli.BoolFlag{
+						Name:  "apply",
+						Usage: "Apply the changes instead of doing a dry run",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					log := logger.Logger.WithField("component", "roster")
+					if !rosterfile.IsValidFormat(c.String("format")) {
+						return fmt.Errorf("invalid format: %s", c.String("format"))
+					}
+					if err := connectRosterDatabase(c.String("config")); err != nil {
+						return err
+					}
+
+					f, err := os.Open(c.String("file"))
+					if err != nil {
+						return err
+					}
+					defer f.Close()
+
+					opts := rosterfile.ImportOptions{
+						Format:   c.String("format"),
+						Reason:   c.String("reason"),
+						Override: c.Bool("override"),
+						DryRun:   !c.Bool("apply"),
+					}
+					if c.IsSet("actor") {
+						actor := c.Uint("actor")
+						opts.ActorID = &actor
+					}
+
+					result, err := rosterfile.Import(f, opts)
+					if err != nil {
+						return err
+					}
+
+					enc := json.NewEncoder(os.Stdout)
+					enc.SetIndent("", "  ")
+					if err := enc.Encode(result); err != nil {
+						return err
+					}
+
+					if len(result.Errors) > 0 {
+						return fmt.Errorf("import has %d errors, nothing was changed", len(result.Errors))
+					}
+					if result.DryRun {
+						log.Infof("Dry run found %d changes, run with --apply to apply them", len(result.Changes))
+					} else {
+						log.Infof("Applied %d changes as import %d", len(result.Changes), result.Import.ID)
+					}
+
+					return nil
+				},
+			},
+		},
+	}
+}
+
+func connectRosterDatabase(configfile string) error {
+	cfg, err := config.ParseConfig(configfile)
+	if err != nil {
+		return err
+	}
+	config.Cfg = cfg
+
+	return database.Connect(database.DBOptions{
+		Host:     cfg.Database.Host,
+		Port:     cfg.Database.Port,
+		User:     cfg.Database.User,
+		Password: cfg.Database.Password,
+		Database: cfg.Database.Database,
+		Driver:   "mysql",
+		Logger:   logger.Logger,
+	})
+}
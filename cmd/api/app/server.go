@@ -28,7 +28,10 @@ import (
 
 	"github.com/adh-partnership/api/pkg/jobs/activity"
 	"github.com/adh-partnership/api/pkg/jobs/dataparser"
+	"github.com/adh-partnership/api/pkg/jobs/events"
 	"github.com/adh-partnership/api/pkg/jobs/roster"
+	"github.com/adh-partnership/api/pkg/jobs/solo"
+	"github.com/adh-partnership/api/pkg/jobs/trainingsync"
 	"github.com/adh-partnership/api/pkg/jobs/weather"
 	"github.com/adh-partnership/api/pkg/logger"
 	"github.com/adh-partnership/api/pkg/server"
@@ -67,6 +70,11 @@ func newServerCommand() *cli.Command {
 			if err != nil {
 				return err
 			}
+			log.Info(" - Events")
+			err = events.ScheduleJobs(s)
+			if err != nil {
+				return err
+			}
 			log.Info(" - Roster")
 			err = roster.ScheduleJobs(s)
 			if err != nil {
@@ -77,6 +85,16 @@ func newServerCommand() *cli.Command {
 			if err != nil {
 				return err
 			}
+			log.Info(" - Solo Endorsements")
+			err = solo.ScheduleJobs(s)
+			if err != nil {
+				return err
+			}
+			log.Info(" - Training Note Sync")
+			err = trainingsync.ScheduleJobs(s)
+			if err != nil {
+				return err
+			}
 			log.Info(" - Weather Data Parser")
 			err = weather.ScheduleJobs(s)
 			if err != nil {
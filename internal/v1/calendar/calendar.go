@@ -0,0 +1,307 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package calendar
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/adh-partnership/api/pkg/config"
+	"github.com/adh-partnership/api/pkg/database"
+	"github.com/adh-partnership/api/pkg/database/models"
+	"github.com/adh-partnership/api/pkg/database/models/constants"
+	"github.com/adh-partnership/api/pkg/gin/response"
+	"github.com/adh-partnership/api/pkg/ical"
+)
+
+// Entries that ended or were cancelled longer ago than this are left out of the feeds
+const feedHistory = 30 * 24 * time.Hour
+
+type CalendarToken struct {
+	Token string `json:"token"`
+	Path  string `json:"path"`
+}
+
+// Get Events Feed
+// @Summary Get Events Feed
+// @Description Get the facility's events as an iCalendar feed. Events deleted in the last 30 days are included as
+// @Description cancelled so subscribed calendars remove them.
+// @Tags calendar
+// @Produce text/calendar
+// @Success 200 {string} string
+// @Failure 500 {object} response.R
+// @Router /v1/calendar/events [GET]
+func getEventsFeed(c *gin.Context) {
+	now := time.Now()
+	events, err := database.FindCalendarEvents(now.Add(-feedHistory))
+	if err != nil {
+		log.Errorf("Error getting events for calendar: %s", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	cal := &ical.Calendar{Name: "Events"}
+	for _, event := range events {
+		e := &ical.Event{
+			UID:          uid("event", event.ID),
+			Sequence:     ical.Sequence(event.CreatedAt, event.UpdatedAt),
+			Summary:      event.Title,
+			Description:  event.Description,
+			URL:          frontendURL(fmt.Sprintf("/events/%d", event.ID)),
+			Start:        event.StartDate,
+			End:          event.EndDate,
+			LastModified: event.UpdatedAt,
+		}
+		cancelDeleted(e, event, event.CreatedAt)
+		cal.Events = append(cal.Events, e)
+	}
+
+	respondCalendar(c, cal, now)
+}
+
+// Get Personal Feed
+// @Summary Get Personal Feed
+// @Description Get the event positions and shifts a user is assigned to, and their scheduled training sessions as
+// @Description student or instructor, as an iCalendar feed. The token is the user's calendar token. Assignments to
+// @Description events deleted in the last 30 days are included as cancelled.
+// @Tags calendar
+// @Produce text/calendar
+// @Param token path string true "Calendar token"
+// @Success 200 {string} string
+// @Failure 404 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/calendar/personal/{token} [GET]
+func getPersonalFeed(c *gin.Context) {
+	user, err := database.FindUserByCalendarToken(c.Param("token"))
+	if err != nil {
+		log.Errorf("Error getting user by calendar token: %s", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+	if user == nil {
+		response.RespondError(c, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	now := time.Now()
+	since := now.Add(-feedHistory)
+
+	positions, err := database.FindUserEventPositions(user.CID, since)
+	if err != nil {
+		log.Errorf("Error getting event positions of %d for calendar: %s", user.CID, err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	shifts, err := database.FindUserEventShifts(user.CID, since)
+	if err != nil {
+		log.Errorf("Error getting event shifts of %d for calendar: %s", user.CID, err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	sessions, err := database.FindUserTrainingSessions(user.CID, since)
+	if err != nil {
+		log.Errorf("Error getting training sessions of %d for calendar: %s", user.CID, err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	cal := &ical.Calendar{Name: fmt.Sprintf("%s %s", user.FirstName, user.LastName)}
+	for _, position := range positions {
+		event := &position.Event
+		e := &ical.Event{
+			UID:          uid("event-position", position.ID),
+			Sequence:     ical.Sequence(position.CreatedAt, latest(position.UpdatedAt, event.UpdatedAt)),
+			Summary:      fmt.Sprintf("%s: %s", position.Position, event.Title),
+			Description:  event.Description,
+			URL:          frontendURL(fmt.Sprintf("/events/%d", event.ID)),
+			Start:        event.StartDate,
+			End:          event.EndDate,
+			LastModified: latest(position.UpdatedAt, event.UpdatedAt),
+		}
+		cancelDeleted(e, event, position.CreatedAt)
+		cal.Events = append(cal.Events, e)
+	}
+
+	for _, shift := range shifts {
+		position := &shift.EventPosition
+		event := &position.Event
+		e := &ical.Event{
+			UID:          uid("event-shift", shift.ID),
+			Sequence:     ical.Sequence(shift.CreatedAt, latest(shift.UpdatedAt, event.UpdatedAt)),
+			Summary:      fmt.Sprintf("%s: %s", position.Position, event.Title),
+			Description:  event.Description,
+			URL:          frontendURL(fmt.Sprintf("/events/%d", event.ID)),
+			Start:        shift.StartsAt,
+			End:          shift.EndsAt,
+			LastModified: latest(shift.UpdatedAt, event.UpdatedAt),
+		}
+		cancelDeleted(e, event, shift.CreatedAt)
+		cal.Events = append(cal.Events, e)
+	}
+
+	for _, session := range sessions {
+		cal.Events = append(cal.Events, trainingSessionEvent(session, user))
+	}
+
+	respondCalendar(c, cal, now)
+}
+
+// Get Calendar Token
+// @Summary Get Calendar Token
+// @Description Get the token for the logged in user's personal calendar feed, creating one if needed
+// @Tags calendar
+// @Success 200 {object} CalendarToken
+// @Failure 401 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/calendar/token [GET]
+func getCalendarToken(c *gin.Context) {
+	user := c.MustGet("x-user").(*models.User)
+
+	if user.CalendarToken == "" {
+		if err := rotateCalendarToken(user); err != nil {
+			log.Errorf("Error creating calendar token for %d: %s", user.CID, err)
+			response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+	}
+
+	response.Respond(c, http.StatusOK, &CalendarToken{Token: user.CalendarToken, Path: "/v1/calendar/personal/" + user.CalendarToken})
+}
+
+// Reset Calendar Token
+// @Summary Reset Calendar Token
+// @Description Replace the token for the logged in user's personal calendar feed, so the old feed URL stops working
+// @Tags calendar
+// @Success 200 {object} CalendarToken
+// @Failure 401 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/calendar/token [POST]
+func postCalendarToken(c *gin.Context) {
+	user := c.MustGet("x-user").(*models.User)
+
+	if err := rotateCalendarToken(user); err != nil {
+		log.Errorf("Error resetting calendar token for %d: %s", user.CID, err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	response.Respond(c, http.StatusOK, &CalendarToken{Token: user.CalendarToken, Path: "/v1/calendar/personal/" + user.CalendarToken})
+}
+
+func trainingSessionEvent(session *models.TrainingRequest, user *models.User) *ical.Event {
+	with := session.Instructor
+	if session.InstructorID != nil && *session.InstructorID == user.CID {
+		with = session.Student
+	}
+
+	e := &ical.Event{
+		UID:         fmt.Sprintf("training-%s@%s", session.ID, uidDomain()),
+		Summary:     "Training: " + session.Position,
+		Description: session.Notes,
+		Start:       *session.Start,
+		End:         *session.End,
+	}
+	if with != nil {
+		e.Summary = fmt.Sprintf("Training: %s with %s %s", session.Position, with.FirstName, with.LastName)
+	}
+	if session.CreatedAt != nil && session.UpdatedAt != nil {
+		e.Sequence = ical.Sequence(*session.CreatedAt, *session.UpdatedAt)
+		e.LastModified = *session.UpdatedAt
+	}
+	if session.Status == constants.TrainingSessionStatusCancelled {
+		e.Status = ical.StatusCancelled
+	}
+
+	return e
+}
+
+// cancelDeleted marks the entry for an event, or an assignment to it, as cancelled if the event was deleted, so
+// subscribed calendars remove it
+func cancelDeleted(e *ical.Event, event *models.Event, created time.Time) {
+	if !event.DeletedAt.Valid {
+		return
+	}
+
+	e.Status = ical.StatusCancelled
+	e.Sequence = ical.Sequence(created, event.DeletedAt.Time)
+	e.LastModified = event.DeletedAt.Time
+}
+
+func rotateCalendarToken(user *models.User) error {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return err
+	}
+
+	token := hex.EncodeToString(b)
+	if err := database.DB.Model(&models.User{}).Where("cid = ?", user.CID).Update("calendar_token", token).Error; err != nil {
+		return err
+	}
+	user.CalendarToken = token
+
+	return nil
+}
+
+func respondCalendar(c *gin.Context, cal *ical.Calendar, now time.Time) {
+	var b bytes.Buffer
+	if err := cal.Write(&b, now); err != nil {
+		log.Errorf("Error writing calendar: %s", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", b.Bytes())
+}
+
+func uid(kind string, id uint) string {
+	return fmt.Sprintf("%s-%d@%s", kind, id, uidDomain())
+}
+
+// uidDomain returns the domain UIDs are qualified with so they are unique across facilities
+func uidDomain() string {
+	if u, err := url.Parse(config.Cfg.Facility.FrontendURL); err == nil && u.Hostname() != "" {
+		return u.Hostname()
+	}
+
+	return strings.ToLower(config.Cfg.VATUSA.Facility) + ".adh-partnership"
+}
+
+func frontendURL(path string) string {
+	if config.Cfg.Facility.FrontendURL == "" {
+		return ""
+	}
+
+	return strings.TrimSuffix(config.Cfg.Facility.FrontendURL, "/") + path
+}
+
+func latest(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+
+	return b
+}
@@ -32,6 +32,15 @@ type CertificationDTO struct {
 	DisplayName string `json:"display_name"`
 	Order       uint   `json:"order"`
 	Hidden      bool   `json:"hidden"`
+	// Short name of the minimum rating needed to be solo or certified, empty for none
+	MinRating string `json:"min_rating"`
+	// Prerequisite rules, left unchanged on update if omitted
+	Prerequisites []*CertificationPrerequisiteDTO `json:"prerequisites"`
+}
+
+type CertificationPrerequisiteDTO struct {
+	Requires string `json:"requires"`
+	MinDays  int    `json:"min_days"`
 }
 
 // Get certification types
@@ -68,6 +77,17 @@ func postCertifications(c *gin.Context) {
 		return
 	}
 
+	minRatingID, prerequisites, msg, err := buildCertificationRules(certificationDTO.Name, &certificationDTO)
+	if err != nil {
+		log.Errorf("Failed to validate certification rules: %+v", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+	if msg != "" {
+		response.RespondError(c, http.StatusBadRequest, msg)
+		return
+	}
+
 	// If no order is specified, set it to the max order + 1
 	if certificationDTO.Order == 0 {
 		var maxOrder uint
@@ -79,12 +99,24 @@ func postCertifications(c *gin.Context) {
 		}
 	}
 
-	if err := database.DB.Create(&models.Certification{
-		DisplayName: certificationDTO.DisplayName,
-		Name:        certificationDTO.Name,
-		Order:       certificationDTO.Order,
-		Hidden:      certificationDTO.Hidden,
-	}).Error; err != nil {
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&models.Certification{
+			DisplayName: certificationDTO.DisplayName,
+			Name:        certificationDTO.Name,
+			Order:       certificationDTO.Order,
+			Hidden:      certificationDTO.Hidden,
+			MinRatingID: minRatingID,
+		}).Error; err != nil {
+			return err
+		}
+
+		if len(prerequisites) > 0 {
+			return tx.Create(&prerequisites).Error
+		}
+
+		return nil
+	})
+	if err != nil {
 		log.Errorf("Failed to create certification: %+v", err)
 		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
 		return
@@ -170,22 +202,71 @@ func putCertifications(c *gin.Context) {
 		return
 	}
 
+	minRatingID, prerequisites, msg, err := buildCertificationRules(c.Param("name"), &certificationDTO)
+	if err != nil {
+		log.Errorf("Failed to validate certification rules: %+v", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+	if msg != "" {
+		response.RespondError(c, http.StatusBadRequest, msg)
+		return
+	}
+
 	cert.Name = certificationDTO.Name
 	cert.DisplayName = certificationDTO.DisplayName
 	cert.Order = certificationDTO.Order
 	cert.Hidden = certificationDTO.Hidden
+	cert.MinRatingID = minRatingID
 
-	err := database.DB.Transaction(func(tx *gorm.DB) error {
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
 		if err := tx.Save(cert).Error; err != nil {
 			return err
 		}
 
+		if certificationDTO.Prerequisites != nil {
+			if err := tx.Where(models.CertificationPrerequisite{Certification: c.Param("name")}).
+				Delete(&models.CertificationPrerequisite{}).Error; err != nil {
+				return err
+			}
+			if len(prerequisites) > 0 {
+				if err := tx.Create(&prerequisites).Error; err != nil {
+					return err
+				}
+			}
+		}
+
 		if err := tx.Model(&models.UserCertification{}).Where(&models.UserCertification{
 			Name: c.Param("name"),
 		}).Update("name", certificationDTO.Name).Error; err != nil {
 			return err
 		}
 
+		// Keep the change history attached to the renamed certification
+		if err := tx.Model(&models.CertificationChange{}).Where(&models.CertificationChange{
+			Certification: c.Param("name"),
+		}).Update("certification", certificationDTO.Name).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.CertificationPrerequisite{}).Where(&models.CertificationPrerequisite{
+			Certification: c.Param("name"),
+		}).Update("certification", certificationDTO.Name).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.CertificationPrerequisite{}).Where(&models.CertificationPrerequisite{
+			Requires: c.Param("name"),
+		}).Update("requires", certificationDTO.Name).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.EventPositionCertification{}).Where(&models.EventPositionCertification{
+			Certification: c.Param("name"),
+		}).Update("certification", certificationDTO.Name).Error; err != nil {
+			return err
+		}
+
 		return nil
 	})
 	if err != nil {
@@ -232,6 +313,16 @@ func deleteCertifications(c *gin.Context) {
 			return err
 		}
 
+		if err := tx.Where("certification = ? OR requires = ?", c.Param("name"), c.Param("name")).
+			Delete(&models.CertificationPrerequisite{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where(models.EventPositionCertification{Certification: c.Param("name")}).
+			Delete(&models.EventPositionCertification{}).Error; err != nil {
+			return err
+		}
+
 		return nil
 	})
 	if err != nil {
@@ -244,3 +335,72 @@ func deleteCertifications(c *gin.Context) {
 
 	response.Respond(c, http.StatusNoContent, nil)
 }
+
+// buildCertificationRules validates the rating and prerequisite rules of a certification, currently named name,
+// returning a message for the user if they are invalid. If the request leaves the prerequisites unchanged, none are
+// returned.
+func buildCertificationRules(name string, data *CertificationDTO) (int, []*models.CertificationPrerequisite, string, error) {
+	var minRatingID int
+	if data.MinRating != "" {
+		rating, err := database.FindRatingByShort(data.MinRating)
+		if err != nil {
+			return 0, nil, "", err
+		}
+		if rating == nil {
+			return 0, nil, "Invalid minimum rating", nil
+		}
+		minRatingID = rating.ID
+	}
+
+	if data.Prerequisites == nil {
+		return minRatingID, nil, "", nil
+	}
+
+	existing, err := database.FindCertificationPrerequisites(database.DB, "")
+	if err != nil {
+		return 0, nil, "", err
+	}
+
+	// Check for cycles against the other certifications' rules, with this certification under its new name
+	all := []*models.CertificationPrerequisite{}
+	for _, p := range existing {
+		if p.Certification == name {
+			continue
+		}
+		rule := *p
+		if rule.Requires == name {
+			rule.Requires = data.Name
+		}
+		all = append(all, &rule)
+	}
+
+	var prerequisites []*models.CertificationPrerequisite
+	seen := map[string]bool{}
+	for _, p := range data.Prerequisites {
+		if p == nil || p.MinDays < 0 {
+			return 0, nil, "Invalid prerequisite", nil
+		}
+		if p.Requires == name || p.Requires == data.Name {
+			return 0, nil, "A certification cannot require itself", nil
+		}
+		if !database.ValidCertification(p.Requires) {
+			return 0, nil, "Invalid prerequisite certification " + p.Requires, nil
+		}
+		if seen[p.Requires] {
+			return 0, nil, "Duplicate prerequisite " + p.Requires, nil
+		}
+		seen[p.Requires] = true
+
+		prerequisites = append(prerequisites, &models.CertificationPrerequisite{
+			Certification: data.Name,
+			Requires:      p.Requires,
+			MinDays:       p.MinDays,
+		})
+	}
+
+	if database.HasPrerequisiteCycle(append(all, prerequisites...)) {
+		return 0, nil, "Prerequisites would form a cycle", nil
+	}
+
+	return minRatingID, prerequisites, "", nil
+}
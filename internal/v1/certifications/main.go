@@ -29,6 +29,7 @@ func Routes(r *gin.RouterGroup) {
 	r.GET("", getCertifications)
 	r.POST("", auth.NotGuest, auth.InGroup("admin"), postCertifications)
 	r.PATCH("/bulk-order", auth.NotGuest, auth.InGroup("admin"), patchBulkOrder)
+	r.GET("/violations", auth.NotGuest, auth.InGroup("admin"), getViolations)
 	r.DELETE("/:name", auth.NotGuest, auth.InGroup("admin"), deleteCertifications)
 	r.PUT("/:name", auth.NotGuest, auth.InGroup("admin"), putCertifications)
 }
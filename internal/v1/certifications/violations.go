@@ -0,0 +1,121 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package certifications
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/adh-partnership/api/pkg/database"
+	"github.com/adh-partnership/api/pkg/database/models"
+	"github.com/adh-partnership/api/pkg/gin/response"
+)
+
+type CertificationViolationDTO struct {
+	CID           uint     `json:"cid"`
+	FirstName     string   `json:"first_name"`
+	LastName      string   `json:"last_name"`
+	Certification string   `json:"certification"`
+	Value         string   `json:"value"`
+	Violations    []string `json:"violations"`
+}
+
+// Get certification rule violations
+// @Summary Get certification rule violations
+// @Description Get the users on the roster holding a certification without meeting its prerequisites, for example
+// @Description because they were set before the rules existed or by an override
+// @Tags certifications
+// @Success 200 {object} []CertificationViolationDTO
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/certifications/violations [get]
+func getViolations(c *gin.Context) {
+	prerequisites, err := database.FindCertificationPrerequisites(database.DB, "")
+	if err != nil {
+		log.Errorf("Failed to get certification prerequisites: %+v", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	holders, err := database.FindCertificationHolders(database.DB, nil)
+	if err != nil {
+		log.Errorf("Failed to get certification holders: %+v", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	ratings, err := database.FindRatingNames(database.DB)
+	if err != nil {
+		log.Errorf("Failed to get ratings: %+v", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	now := time.Now()
+	ret := []*CertificationViolationDTO{}
+	for _, holder := range holders {
+		for _, cert := range database.GetCertifications() {
+			value := holder.Values[cert.Name]
+			violations := database.CheckPrerequisites(cert, prerequisites, value, holder, ratings, now)
+			if len(violations) == 0 {
+				continue
+			}
+			ret = append(ret, &CertificationViolationDTO{
+				CID:           holder.CID,
+				Certification: cert.Name,
+				Value:         value,
+				Violations:    violations,
+			})
+		}
+	}
+
+	var cids []uint
+	for _, v := range ret {
+		cids = append(cids, v.CID)
+	}
+	if len(cids) > 0 {
+		var users []*models.User
+		if err := database.DB.Select("cid", "first_name", "last_name").Where("cid IN ?", cids).Find(&users).Error; err != nil {
+			log.Errorf("Failed to get users: %+v", err)
+			response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+		names := map[uint]*models.User{}
+		for _, u := range users {
+			names[u.CID] = u
+		}
+		for _, v := range ret {
+			if u, ok := names[v.CID]; ok {
+				v.FirstName = u.FirstName
+				v.LastName = u.LastName
+			}
+		}
+	}
+
+	sort.SliceStable(ret, func(i, j int) bool {
+		if ret[i].CID != ret[j].CID {
+			return ret[i].CID < ret[j].CID
+		}
+		return ret[i].Certification < ret[j].Certification
+	})
+
+	response.Respond(c, http.StatusOK, ret)
+}
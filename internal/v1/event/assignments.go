@@ -0,0 +1,382 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package event
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/adh-partnership/api/pkg/database"
+	"github.com/adh-partnership/api/pkg/database/dto"
+	"github.com/adh-partnership/api/pkg/database/models"
+	"github.com/adh-partnership/api/pkg/database/models/constants"
+	"github.com/adh-partnership/api/pkg/events"
+	"github.com/adh-partnership/api/pkg/gin/response"
+)
+
+// Solve Event Assignments
+// @Summary Solve Event Assignments
+// @Description Propose a roster for the event's unlocked positions from the signup choices and availability, filling
+// @Description each shift of positions split into shifts. The proposal is stored as a draft, replacing any previous
+// @Description one, and is not visible on the positions until it is published.
+// @Tags Events
+// @Param id path string true "Event ID"
+// @Success 200 {object} dto.EventAssignmentDraft
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 404 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/events/{id}/assignments/solve [post]
+func postSolveAssignments(c *gin.Context) {
+	event, err := database.GetEvent(c.Param("id"))
+	if err != nil {
+		log.Errorf("Error getting event: %s", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	if event == nil {
+		response.RespondError(c, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	ratings, err := database.FindRatingNames(database.DB)
+	if err != nil {
+		log.Errorf("Error getting ratings: %s", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	// Controllers locked into a position or one of its shifts are not available for any other
+	locked := map[uint]bool{}
+	var slots []*events.Slot
+	roster := map[rosterKey]*rosterSlot{}
+	for _, rs := range buildRosterSlots(event, ratings) {
+		if rs.Position.Locked {
+			if cid, _ := rs.assigned(); cid != nil {
+				locked[*cid] = true
+			}
+			continue
+		}
+		slots = append(slots, rs.Slot)
+		roster[rs.key()] = rs
+	}
+
+	candidates, err := buildCandidates(event, locked)
+	if err != nil {
+		log.Errorf("Error getting certifications for event %d signups: %s", event.ID, err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	solution := events.Solve(slots, candidates)
+
+	now := time.Now()
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		for _, a := range solution.Assignments {
+			var m interface{} = &models.EventPosition{}
+			id := a.SlotID
+			if rs := roster[rosterKey{a.SlotID, a.ShiftID}]; rs.Shift != nil {
+				m = &models.EventShift{}
+				id = rs.Shift.ID
+			}
+			if err := tx.Model(m).Where("id = ?", id).Update("proposed_user_id", a.CID).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&models.Event{}).Where("id = ?", event.ID).Update("assignment_draft_at", now).Error
+	})
+	if err != nil {
+		log.Errorf("Error saving assignment draft for event %d: %s", event.ID, err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	respondAssignmentDraft(c, c.Param("id"))
+}
+
+// Get Event Assignment Draft
+// @Summary Get Event Assignment Draft
+// @Description Get the roster proposed by the assignment solver, with locked positions included as they stand
+// @Tags Events
+// @Param id path string true "Event ID"
+// @Success 200 {object} dto.EventAssignmentDraft
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 404 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/events/{id}/assignments/draft [get]
+func getAssignmentDraft(c *gin.Context) {
+	respondAssignmentDraft(c, c.Param("id"))
+}
+
+// Publish Event Assignment Draft
+// @Summary Publish Event Assignment Draft
+// @Description Assign every unlocked position and shift the draft filled to the controller proposed for it, keeping
+// @Description the current assignment of those it left unfilled, and notify the assigned controllers if event
+// @Description notifications are enabled
+// @Tags Events
+// @Param id path string true "Event ID"
+// @Success 200 {object} dto.EventsResponse
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 404 {object} response.R
+// @Failure 409 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/events/{id}/assignments/publish [post]
+func postPublishAssignments(c *gin.Context) {
+	event, err := database.GetEvent(c.Param("id"))
+	if err != nil {
+		log.Errorf("Error getting event: %s", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	if event == nil {
+		response.RespondError(c, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	if event.AssignmentDraftAt == nil {
+		response.RespondError(c, http.StatusConflict, "No assignment draft to publish")
+		return
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		// Positions staffed by partner facilities are never in the draft, and slots the draft left unfilled keep
+		// their current assignment
+		if err := tx.Model(&models.EventPosition{}).Where(
+			"event_id = ? AND locked = ? AND facility = ? AND proposed_user_id IS NOT NULL", event.ID, false, "",
+		).Updates(map[string]interface{}{
+			"user_id":          gorm.Expr("proposed_user_id"),
+			"proposed_user_id": nil,
+		}).Error; err != nil {
+			return err
+		}
+		unlocked := tx.Model(&models.EventPosition{}).Select("id").Where("event_id = ? AND locked = ? AND facility = ?", event.ID, false, "")
+		if err := tx.Model(&models.EventShift{}).Where("event_position_id IN (?) AND proposed_user_id IS NOT NULL", unlocked).Updates(map[string]interface{}{
+			"user_id":          gorm.Expr("proposed_user_id"),
+			"proposed_user_id": nil,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Event{}).Where("id = ?", event.ID).Updates(map[string]interface{}{
+			"assignment_draft_at":      nil,
+			"assignments_published_at": time.Now(),
+		}).Error
+	})
+	if err != nil {
+		log.Errorf("Error publishing assignments for event %d: %s", event.ID, err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	event, err = database.GetEvent(c.Param("id"))
+	if err != nil {
+		log.Errorf("Error getting event: %s", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	if events.NotificationsEnabled() {
+		go notifyAssignments(event)
+	}
+
+	response.Respond(c, http.StatusOK, dto.ConvEventToEventsResponse(event))
+}
+
+// Discard Event Assignment Draft
+// @Summary Discard Event Assignment Draft
+// @Tags Events
+// @Param id path string true "Event ID"
+// @Success 204
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 404 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/events/{id}/assignments/draft [delete]
+func deleteAssignmentDraft(c *gin.Context) {
+	event, err := database.GetEvent(c.Param("id"))
+	if err != nil {
+		log.Errorf("Error getting event: %s", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	if event == nil || event.AssignmentDraftAt == nil {
+		response.RespondError(c, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.EventPosition{}).Where("event_id = ?", event.ID).Update("proposed_user_id", nil).Error; err != nil {
+			return err
+		}
+		positions := tx.Model(&models.EventPosition{}).Select("id").Where("event_id = ?", event.ID)
+		if err := tx.Model(&models.EventShift{}).Where("event_position_id IN (?)", positions).Update("proposed_user_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Event{}).Where("id = ?", event.ID).Update("assignment_draft_at", nil).Error
+	})
+	if err != nil {
+		log.Errorf("Error discarding assignment draft for event %d: %s", event.ID, err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	response.RespondBlank(c, http.StatusNoContent)
+}
+
+func respondAssignmentDraft(c *gin.Context, id string) {
+	event, err := database.GetEvent(id)
+	if err != nil {
+		log.Errorf("Error getting event: %s", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	if event == nil || event.AssignmentDraftAt == nil {
+		response.RespondError(c, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	candidates, err := buildCandidates(event, nil)
+	if err != nil {
+		log.Errorf("Error getting certifications for event %d signups: %s", event.ID, err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	ratings, err := database.FindRatingNames(database.DB)
+	if err != nil {
+		log.Errorf("Error getting ratings: %s", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	var slots []*events.Slot
+	roster := map[rosterKey]*rosterSlot{}
+	proposed := map[*events.Slot]uint{}
+	users := map[rosterKey]*models.User{}
+	for _, rs := range buildRosterSlots(event, ratings) {
+		slots = append(slots, rs.Slot)
+		roster[rs.key()] = rs
+
+		cid, user := rs.proposed()
+		if rs.Position.Locked {
+			cid, user = rs.assigned()
+		}
+		if cid != nil {
+			proposed[rs.Slot] = *cid
+			users[rs.key()] = user
+		}
+	}
+	solution := events.Summarize(slots, candidates, proposed)
+
+	ret := &dto.EventAssignmentDraft{
+		EventID:   event.ID,
+		DraftedAt: event.AssignmentDraftAt,
+		Positions: []*dto.EventAssignmentResponse{},
+		Filled:    solution.Filled,
+		Unfilled:  solution.Unfilled,
+		ByChoice:  solution.ByChoice,
+		Visitors:  solution.Visitors,
+		Signups:   len(candidates),
+	}
+	for _, a := range solution.Assignments {
+		key := rosterKey{a.SlotID, a.ShiftID}
+		r := &dto.EventAssignmentResponse{
+			PositionID: a.SlotID,
+			ShiftID:    a.ShiftID,
+			Position:   a.Position,
+			Locked:     roster[key].Position.Locked,
+			UserID:     a.CID,
+			Choice:     a.Choice,
+		}
+		if shift := roster[key].Shift; shift != nil {
+			r.StartsAt = &shift.StartsAt
+			r.EndsAt = &shift.EndsAt
+		}
+		if u := users[key]; u != nil {
+			r.User = dto.ConvUserToUserResponse(u)
+		}
+		ret.Positions = append(ret.Positions, r)
+	}
+
+	response.Respond(c, http.StatusOK, ret)
+}
+
+// buildCandidates turns the event's signups into solver candidates, leaving out waitlisted signups and the
+// controllers in exclude
+func buildCandidates(event *models.Event, exclude map[uint]bool) ([]*events.Candidate, error) {
+	var signups []*models.EventSignup
+	for _, signup := range event.Signups {
+		if signup.UserID != nil && !signup.Waitlisted && !exclude[*signup.UserID] {
+			signups = append(signups, signup)
+		}
+	}
+
+	return signupCandidates(signups)
+}
+
+// signupCandidates turns signups into candidates, in the same order
+func signupCandidates(signups []*models.EventSignup) ([]*events.Candidate, error) {
+	var cids []uint
+	for _, signup := range signups {
+		if signup.UserID != nil {
+			cids = append(cids, *signup.UserID)
+		}
+	}
+	if len(cids) == 0 {
+		return nil, nil
+	}
+
+	holders, err := database.FindCertificationHolders(database.DB, cids)
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []*events.Candidate
+	for _, signup := range signups {
+		if signup.UserID == nil {
+			continue
+		}
+
+		candidate := &events.Candidate{
+			CID:            *signup.UserID,
+			Visitor:        signup.User != nil && signup.User.ControllerType == constants.ControllerTypeVisitor,
+			Certifications: map[string]string{},
+			AvailableFrom:  signup.AvailableFrom,
+			AvailableUntil: signup.AvailableUntil,
+		}
+		for _, choice := range []string{signup.Choice1, signup.Choice2, signup.Choice3} {
+			if choice != "" {
+				candidate.Choices = append(candidate.Choices, choice)
+			}
+		}
+		if holder, ok := holders[candidate.CID]; ok {
+			candidate.RatingID = holder.RatingID
+			candidate.Certifications = holder.Values
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	return candidates, nil
+}
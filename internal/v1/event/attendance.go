@@ -0,0 +1,342 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package event
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/adh-partnership/api/pkg/config"
+	"github.com/adh-partnership/api/pkg/database"
+	"github.com/adh-partnership/api/pkg/database/dto"
+	"github.com/adh-partnership/api/pkg/database/models"
+	"github.com/adh-partnership/api/pkg/events"
+	"github.com/adh-partnership/api/pkg/gin/response"
+)
+
+type noShowKey struct {
+	CID      uint
+	Position string
+	ShiftID  uint
+}
+
+// Get Event Attendance
+// @Summary Get Event Attendance
+// @Description Compare each assignment of an event with the sessions of its controller. Assignments are attended,
+// @Description late, left_early, no_show or different_position, where the controller only worked other positions
+// @Description during it. Controllers may log on late or off early by the configured grace and still have attended.
+// @Tags Events
+// @Param id path string true "Event ID"
+// @Success 200 {object} dto.EventAttendanceResponse
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 404 {object} response.R
+// @Failure 409 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/events/{id}/attendance [get]
+func getEventAttendance(c *gin.Context) {
+	event, err := database.GetEvent(c.Param("id"))
+	if err != nil {
+		log.Errorf("Error getting event: %s", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+	if event == nil {
+		response.RespondError(c, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	if event.StartDate.After(time.Now()) {
+		response.RespondError(c, http.StatusConflict, "Event has not started")
+		return
+	}
+
+	report, err := buildAttendance(event)
+	if err != nil {
+		log.Errorf("Error building attendance of event %d: %s", event.ID, err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	response.Respond(c, http.StatusOK, report)
+}
+
+// Record Event No Show
+// @Summary Record Event No Show
+// @Description Record on a controller's record that they did not work a position they were assigned
+// @Tags Events
+// @Param id path string true "Event ID"
+// @Param data body dto.EventNoShowRequest true "No show"
+// @Success 201 {object} models.EventNoShow
+// @Failure 400 {object} response.R
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 404 {object} response.R
+// @Failure 409 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/events/{id}/attendance/noshows [post]
+func postEventNoShow(c *gin.Context) {
+	user := c.MustGet("x-user").(*models.User)
+
+	data := &dto.EventNoShowRequest{}
+	if err := c.ShouldBind(&data); err != nil || data.UserID == 0 || data.Position == "" {
+		response.RespondError(c, http.StatusBadRequest, "Bad Request")
+		return
+	}
+
+	event, err := database.GetEvent(c.Param("id"))
+	if err != nil {
+		log.Errorf("Error getting event: %s", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+	if event == nil {
+		response.RespondError(c, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	var slot *rosterSlot
+	for _, s := range buildRosterSlots(event, nil) {
+		cid, _ := s.assigned()
+		if cid == nil || *cid != data.UserID || s.Position.Position != data.Position {
+			continue
+		}
+		if (data.ShiftID == nil && s.Shift == nil) || (data.ShiftID != nil && s.Shift != nil && *data.ShiftID == s.Shift.ID) {
+			slot = s
+			break
+		}
+	}
+	if slot == nil {
+		response.RespondError(c, http.StatusBadRequest, "Controller was not assigned to that position")
+		return
+	}
+
+	key := noShowKey{data.UserID, data.Position, slot.Slot.ShiftID}
+	recorded, err := database.FindEventNoShows(event.ID)
+	if err != nil {
+		log.Errorf("Error getting no shows of event %d: %s", event.ID, err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+	for _, n := range recorded {
+		if keyOfNoShow(n) == key {
+			response.RespondError(c, http.StatusConflict, "No show already recorded")
+			return
+		}
+	}
+
+	noShow := &models.EventNoShow{
+		EventID:    event.ID,
+		CID:        data.UserID,
+		Position:   data.Position,
+		ShiftID:    data.ShiftID,
+		ReporterID: user.CID,
+		Notes:      data.Notes,
+	}
+	if err := database.DB.Create(noShow).Error; err != nil {
+		log.Errorf("Error recording no show: %s", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	response.Respond(c, http.StatusCreated, noShow)
+}
+
+// Delete Event No Show
+// @Summary Delete Event No Show
+// @Description Remove a no show recorded in error from a controller's record
+// @Tags Events
+// @Param id path string true "Event ID"
+// @Param noshow path string true "No Show ID"
+// @Success 204
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 404 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/events/{id}/attendance/noshows/{noshow} [delete]
+func deleteEventNoShow(c *gin.Context) {
+	noShow := &models.EventNoShow{}
+	if err := database.DB.Where("id = ? AND event_id = ?", c.Param("noshow"), c.Param("id")).First(noShow).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.RespondError(c, http.StatusNotFound, "Not Found")
+			return
+		}
+		log.Errorf("Error getting no show: %s", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	if err := database.DB.Delete(noShow).Error; err != nil {
+		log.Errorf("Error deleting no show: %s", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	response.RespondBlank(c, http.StatusNoContent)
+}
+
+func keyOfNoShow(n *models.EventNoShow) noShowKey {
+	key := noShowKey{CID: n.CID, Position: n.Position}
+	if n.ShiftID != nil {
+		key.ShiftID = *n.ShiftID
+	}
+
+	return key
+}
+
+// buildAttendance classifies every assignment of the event against the sessions of its controller
+func buildAttendance(event *models.Event) (*dto.EventAttendanceResponse, error) {
+	ret := &dto.EventAttendanceResponse{
+		EventID:     event.ID,
+		Assignments: []*dto.EventAttendanceEntry{},
+		Controllers: []*dto.EventAttendanceTotal{},
+		NoShows:     []*dto.EventAttendanceEntry{},
+	}
+
+	slots := buildRosterSlots(event, nil)
+	var cids []uint
+	seen := map[uint]bool{}
+	for _, slot := range slots {
+		if cid, _ := slot.assigned(); cid != nil && !seen[*cid] {
+			seen[*cid] = true
+			cids = append(cids, *cid)
+		}
+	}
+	if len(cids) == 0 {
+		return ret, nil
+	}
+
+	sessions, err := controllerSessions(cids, event.StartDate, event.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	noShows, err := database.FindEventNoShows(event.ID)
+	if err != nil {
+		return nil, err
+	}
+	recorded := map[noShowKey]uint{}
+	for _, n := range noShows {
+		recorded[keyOfNoShow(n)] = n.ID
+	}
+
+	grace := time.Duration(config.Cfg.Facility.Events.AttendanceGraceMinutes) * time.Minute
+	totals := map[uint]*dto.EventAttendanceTotal{}
+	users := map[uint]*models.User{}
+	for _, slot := range slots {
+		cid, user := slot.assigned()
+		if cid == nil {
+			continue
+		}
+
+		booking := &events.Booking{Position: slot.Position.Position, Start: slot.Slot.Start, End: slot.Slot.End}
+		attendance := events.Attend(booking, sessions[*cid], grace)
+
+		if _, ok := users[*cid]; !ok {
+			users[*cid] = user
+		}
+		entry := &dto.EventAttendanceEntry{
+			PositionID:     slot.Position.ID,
+			ShiftID:        slot.Slot.ShiftID,
+			Position:       slot.Position.Position,
+			StartsAt:       booking.Start,
+			EndsAt:         booking.End,
+			UserID:         *cid,
+			User:           dto.ConvUserToUserResponse(user),
+			Status:         attendance.Status,
+			WorkedMinutes:  int(attendance.Worked.Minutes()),
+			LoggedOnAt:     attendance.LoggedOn,
+			LoggedOffAt:    attendance.LoggedOff,
+			OtherPositions: attendance.OtherPositions,
+		}
+		if id, ok := recorded[noShowKey{*cid, booking.Position, slot.Slot.ShiftID}]; ok {
+			entry.NoShowID = &id
+		}
+		ret.Assignments = append(ret.Assignments, entry)
+
+		total, ok := totals[*cid]
+		if !ok {
+			total = &dto.EventAttendanceTotal{UserID: *cid, User: entry.User}
+			totals[*cid] = total
+			ret.Controllers = append(ret.Controllers, total)
+		}
+		total.Assignments++
+		total.WorkedMinutes += entry.WorkedMinutes
+		switch attendance.Status {
+		case events.AttendanceAttended:
+			total.Attended++
+		case events.AttendanceLate:
+			total.Late++
+		case events.AttendanceLeftEarly:
+			total.LeftEarly++
+		case events.AttendanceDifferentPosition:
+			total.DifferentPosition++
+		case events.AttendanceNoShow:
+			total.NoShows++
+			ret.NoShows = append(ret.NoShows, entry)
+		}
+	}
+
+	sort.SliceStable(ret.Controllers, func(i, j int) bool {
+		a, b := users[ret.Controllers[i].UserID], users[ret.Controllers[j].UserID]
+		if a == nil || b == nil {
+			return ret.Controllers[i].UserID < ret.Controllers[j].UserID
+		}
+		if a.LastName != b.LastName {
+			return a.LastName < b.LastName
+		}
+		return a.FirstName < b.FirstName
+	})
+
+	return ret, nil
+}
+
+// controllerSessions returns the finished and current sessions of each controller that overlap start to end
+func controllerSessions(cids []uint, start, end time.Time) (map[uint][]*events.Session, error) {
+	stats, err := database.FindControllerStatsBetween(cids, start, end)
+	if err != nil {
+		return nil, err
+	}
+	online, err := database.FindOnlineControllersBefore(cids, end)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := map[uint][]*events.Session{}
+	for _, stat := range stats {
+		ret[stat.UserID] = append(ret[stat.UserID], &events.Session{
+			Callsign: stat.Position,
+			Start:    stat.LogonTime,
+			End:      stat.LogonTime.Add(time.Duration(stat.Duration) * time.Second),
+		})
+	}
+	for _, controller := range online {
+		// Current sessions last until the controller was last seen by the data parser
+		ret[controller.UserID] = append(ret[controller.UserID], &events.Session{
+			Callsign: controller.Position,
+			Start:    controller.LogonTime,
+			End:      controller.UpdatedAt,
+		})
+	}
+
+	return ret, nil
+}
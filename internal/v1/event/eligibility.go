@@ -0,0 +1,241 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package event
+
+import (
+	"fmt"
+
+	"github.com/adh-partnership/api/pkg/config"
+	"github.com/adh-partnership/api/pkg/database"
+	"github.com/adh-partnership/api/pkg/database/dto"
+	"github.com/adh-partnership/api/pkg/database/models"
+	"github.com/adh-partnership/api/pkg/events"
+)
+
+// buildSlot describes what a controller needs to work an event position, ratings mapping rating IDs to short names
+func buildSlot(position *models.EventPosition, ratings map[int]string) *events.Slot {
+	slot := &events.Slot{
+		ID:          position.ID,
+		Position:    position.Position,
+		MinRatingID: position.MinRatingID,
+		MinRating:   ratings[position.MinRatingID],
+	}
+	for _, cert := range position.Certifications {
+		slot.Certifications = append(slot.Certifications, cert.Certification)
+	}
+
+	return slot
+}
+
+// rosterSlot ties a solver slot to the position, or shift of a position, it was built from
+type rosterSlot struct {
+	Slot     *events.Slot
+	Position *models.EventPosition
+	Shift    *models.EventShift
+}
+
+type rosterKey struct {
+	PositionID uint
+	ShiftID    uint
+}
+
+func (r *rosterSlot) key() rosterKey {
+	return rosterKey{r.Slot.ID, r.Slot.ShiftID}
+}
+
+// assigned returns the controller working the slot
+func (r *rosterSlot) assigned() (*uint, *models.User) {
+	if r.Shift != nil {
+		return r.Shift.UserID, r.Shift.User
+	}
+
+	return r.Position.UserID, r.Position.User
+}
+
+// proposed returns the controller the assignment draft proposes for the slot
+func (r *rosterSlot) proposed() (*uint, *models.User) {
+	if r.Shift != nil {
+		return r.Shift.ProposedUserID, r.Shift.ProposedUser
+	}
+
+	return r.Position.ProposedUserID, r.Position.ProposedUser
+}
+
+// buildRosterSlots returns a slot for each shift of the event's positions, or for the whole event if a position is
+// not split into shifts. Positions staffed by partner facilities are left out as they assign them themselves.
+func buildRosterSlots(event *models.Event, ratings map[int]string) []*rosterSlot {
+	var ret []*rosterSlot
+	host := events.HostFacility()
+	for _, position := range event.Positions {
+		if events.PositionFacility(position, host) != host {
+			continue
+		}
+		if len(position.Shifts) == 0 {
+			slot := buildSlot(position, ratings)
+			slot.Start = event.StartDate
+			slot.End = event.EndDate
+			ret = append(ret, &rosterSlot{Slot: slot, Position: position})
+			continue
+		}
+
+		for _, shift := range position.Shifts {
+			slot := buildSlot(position, ratings)
+			slot.ShiftID = shift.ID
+			slot.Start = shift.StartsAt
+			slot.End = shift.EndsAt
+			ret = append(ret, &rosterSlot{Slot: slot, Position: position, Shift: shift})
+		}
+	}
+
+	return ret
+}
+
+// findCandidate gets the rating and certifications of a single controller
+func findCandidate(user *models.User) (*events.Candidate, error) {
+	holders, err := database.FindCertificationHolders(database.DB, []uint{user.CID})
+	if err != nil {
+		return nil, err
+	}
+
+	candidate := &events.Candidate{CID: user.CID, RatingID: user.RatingID, Certifications: map[string]string{}}
+	if holder, ok := holders[user.CID]; ok {
+		candidate.Certifications = holder.Values
+	}
+
+	return candidate, nil
+}
+
+// eligiblePositions returns the names of the event's positions the controller can work
+func eligiblePositions(event *models.Event, user *models.User) ([]string, error) {
+	candidate, err := findCandidate(user)
+	if err != nil {
+		return nil, err
+	}
+
+	ratings, err := database.FindRatingNames(database.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := []string{}
+	for _, position := range event.Positions {
+		if events.Eligible(buildSlot(position, ratings), candidate) {
+			ret = append(ret, position.Position)
+		}
+	}
+
+	return ret, nil
+}
+
+// applyPositionRequirements validates the certifications and minimum rating of a position request and sets them on
+// the position. New positions without certifications in the request get the one configured for their callsign. A
+// non-empty string is returned if the request is invalid.
+func applyPositionRequirements(position *models.EventPosition, data *dto.EventPositionRequest) (string, error) {
+	certifications := data.Certifications
+	if certifications == nil && position.ID == 0 {
+		if cert := events.PositionCertification(position.Position, config.Cfg.Facility.Events.PositionCertifications); cert != "" {
+			certifications = []string{cert}
+		}
+	}
+
+	if certifications != nil {
+		position.Certifications = []*models.EventPositionCertification{}
+		for _, cert := range certifications {
+			if !database.ValidCertification(cert) {
+				return fmt.Sprintf("Invalid certification %s", cert), nil
+			}
+			position.Certifications = append(position.Certifications, &models.EventPositionCertification{
+				EventPositionID: position.ID,
+				Certification:   cert,
+			})
+		}
+	}
+
+	if data.MinRating != nil {
+		position.MinRatingID = 0
+		if *data.MinRating != "" {
+			rating, err := database.FindRatingByShort(*data.MinRating)
+			if err != nil {
+				return "", err
+			}
+			if rating == nil {
+				return fmt.Sprintf("Invalid rating %s", *data.MinRating), nil
+			}
+			position.MinRatingID = rating.ID
+		}
+	}
+
+	return "", nil
+}
+
+// assignmentViolations returns the reasons the controller cannot work the position, or the shift of it if shift is
+// not nil, empty if they can
+func assignmentViolations(event *models.Event, position *models.EventPosition, shift *models.EventShift, user *models.User) ([]string, error) {
+	candidate, err := findCandidate(user)
+	if err != nil {
+		return nil, err
+	}
+	if signup := findSignup(event, user.CID); signup != nil {
+		candidate.AvailableFrom = signup.AvailableFrom
+		candidate.AvailableUntil = signup.AvailableUntil
+	}
+
+	ratings, err := database.FindRatingNames(database.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	slot := buildSlot(position, ratings)
+	slot.Start, slot.End = event.StartDate, event.EndDate
+	if shift != nil {
+		slot.Start, slot.End = shift.StartsAt, shift.EndsAt
+	}
+
+	return events.Violations(slot, candidate), nil
+}
+
+func findSignup(event *models.Event, cid uint) *models.EventSignup {
+	for _, signup := range event.Signups {
+		if signup.UserID != nil && *signup.UserID == cid {
+			return signup
+		}
+	}
+
+	return nil
+}
+
+// userBookings returns the times the controller is assigned to work during the event, leaving out the position, or
+// the shift of it if shift is not nil, being assigned
+func userBookings(event *models.Event, cid uint, position *models.EventPosition, shift *models.EventShift) []*events.Booking {
+	var ret []*events.Booking
+	for _, p := range event.Positions {
+		if len(p.Shifts) == 0 {
+			if p.UserID != nil && *p.UserID == cid && (shift != nil || p.ID != position.ID) {
+				ret = append(ret, &events.Booking{Position: p.Position, Start: event.StartDate, End: event.EndDate})
+			}
+			continue
+		}
+
+		for _, s := range p.Shifts {
+			if s.UserID != nil && *s.UserID == cid && (shift == nil || s.ID != shift.ID) {
+				ret = append(ret, &events.Booking{Position: p.Position, Start: s.StartsAt, End: s.EndsAt})
+			}
+		}
+	}
+
+	return ret
+}
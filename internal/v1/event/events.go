@@ -18,12 +18,16 @@ package event
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 
 	"github.com/adh-partnership/api/pkg/database"
 	"github.com/adh-partnership/api/pkg/database/dto"
 	"github.com/adh-partnership/api/pkg/database/models"
+	"github.com/adh-partnership/api/pkg/database/models/constants"
+	"github.com/adh-partnership/api/pkg/events"
 	"github.com/adh-partnership/api/pkg/gin/response"
 )
 
@@ -58,7 +62,7 @@ func getEvents(c *gin.Context) {
 
 // Get Event
 // @Summary Get Event
-// @Description Get an event
+// @Description Get an event. For logged in users, the positions they are eligible to work are included.
 // @Tags Events
 // @Param id path string true "Event ID"
 // @Success 200 {object} dto.EventsResponse
@@ -78,12 +82,22 @@ func getEvent(c *gin.Context) {
 		return
 	}
 
-	response.Respond(c, http.StatusOK, dto.ConvEventToEventsResponse(event))
+	ret := dto.ConvEventToEventsResponse(event)
+	if u, ok := c.Get("x-user"); ok && !c.GetBool("x-guest") {
+		ret.EligiblePositions, err = eligiblePositions(event, u.(*models.User))
+		if err != nil {
+			log.Errorf("Error getting eligible positions: %s", err)
+			response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+	}
+
+	response.Respond(c, http.StatusOK, ret)
 }
 
 // Create Event
 // @Summary Create Event
-// @Description Create an event
+// @Description Create an event, announcing it on Discord if event notifications are enabled
 // @Tags Events
 // @Param data body dto.EventRequest true "Event Data"
 // @Success 201
@@ -98,6 +112,10 @@ func postEvent(c *gin.Context) {
 		response.RespondError(c, http.StatusBadRequest, "Invalid request")
 		return
 	}
+	if dto.SignupCap != nil && *dto.SignupCap < 0 {
+		response.RespondError(c, http.StatusBadRequest, "Signup cap cannot be negative")
+		return
+	}
 
 	event := models.Event{
 		Title:       dto.Title,
@@ -106,6 +124,18 @@ func postEvent(c *gin.Context) {
 		StartDate:   *dto.StartDate,
 		EndDate:     *dto.EndDate,
 	}
+	if dto.SignupsCloseAt != nil && !dto.SignupsCloseAt.IsZero() {
+		event.SignupsCloseAt = dto.SignupsCloseAt
+	}
+	if dto.SignupCap != nil {
+		event.SignupCap = *dto.SignupCap
+	}
+	facilities, err := events.PartnerFacilities(events.HostFacility(), dto.Facilities)
+	if err != nil {
+		response.RespondError(c, http.StatusBadRequest, "Invalid facility")
+		return
+	}
+	event.Facilities = facilities
 
 	if err := database.DB.Create(&event).Error; err != nil {
 		log.Errorf("Error creating event: %s", err)
@@ -113,12 +143,20 @@ func postEvent(c *gin.Context) {
 		return
 	}
 
+	if events.NotificationsEnabled() {
+		// Announced on a copy, the response is written while the announcement is sent
+		announced := event
+		go events.AnnounceNew(&announced)
+	}
+
 	response.Respond(c, http.StatusCreated, event)
 }
 
 // Patch Event
 // @Summary Patch Event
-// @Description Patch an event
+// @Description Patch an event. Raising the signup cap lets waitlisted signups in. Controllers told about their
+// @Description assignment, and the announcement channel if the event was announced, are notified when the title or
+// @Description times change. Positions of facilities taken off the event go back to the host facility.
 // @Tags Events
 // @Param id path string true "Event ID"
 // @Param data body dto.EventRequest true "Event Data"
@@ -135,6 +173,15 @@ func patchEvent(c *gin.Context) {
 		response.RespondError(c, http.StatusBadRequest, "Invalid request")
 		return
 	}
+	if data.SignupCap != nil && *data.SignupCap < 0 {
+		response.RespondError(c, http.StatusBadRequest, "Signup cap cannot be negative")
+		return
+	}
+	facilities, err := events.PartnerFacilities(events.HostFacility(), data.Facilities)
+	if err != nil {
+		response.RespondError(c, http.StatusBadRequest, "Invalid facility")
+		return
+	}
 
 	event, err := database.GetEvent(c.Param("id"))
 	if err != nil {
@@ -148,20 +195,76 @@ func patchEvent(c *gin.Context) {
 		return
 	}
 
+	before := *event
 	patchedEvent := dto.PatchEventRequest(event, data)
 
-	if err := database.DB.Save(patchedEvent).Error; err != nil {
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if data.Facilities != nil {
+			if err := tx.Where(models.EventFacility{EventID: patchedEvent.ID}).Delete(&models.EventFacility{}).Error; err != nil {
+				return err
+			}
+			patchedEvent.Facilities = facilities
+			if err := untagRemovedFacilities(tx, patchedEvent); err != nil {
+				return err
+			}
+		}
+		return tx.Save(patchedEvent).Error
+	})
+	if err != nil {
 		log.Errorf("Error updating event: %s", err)
 		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
 		return
 	}
 
+	if data.SignupCap != nil {
+		if err := promoteWaitlist(patchedEvent); err != nil {
+			log.Errorf("Error promoting waitlisted signups of event %d: %s", patchedEvent.ID, err)
+		}
+	}
+
+	if changes := events.Changes(&before, patchedEvent); len(changes) > 0 && events.NotificationsEnabled() {
+		go notifyEventChange(patchedEvent, constants.EventNotificationUpdate, "Event updated: "+strings.Join(changes, ", "))
+	}
+
 	response.Respond(c, http.StatusOK, patchedEvent)
 }
 
+// untagRemovedFacilities gives the positions of facilities no longer co-hosting the event back to the host, dropping
+// the partner controllers assigned to them
+func untagRemovedFacilities(tx *gorm.DB, event *models.Event) error {
+	host := events.HostFacility()
+	for _, position := range event.Positions {
+		if position.Facility == "" || events.Participates(event, host, position.Facility) {
+			continue
+		}
+
+		if err := tx.Model(&models.EventPosition{}).Where("id = ?", position.ID).Updates(map[string]interface{}{
+			"facility":      "",
+			"external_cid":  nil,
+			"external_name": "",
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.EventShift{}).Where("event_position_id = ?", position.ID).Updates(map[string]interface{}{
+			"external_cid":  nil,
+			"external_name": "",
+		}).Error; err != nil {
+			return err
+		}
+
+		position.Facility, position.ExternalCID, position.ExternalName = "", nil, ""
+		for _, shift := range position.Shifts {
+			shift.ExternalCID, shift.ExternalName = nil, ""
+		}
+	}
+
+	return nil
+}
+
 // Delete Event
 // @Summary Delete Event
-// @Description Delete an event
+// @Description Delete an event, notifying the controllers told about their assignment that it was cancelled. The
+// @Description positions are kept so personal calendar feeds can show the assignments as cancelled.
 // @Tags Events
 // @Param id path string true "Event ID"
 // @Success 204
@@ -182,12 +285,6 @@ func deleteEvent(c *gin.Context) {
 		return
 	}
 
-	if err := database.DB.Where(models.EventPosition{EventID: event.ID}).Delete(models.EventPosition{}).Error; err != nil {
-		log.Errorf("Error deleting positions: %s", err)
-		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
-		return
-	}
-
 	if err := database.DB.Where(models.EventSignup{EventID: event.ID}).Delete(models.EventSignup{}).Error; err != nil {
 		log.Errorf("Error deleting signups: %s", err)
 		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
@@ -200,5 +297,9 @@ func deleteEvent(c *gin.Context) {
 		return
 	}
 
+	if events.NotificationsEnabled() {
+		go notifyEventChange(event, constants.EventNotificationCancelled, "Event cancelled")
+	}
+
 	response.Respond(c, http.StatusNoContent, nil)
 }
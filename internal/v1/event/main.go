@@ -32,10 +32,35 @@ func Routes(r *gin.RouterGroup) {
 	r.PATCH(":id", auth.NotGuest, auth.InGroup("events"), patchEvent)
 	r.DELETE(":id", auth.NotGuest, auth.InGroup("events"), deleteEvent)
 
+	r.GET("/templates", auth.NotGuest, auth.InGroup("events"), getEventTemplates)
+	r.POST("/templates", auth.NotGuest, auth.InGroup("events"), postEventTemplate)
+	r.GET("/templates/:template", auth.NotGuest, auth.InGroup("events"), getEventTemplate)
+	r.PUT("/templates/:template", auth.NotGuest, auth.InGroup("events"), putEventTemplate)
+	r.DELETE("/templates/:template", auth.NotGuest, auth.InGroup("events"), deleteEventTemplate)
+	r.POST("/templates/:template/events", auth.NotGuest, auth.InGroup("events"), postEventFromTemplate)
+
 	r.GET("/:id/positions", getEventPositions)
 	r.POST("/:id/positions", auth.NotGuest, auth.InGroup("events"), addEventPosition)
 	r.PUT("/:id/positions/:position", auth.NotGuest, auth.InGroup("events"), updateEventPosition)
 	r.DELETE("/:id/positions/:position", auth.NotGuest, auth.InGroup("events"), deleteEventPosition)
+	r.POST("/:id/positions/:position/shifts", auth.NotGuest, auth.InGroup("events"), postEventShift)
+	r.PUT("/:id/positions/:position/shifts/:shift", auth.NotGuest, auth.InGroup("events"), putEventShift)
+	r.DELETE("/:id/positions/:position/shifts/:shift", auth.NotGuest, auth.InGroup("events"), deleteEventShift)
+
+	r.POST("/:id/assignments/solve", auth.NotGuest, auth.InGroup("events"), postSolveAssignments)
+	r.GET("/:id/assignments/draft", auth.NotGuest, auth.InGroup("events"), getAssignmentDraft)
+	r.DELETE("/:id/assignments/draft", auth.NotGuest, auth.InGroup("events"), deleteAssignmentDraft)
+	r.POST("/:id/assignments/publish", auth.NotGuest, auth.InGroup("events"), postPublishAssignments)
+
+	r.GET("/:id/shared", auth.NotGuest, getSharedEvent)
+	r.PUT("/:id/shared/assignments", auth.NotGuest, putSharedAssignments)
+	r.GET("/partners/:facility/:id", auth.NotGuest, auth.InGroup("events"), getPartnerEvent)
+	r.PUT("/partners/:facility/:id/assignments", auth.NotGuest, auth.InGroup("events"), putPartnerAssignments)
+
+	r.GET("/:id/stats", getEventTraffic)
+	r.GET("/:id/attendance", auth.NotGuest, auth.InGroup("events"), getEventAttendance)
+	r.POST("/:id/attendance/noshows", auth.NotGuest, auth.InGroup("events"), postEventNoShow)
+	r.DELETE("/:id/attendance/noshows/:noshow", auth.NotGuest, auth.InGroup("events"), deleteEventNoShow)
 
 	r.POST("/:id/signup", auth.NotGuest, postEventSignup)
 	r.DELETE("/:id/signup", auth.NotGuest, deleteEventSignup)
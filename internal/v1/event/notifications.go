@@ -0,0 +1,97 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package event
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/adh-partnership/api/pkg/database"
+	"github.com/adh-partnership/api/pkg/database/models"
+	"github.com/adh-partnership/api/pkg/database/models/constants"
+	"github.com/adh-partnership/api/pkg/events"
+)
+
+// notifyEventChange tells everyone that already heard about the event that it changed or was cancelled
+func notifyEventChange(event *models.Event, kind, content string) {
+	if event.AnnouncedAt != nil {
+		events.Announce(event, content)
+	}
+
+	users, err := database.FindNotifiedControllers(event.ID)
+	if err != nil {
+		log.Errorf("Error getting notified controllers of event %d: %s", event.ID, err)
+		return
+	}
+
+	events.NotifyControllers(event, users, events.ControllerAssignments(event), kind, 0, content)
+}
+
+// notifyAssignments tells the controllers assigned to the event about their positions, and those that were told
+// about an earlier assignment that they are no longer assigned
+func notifyAssignments(event *models.Event) {
+	notified, err := database.FindNotifiedControllers(event.ID)
+	if err != nil {
+		log.Errorf("Error getting notified controllers of event %d: %s", event.ID, err)
+		return
+	}
+
+	assignments := events.ControllerAssignments(event)
+	events.NotifyControllers(event, events.AssignedControllers(event), assignments, constants.EventNotificationAssignment, 0,
+		"Position assignments have been published")
+
+	var removed []*models.User
+	for _, user := range notified {
+		if _, ok := assignments[user.CID]; !ok {
+			removed = append(removed, user)
+		}
+	}
+	events.NotifyControllers(event, removed, assignments, constants.EventNotificationRemoved, 0, "You are no longer assigned to this event")
+}
+
+// notifyWithdrawal tells events staff that a controller withdrew after assignments were published, the waitlisted
+// controllers that took their positions about their assignment, and those let in under the cap that they left the
+// waitlist
+func notifyWithdrawal(event *models.Event, user *models.User, result *withdrawal) {
+	if len(result.Freed) > 0 {
+		details := []string{"Freed: " + strings.Join(result.Freed, ", ")}
+		if len(result.Filled) > 0 {
+			details = append(details, "Given from the waitlist: "+strings.Join(result.Filled, ", "))
+		}
+		if len(result.Vacant) > 0 {
+			details = append(details, "Vacant: "+strings.Join(result.Vacant, ", "))
+		}
+		events.NotifyStaff(event, fmt.Sprintf("%s %s (%d) withdrew from the event", user.FirstName, user.LastName, user.CID), details)
+	}
+
+	assignments := events.ControllerAssignments(event)
+	events.NotifyControllers(event, result.Assigned, assignments, constants.EventNotificationAssignment, 0,
+		"A position became available and you were assigned from the waitlist")
+
+	assigned := map[uint]bool{}
+	for _, u := range result.Assigned {
+		assigned[u.CID] = true
+	}
+	var admitted []*models.User
+	for _, u := range result.Promoted {
+		if u != nil && !assigned[u.CID] {
+			admitted = append(admitted, u)
+		}
+	}
+	events.NotifyControllers(event, admitted, assignments, constants.EventNotificationUpdate, 0,
+		"A place opened up and you are off the waitlist")
+}
@@ -0,0 +1,149 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package event
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/adh-partnership/api/pkg/database"
+	"github.com/adh-partnership/api/pkg/database/dto"
+	"github.com/adh-partnership/api/pkg/events"
+	"github.com/adh-partnership/api/pkg/gin/response"
+	"github.com/adh-partnership/api/pkg/network/partner"
+)
+
+// Get Partner Event
+// @Summary Get Partner Event
+// @Description Get an event a partner facility hosts and we co-host, with the positions each facility staffs, from
+// @Description the partner's API
+// @Tags Events
+// @Param facility path string true "Partner Facility ID"
+// @Param id path string true "Event ID at the partner"
+// @Success 200 {object} dto.SharedEventResponse
+// @Failure 400 {object} response.R
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 404 {object} response.R
+// @Failure 502 {object} response.R
+// @Router /v1/events/partners/{facility}/{id} [get]
+func getPartnerEvent(c *gin.Context) {
+	facility, err := events.NormalizeFacility(c.Param("facility"))
+	if err != nil {
+		response.RespondError(c, http.StatusNotFound, "Partner not configured")
+		return
+	}
+
+	id, ok := partnerEventID(c)
+	if !ok {
+		return
+	}
+
+	status, body, err := partner.GetSharedEvent(facility, id)
+	respondPartner(c, status, body, err)
+}
+
+// Put Partner Event Assignments
+// @Summary Put Partner Event Assignments
+// @Description Assign our controllers to the positions, or shifts of them, we staff at an event a partner facility
+// @Description hosts. Names are filled in from our roster, and a cid of 0 clears the assignment.
+// @Tags Events
+// @Param facility path string true "Partner Facility ID"
+// @Param id path string true "Event ID at the partner"
+// @Param data body []dto.SharedAssignmentRequest true "Assignments"
+// @Success 200 {object} dto.SharedEventResponse
+// @Failure 400 {object} response.R
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 404 {object} response.R
+// @Failure 409 {object} response.R
+// @Failure 502 {object} response.R
+// @Router /v1/events/partners/{facility}/{id}/assignments [put]
+func putPartnerAssignments(c *gin.Context) {
+	facility, err := events.NormalizeFacility(c.Param("facility"))
+	if err != nil {
+		response.RespondError(c, http.StatusNotFound, "Partner not configured")
+		return
+	}
+
+	id, ok := partnerEventID(c)
+	if !ok {
+		return
+	}
+
+	var data []*dto.SharedAssignmentRequest
+	if err := c.ShouldBind(&data); err != nil {
+		response.RespondError(c, http.StatusBadRequest, "Bad Request")
+		return
+	}
+
+	for _, a := range data {
+		a.Name = ""
+		if a.CID == 0 {
+			continue
+		}
+		user, err := database.FindUserByCID(fmt.Sprint(a.CID))
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Errorf("Error getting user: %s", err)
+			response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+		if user == nil {
+			response.RespondError(c, http.StatusNotFound, fmt.Sprintf("User %d Not Found", a.CID))
+			return
+		}
+		a.Name = user.FirstName + " " + user.LastName
+	}
+
+	status, body, err := partner.PutSharedAssignments(facility, id, data)
+	respondPartner(c, status, body, err)
+}
+
+// partnerEventID parses the ID of the event at the partner, which goes into the partner's URL
+func partnerEventID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.RespondError(c, http.StatusBadRequest, "Invalid event ID")
+		return 0, false
+	}
+
+	return uint(id), true
+}
+
+// respondPartner passes the partner's response on, which is either a shared event or an error in our own format
+func respondPartner(c *gin.Context, status int, body []byte, err error) {
+	if errors.Is(err, partner.ErrUnknownPartner) {
+		response.RespondError(c, http.StatusNotFound, "Partner not configured")
+		return
+	}
+	if err != nil {
+		response.RespondError(c, http.StatusBadGateway, "Partner API unavailable")
+		return
+	}
+	if status == http.StatusUnauthorized {
+		// Our key was rejected, which is not the fault of the user making the request
+		response.RespondError(c, http.StatusBadGateway, "Partner API rejected our API key")
+		return
+	}
+
+	c.Data(status, "application/json", body)
+}
@@ -57,7 +57,8 @@ func getEventPositions(c *gin.Context) {
 
 // Add Event Position
 // @Summary Add Event Position
-// @Description Add a position to an event
+// @Description Add a position to an event. Without certifications in the request, the position needs the one
+// @Description configured for its callsign. Assigning a controller that cannot work the position needs override.
 // @Tags Events
 // @Param id path string true "Event ID"
 // @Param position body dto.EventPositionRequest true "Position. CID 0 means unassigned."
@@ -102,26 +103,57 @@ func addEventPosition(c *gin.Context) {
 			response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
 			return
 		}
+		if user == nil {
+			response.RespondError(c, http.StatusNotFound, "User Not Found")
+			return
+		}
 	}
 
 	position := &models.EventPosition{
+		EventID:  event.ID,
 		Position: data.Position,
 		User:     user,
 	}
+	if data.Locked != nil {
+		position.Locked = *data.Locked
+	}
+	if _, msg := applyPositionFacility(event, position, data.Facility); msg != "" {
+		response.RespondError(c, http.StatusBadRequest, msg)
+		return
+	}
+
+	if msg, err := applyPositionRequirements(position, data); err != nil {
+		log.Errorf("Error getting position requirements: %s", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	} else if msg != "" {
+		response.RespondError(c, http.StatusBadRequest, msg)
+		return
+	}
 
-	event.Positions = append(event.Positions, position)
-	if err := database.DB.Save(&event).Error; err != nil {
+	if user != nil && !checkAssignment(c, event, position, nil, user, data.Override) {
+		return
+	}
+
+	if err := database.DB.Create(position).Error; err != nil {
 		log.Errorf("Error adding event position: %s", err)
 		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
 		return
 	}
 
+	event, err = database.GetEvent(c.Param("id"))
+	if err != nil {
+		log.Errorf("Error getting event: %s", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
 	response.Respond(c, http.StatusOK, event)
 }
 
 // Update Event Position
 // @Summary Update Event Position
-// @Description Update a position for an event.
+// @Description Update a position for an event. Assigning a controller that cannot work the position needs override.
 // @Tags Events
 // @Param id path string true "Event ID"
 // @Param position path string true "Position Name"
@@ -159,11 +191,20 @@ func updateEventPosition(c *gin.Context) {
 			response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
 			return
 		}
+		if user == nil {
+			response.RespondError(c, http.StatusNotFound, "User Not Found")
+			return
+		}
 		cid = &user.CID
 	}
 
 	for _, position := range event.Positions {
 		if position.Position == c.Param("position") {
+			if user != nil && len(position.Shifts) > 0 {
+				response.RespondError(c, http.StatusBadRequest, "Position is split into shifts, assign the shifts instead")
+				return
+			}
+
 			// Never let position be "", assume they meant to keep the same position name
 			if data.Position == "" {
 				data.Position = position.Position
@@ -171,7 +212,49 @@ func updateEventPosition(c *gin.Context) {
 			position.Position = data.Position
 			position.User = user
 			position.UserID = cid
-			if err := database.DB.Save(&position).Error; err != nil {
+			if user != nil {
+				position.ExternalCID = nil
+				position.ExternalName = ""
+			}
+			if data.Locked != nil {
+				position.Locked = *data.Locked
+			}
+			retagged, msg := applyPositionFacility(event, position, data.Facility)
+			if msg != "" {
+				response.RespondError(c, http.StatusBadRequest, msg)
+				return
+			}
+
+			if msg, err := applyPositionRequirements(position, data); err != nil {
+				log.Errorf("Error getting position requirements: %s", err)
+				response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+				return
+			} else if msg != "" {
+				response.RespondError(c, http.StatusBadRequest, msg)
+				return
+			}
+
+			if user != nil && !checkAssignment(c, event, position, nil, user, data.Override) {
+				return
+			}
+
+			err := database.DB.Transaction(func(tx *gorm.DB) error {
+				if data.Certifications != nil {
+					if err := tx.Where(models.EventPositionCertification{EventPositionID: position.ID}).
+						Delete(&models.EventPositionCertification{}).Error; err != nil {
+						return err
+					}
+				}
+				if retagged {
+					// Partner controllers assigned to the shifts belong to the facility the position was taken from
+					if err := tx.Model(&models.EventShift{}).Where("event_position_id = ?", position.ID).
+						Updates(map[string]interface{}{"external_cid": nil, "external_name": ""}).Error; err != nil {
+						return err
+					}
+				}
+				return tx.Save(&position).Error
+			})
+			if err != nil {
 				log.Errorf("Error updating event position: %s", err)
 				response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
 				return
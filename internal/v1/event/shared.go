@@ -0,0 +1,275 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package event
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/adh-partnership/api/pkg/auth"
+	"github.com/adh-partnership/api/pkg/database"
+	"github.com/adh-partnership/api/pkg/database/dto"
+	"github.com/adh-partnership/api/pkg/database/models"
+	"github.com/adh-partnership/api/pkg/events"
+	authMiddleware "github.com/adh-partnership/api/pkg/gin/middleware/auth"
+	"github.com/adh-partnership/api/pkg/gin/response"
+)
+
+// Get Shared Event
+// @Summary Get Shared Event
+// @Description Get an event as the facilities co-hosting it see it, with the positions each facility staffs and who
+// @Description is assigned to them. For the API keys of co-hosting facilities and events staff.
+// @Tags Events
+// @Param id path string true "Event ID"
+// @Success 200 {object} dto.SharedEventResponse
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 404 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/events/{id}/shared [get]
+func getSharedEvent(c *gin.Context) {
+	event, err := database.GetEvent(c.Param("id"))
+	if err != nil {
+		log.Errorf("Error getting event: %s", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	if event == nil {
+		response.RespondError(c, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	host := events.HostFacility()
+	user := c.MustGet("x-user").(*models.User)
+	if !events.Participates(event, host, authMiddleware.APIFacility(c)) && !auth.InGroup(user, "events") {
+		response.RespondError(c, http.StatusForbidden, "Forbidden")
+		return
+	}
+
+	response.Respond(c, http.StatusOK, dto.ConvEventToSharedEventResponse(event, host))
+}
+
+// Put Shared Event Assignments
+// @Summary Put Shared Event Assignments
+// @Description Assign controllers of a co-hosting facility to the positions, or shifts of them, it staffs. Only for
+// @Description the API key issued to that facility. A cid of 0 clears the assignment, and positions not listed are
+// @Description left as they are.
+// @Tags Events
+// @Param id path string true "Event ID"
+// @Param data body []dto.SharedAssignmentRequest true "Assignments"
+// @Success 200 {object} dto.SharedEventResponse
+// @Failure 400 {object} response.R
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 404 {object} response.R
+// @Failure 409 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/events/{id}/shared/assignments [put]
+func putSharedAssignments(c *gin.Context) {
+	var data []*dto.SharedAssignmentRequest
+	if err := c.ShouldBind(&data); err != nil {
+		response.RespondError(c, http.StatusBadRequest, "Bad Request")
+		return
+	}
+
+	event, err := database.GetEvent(c.Param("id"))
+	if err != nil {
+		log.Errorf("Error getting event: %s", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	if event == nil {
+		response.RespondError(c, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	host := events.HostFacility()
+	facility := authMiddleware.APIFacility(c)
+	if facility == host || !events.Participates(event, host, facility) {
+		response.RespondError(c, http.StatusForbidden, "Forbidden")
+		return
+	}
+
+	if event.EndDate.Before(time.Now()) {
+		response.RespondError(c, http.StatusConflict, "Event has ended")
+		return
+	}
+
+	var positions []*models.EventPosition
+	var shifts []*models.EventShift
+	for _, a := range data {
+		position := findPositionByID(event, a.PositionID)
+		if position == nil {
+			response.RespondError(c, http.StatusNotFound, fmt.Sprintf("Position %d not found", a.PositionID))
+			return
+		}
+		if events.PositionFacility(position, host) != facility {
+			response.RespondError(c, http.StatusForbidden, fmt.Sprintf("%s is not staffed by %s", position.Position, facility))
+			return
+		}
+		if a.CID != 0 && a.Name == "" {
+			response.RespondError(c, http.StatusBadRequest, "Name is required")
+			return
+		}
+
+		var cid *uint
+		if a.CID != 0 {
+			cid = &a.CID
+		}
+
+		if a.ShiftID == 0 {
+			if len(position.Shifts) > 0 {
+				response.RespondError(c, http.StatusBadRequest, "Position is split into shifts, assign the shifts instead")
+				return
+			}
+			position.UserID, position.User = nil, nil
+			position.ExternalCID, position.ExternalName = cid, a.Name
+			positions = append(positions, position)
+			continue
+		}
+
+		var shift *models.EventShift
+		for _, s := range position.Shifts {
+			if s.ID == a.ShiftID {
+				shift = s
+			}
+		}
+		if shift == nil {
+			response.RespondError(c, http.StatusNotFound, fmt.Sprintf("Shift %d not found", a.ShiftID))
+			return
+		}
+		shift.UserID, shift.User = nil, nil
+		shift.ExternalCID, shift.ExternalName = cid, a.Name
+		shifts = append(shifts, shift)
+	}
+
+	if msg := sharedConflict(event, facility); msg != "" {
+		response.RespondError(c, http.StatusConflict, msg)
+		return
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		for _, position := range positions {
+			if err := tx.Model(&models.EventPosition{}).Where("id = ?", position.ID).Updates(map[string]interface{}{
+				"user_id":       nil,
+				"external_cid":  position.ExternalCID,
+				"external_name": position.ExternalName,
+			}).Error; err != nil {
+				return err
+			}
+		}
+		for _, shift := range shifts {
+			if err := tx.Model(&models.EventShift{}).Where("id = ?", shift.ID).Updates(map[string]interface{}{
+				"user_id":       nil,
+				"external_cid":  shift.ExternalCID,
+				"external_name": shift.ExternalName,
+			}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Errorf("Error saving %s assignments for event %d: %s", facility, event.ID, err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	response.Respond(c, http.StatusOK, dto.ConvEventToSharedEventResponse(event, host))
+}
+
+// applyPositionFacility tags a position with the facility staffing it, returning if the facility changed or a message
+// if the facility does not co-host the event. An empty facility leaves the position as it is.
+func applyPositionFacility(event *models.Event, position *models.EventPosition, facility string) (bool, string) {
+	if facility == "" {
+		return false, ""
+	}
+
+	host := events.HostFacility()
+	facility, err := events.NormalizeFacility(facility)
+	if err != nil || !events.Participates(event, host, facility) {
+		return false, "Facility does not co-host the event"
+	}
+	if facility == host {
+		facility = ""
+	}
+	if position.Facility == facility {
+		return false, ""
+	}
+
+	// Controllers assigned by the facility the position was taken from no longer staff it
+	position.Facility = facility
+	position.ExternalCID = nil
+	position.ExternalName = ""
+	for _, shift := range position.Shifts {
+		shift.ExternalCID = nil
+		shift.ExternalName = ""
+	}
+
+	return position.ID != 0, ""
+}
+
+func findPositionByID(event *models.Event, id uint) *models.EventPosition {
+	for _, position := range event.Positions {
+		if position.ID == id {
+			return position
+		}
+	}
+
+	return nil
+}
+
+// sharedConflict returns a message if a controller of the facility is assigned to overlapping positions or shifts,
+// empty if there is none
+func sharedConflict(event *models.Event, facility string) string {
+	type slot struct {
+		name       string
+		start, end time.Time
+	}
+	assigned := map[uint][]slot{}
+	for _, position := range event.Positions {
+		if position.Facility != facility {
+			continue
+		}
+		if position.ExternalCID != nil {
+			assigned[*position.ExternalCID] = append(assigned[*position.ExternalCID], slot{position.Position, event.StartDate, event.EndDate})
+		}
+		for _, shift := range position.Shifts {
+			if shift.ExternalCID != nil {
+				assigned[*shift.ExternalCID] = append(assigned[*shift.ExternalCID], slot{events.SlotName(position, shift), shift.StartsAt, shift.EndsAt})
+			}
+		}
+	}
+
+	for cid, slots := range assigned {
+		for i := range slots {
+			for j := i + 1; j < len(slots); j++ {
+				if events.Overlaps(slots[i].start, slots[i].end, slots[j].start, slots[j].end) {
+					return fmt.Sprintf("%d is assigned to both %s and %s", cid, slots[i].name, slots[j].name)
+				}
+			}
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,297 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package event
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/adh-partnership/api/pkg/database"
+	"github.com/adh-partnership/api/pkg/database/dto"
+	"github.com/adh-partnership/api/pkg/database/models"
+	"github.com/adh-partnership/api/pkg/events"
+	"github.com/adh-partnership/api/pkg/gin/response"
+)
+
+// Add Event Shift
+// @Summary Add Event Shift
+// @Description Split a position into shifts by adding one. Shifts of a position cannot overlap and must be within the
+// @Description event. Assigning a controller that cannot work the shift needs override, and a controller can never
+// @Description be assigned to overlapping shifts or positions.
+// @Tags Events
+// @Param id path string true "Event ID"
+// @Param position path string true "Position Name"
+// @Param shift body dto.EventShiftRequest true "Shift. CID 0 means unassigned."
+// @Success 200 {object} models.Event
+// @Failure 400 {object} response.R
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 404 {object} response.R
+// @Failure 409 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/events/{id}/positions/{position}/shifts [post]
+func postEventShift(c *gin.Context) {
+	event, position, ok := findEventPosition(c)
+	if !ok {
+		return
+	}
+
+	data := &dto.EventShiftRequest{}
+	if err := c.ShouldBind(&data); err != nil || data.StartsAt == nil || data.EndsAt == nil {
+		response.RespondError(c, http.StatusBadRequest, "Bad Request")
+		return
+	}
+
+	if position.UserID != nil {
+		response.RespondError(c, http.StatusConflict, "Unassign the position before splitting it into shifts")
+		return
+	}
+
+	shift := &models.EventShift{EventPositionID: position.ID}
+	if !applyShift(c, event, position, shift, data) {
+		return
+	}
+
+	if err := database.DB.Create(shift).Error; err != nil {
+		log.Errorf("Error adding event shift: %s", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	respondEvent(c)
+}
+
+// Update Event Shift
+// @Summary Update Event Shift
+// @Description Update a shift of a position. Assigning a controller that cannot work the shift needs override, and a
+// @Description controller can never be assigned to overlapping shifts or positions.
+// @Tags Events
+// @Param id path string true "Event ID"
+// @Param position path string true "Position Name"
+// @Param shift path string true "Shift ID"
+// @Param data body dto.EventShiftRequest true "Shift. CID 0 means unassigned, times left out are unchanged."
+// @Success 200 {object} models.Event
+// @Failure 400 {object} response.R
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 404 {object} response.R
+// @Failure 409 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/events/{id}/positions/{position}/shifts/{shift} [put]
+func putEventShift(c *gin.Context) {
+	event, position, ok := findEventPosition(c)
+	if !ok {
+		return
+	}
+
+	data := &dto.EventShiftRequest{}
+	if err := c.ShouldBind(&data); err != nil {
+		response.RespondError(c, http.StatusBadRequest, "Bad Request")
+		return
+	}
+
+	shift := findShift(position, c.Param("shift"))
+	if shift == nil {
+		response.RespondError(c, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	if data.StartsAt == nil {
+		data.StartsAt = &shift.StartsAt
+	}
+	if data.EndsAt == nil {
+		data.EndsAt = &shift.EndsAt
+	}
+	if !applyShift(c, event, position, shift, data) {
+		return
+	}
+
+	if err := database.DB.Omit("User", "ProposedUser").Save(shift).Error; err != nil {
+		log.Errorf("Error updating event shift: %s", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	respondEvent(c)
+}
+
+// Delete Event Shift
+// @Summary Delete Event Shift
+// @Tags Events
+// @Param id path string true "Event ID"
+// @Param position path string true "Position Name"
+// @Param shift path string true "Shift ID"
+// @Success 204
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 404 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/events/{id}/positions/{position}/shifts/{shift} [delete]
+func deleteEventShift(c *gin.Context) {
+	_, position, ok := findEventPosition(c)
+	if !ok {
+		return
+	}
+
+	shift := findShift(position, c.Param("shift"))
+	if shift == nil {
+		response.RespondError(c, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	if err := database.DB.Delete(shift).Error; err != nil {
+		log.Errorf("Error deleting event shift: %s", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	response.RespondBlank(c, http.StatusNoContent)
+}
+
+// applyShift validates a shift request and sets it on the shift, responding with an error and returning false if it
+// is invalid
+func applyShift(c *gin.Context, event *models.Event, position *models.EventPosition, shift *models.EventShift, data *dto.EventShiftRequest) bool {
+	if !data.StartsAt.Before(*data.EndsAt) {
+		response.RespondError(c, http.StatusBadRequest, "Shift must start before it ends")
+		return false
+	}
+	if data.StartsAt.Before(event.StartDate) || data.EndsAt.After(event.EndDate) {
+		response.RespondError(c, http.StatusBadRequest, "Shift must be within the event")
+		return false
+	}
+
+	for _, s := range position.Shifts {
+		if s.ID != shift.ID && events.Overlaps(s.StartsAt, s.EndsAt, *data.StartsAt, *data.EndsAt) {
+			response.RespondError(c, http.StatusConflict, fmt.Sprintf("Shift overlaps the %s to %s shift of %s", s.StartsAt.UTC().Format("15:04Z"),
+				s.EndsAt.UTC().Format("15:04Z"), position.Position))
+			return false
+		}
+	}
+
+	shift.StartsAt = *data.StartsAt
+	shift.EndsAt = *data.EndsAt
+	shift.UserID = nil
+	shift.User = nil
+
+	if data.UserID == 0 {
+		return true
+	}
+
+	user, err := database.FindUserByCID(fmt.Sprint(data.UserID))
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Errorf("Error getting user: %s", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return false
+	}
+	if user == nil {
+		response.RespondError(c, http.StatusNotFound, "User Not Found")
+		return false
+	}
+
+	if !checkAssignment(c, event, position, shift, user, data.Override) {
+		return false
+	}
+	shift.UserID = &user.CID
+	shift.ExternalCID = nil
+	shift.ExternalName = ""
+
+	return true
+}
+
+// checkAssignment responds with an error and returns false if the controller cannot be assigned to the position, or
+// to the shift of it if shift is not nil. Controllers that are not eligible or not available can still be assigned
+// with override, but never to overlapping positions or shifts.
+func checkAssignment(c *gin.Context, event *models.Event, position *models.EventPosition, shift *models.EventShift, user *models.User, override bool) bool {
+	violations, err := assignmentViolations(event, position, shift, user)
+	if err != nil {
+		log.Errorf("Error checking eligibility of %d for %s: %s", user.CID, position.Position, err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return false
+	}
+
+	if len(violations) > 0 && !override {
+		response.RespondError(c, http.StatusBadRequest, fmt.Sprintf("Controller is not eligible for %s: %s; set override to assign anyway",
+			position.Position, strings.Join(violations, "; ")))
+		return false
+	}
+
+	if len(violations) > 0 {
+		log.Infof("Ineligible controller %d assigned to %s with override: %s", user.CID, position.Position, strings.Join(violations, "; "))
+	}
+
+	start, end := event.StartDate, event.EndDate
+	if shift != nil {
+		start, end = shift.StartsAt, shift.EndsAt
+	}
+	if conflicts := events.Conflicts(userBookings(event, user.CID, position, shift), start, end); len(conflicts) > 0 {
+		response.RespondError(c, http.StatusConflict, fmt.Sprintf("Controller is already assigned to %s at the same time", conflicts[0].Position))
+		return false
+	}
+
+	return true
+}
+
+// findEventPosition gets the event and position of the request, responding with an error and returning false if
+// either is not found
+func findEventPosition(c *gin.Context) (*models.Event, *models.EventPosition, bool) {
+	event, err := database.GetEvent(c.Param("id"))
+	if err != nil {
+		log.Errorf("Error getting event: %s", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return nil, nil, false
+	}
+
+	if event == nil {
+		response.RespondError(c, http.StatusNotFound, "Not Found")
+		return nil, nil, false
+	}
+
+	for _, position := range event.Positions {
+		if position.Position == c.Param("position") {
+			return event, position, true
+		}
+	}
+
+	response.RespondError(c, http.StatusNotFound, "Not Found")
+	return nil, nil, false
+}
+
+func findShift(position *models.EventPosition, id string) *models.EventShift {
+	for _, shift := range position.Shifts {
+		if fmt.Sprint(shift.ID) == id {
+			return shift
+		}
+	}
+
+	return nil
+}
+
+func respondEvent(c *gin.Context) {
+	event, err := database.GetEvent(c.Param("id"))
+	if err != nil {
+		log.Errorf("Error getting event: %s", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	response.Respond(c, http.StatusOK, event)
+}
@@ -17,7 +17,10 @@
 package event
 
 import (
+	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -25,12 +28,16 @@ import (
 	"github.com/adh-partnership/api/pkg/database"
 	"github.com/adh-partnership/api/pkg/database/dto"
 	"github.com/adh-partnership/api/pkg/database/models"
+	"github.com/adh-partnership/api/pkg/events"
 	"github.com/adh-partnership/api/pkg/gin/response"
 )
 
 // Create/Edit User Signup for Event
 // @Summary Create/Edit User Signup for Event
-// @Description Create/Edit User Signup for Event. This will only work for the logged in user.
+// @Description Create/Edit User Signup for Event. This will only work for the logged in user. Each choice must be
+// @Description one of the event's positions that the user is eligible to work. The availability window is open ended
+// @Description where left out. Signups cannot be made or changed once they close, and new signups beyond the
+// @Description event's cap are waitlisted.
 // @Tags Events
 // @Param id path string true "Event ID"
 // @Param signup body dto.EventSignupRequest true "Signup"
@@ -38,6 +45,7 @@ import (
 // @Failure 400 {object} response.R
 // @Failure 403 {object} response.R
 // @Failure 404 {object} response.R
+// @Failure 409 {object} response.R
 // @Failure 500 {object} response.R
 // @Router /v1/events/{id}/signup [post]
 func postEventSignup(c *gin.Context) {
@@ -53,6 +61,11 @@ func postEventSignup(c *gin.Context) {
 		return
 	}
 
+	if !events.SignupsOpen(event.SignupsCloseAt, time.Now()) {
+		response.RespondError(c, http.StatusConflict, "Signups are closed")
+		return
+	}
+
 	data := &dto.EventSignupRequest{}
 	if err := c.ShouldBind(&data); err != nil {
 		response.RespondError(c, http.StatusBadRequest, "Invalid request")
@@ -61,6 +74,20 @@ func postEventSignup(c *gin.Context) {
 
 	user := c.MustGet("x-user").(*models.User)
 
+	if data.AvailableFrom != nil && data.AvailableUntil != nil && !data.AvailableFrom.Before(*data.AvailableUntil) {
+		response.RespondError(c, http.StatusBadRequest, "Availability must start before it ends")
+		return
+	}
+
+	if msg, err := checkSignupChoices(event, user, data); err != nil {
+		log.Errorf("Error checking signup choices of %d: %s", user.CID, err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	} else if msg != "" {
+		response.RespondError(c, http.StatusBadRequest, msg)
+		return
+	}
+
 	signup := &models.EventSignup{}
 	if err := database.DB.Where("event_id = ? AND user_id = ?", event.ID, user.CID).First(signup).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
@@ -71,6 +98,10 @@ func postEventSignup(c *gin.Context) {
 				Choice2: data.Choice2,
 				Choice3: data.Choice3,
 				Notes:   data.Notes,
+
+				AvailableFrom:  data.AvailableFrom,
+				AvailableUntil: data.AvailableUntil,
+				Waitlisted:     events.Waitlisted(event.SignupCap, activeSignups(event)),
 			}
 			if err := database.DB.Create(signup).Error; err != nil {
 				log.Errorf("Error creating event signup: %s", err)
@@ -87,6 +118,8 @@ func postEventSignup(c *gin.Context) {
 		signup.Choice2 = data.Choice2
 		signup.Choice3 = data.Choice3
 		signup.Notes = data.Notes
+		signup.AvailableFrom = data.AvailableFrom
+		signup.AvailableUntil = data.AvailableUntil
 
 		if err := database.DB.Save(&signup).Error; err != nil {
 			log.Errorf("Error updating event signup: %s", err)
@@ -101,12 +134,15 @@ func postEventSignup(c *gin.Context) {
 
 // Delete User Signup
 // @Summary Delete User Signup
-// @Description Delete User Signup. This will only work for the logged in user.
+// @Description Withdraw the logged in user from an event. Once assignments are published, the user's positions are
+// @Description freed and given to the first waitlisted controller that can work them, and events staff are told.
+// @Description Controllers cannot withdraw once the event has started.
 // @Tags Events
 // @Param id path string true "Event ID"
 // @Success 204
 // @Failure 403 {object} response.R
 // @Failure 404 {object} response.R
+// @Failure 409 {object} response.R
 // @Failure 500 {object} response.R
 // @Router /v1/events/{id}/signup [delete]
 func deleteEventSignup(c *gin.Context) {
@@ -124,22 +160,69 @@ func deleteEventSignup(c *gin.Context) {
 
 	user := c.MustGet("x-user").(*models.User)
 
-	found := false
-	for i := range event.Signups {
-		if *event.Signups[i].UserID == user.CID {
-			if err := database.DB.Delete(event.Signups[i]).Error; err != nil {
-				log.Errorf("Error deleting event signup: %s", err)
-				response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
-				return
-			}
-			found = true
-		}
+	signup := findSignup(event, user.CID)
+	if signup == nil {
+		response.RespondError(c, http.StatusNotFound, "Not Found")
+		return
 	}
 
-	if !found {
-		response.RespondError(c, http.StatusNotFound, "Not Found")
+	if !event.StartDate.After(time.Now()) {
+		response.RespondError(c, http.StatusConflict, "Event has started")
+		return
+	}
+
+	result, err := withdraw(event, signup)
+	if err != nil {
+		log.Errorf("Error withdrawing %d from event %d: %s", user.CID, event.ID, err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
 		return
 	}
 
+	if events.NotificationsEnabled() {
+		go notifyWithdrawal(event, user, result)
+	}
+
 	response.RespondBlank(c, http.StatusNoContent)
 }
+
+// checkSignupChoices validates that every choice of a signup is a position of the event the user can work, and
+// rewrites the choices to the position names as the event spells them. Events that have no positions yet accept any
+// choice. A non-empty string is returned if a choice is invalid.
+func checkSignupChoices(event *models.Event, user *models.User, data *dto.EventSignupRequest) (string, error) {
+	ratings, err := database.FindRatingNames(database.DB)
+	if err != nil {
+		return "", err
+	}
+
+	candidate, err := findCandidate(user)
+	if err != nil {
+		return "", err
+	}
+
+	for _, choice := range []*string{&data.Choice1, &data.Choice2, &data.Choice3} {
+		if *choice == "" {
+			continue
+		}
+
+		var position *models.EventPosition
+		for _, p := range event.Positions {
+			if strings.EqualFold(p.Position, *choice) {
+				position = p
+				break
+			}
+		}
+		if position == nil {
+			if len(event.Positions) > 0 {
+				return fmt.Sprintf("%s is not a position of this event", *choice), nil
+			}
+			continue
+		}
+
+		if violations := events.Violations(buildSlot(position, ratings), candidate); len(violations) > 0 {
+			return fmt.Sprintf("Not eligible for %s: %s", position.Position, strings.Join(violations, "; ")), nil
+		}
+		*choice = position.Position
+	}
+
+	return "", nil
+}
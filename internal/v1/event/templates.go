@@ -0,0 +1,327 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package event
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/adh-partnership/api/pkg/config"
+	"github.com/adh-partnership/api/pkg/database"
+	"github.com/adh-partnership/api/pkg/database/dto"
+	"github.com/adh-partnership/api/pkg/database/models"
+	"github.com/adh-partnership/api/pkg/events"
+	"github.com/adh-partnership/api/pkg/gin/response"
+)
+
+// Get Event Templates
+// @Summary Get Event Templates
+// @Description Get every event template with its positions and recurrence rule
+// @Tags Events
+// @Success 200 {object} []models.EventTemplate
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/events/templates [get]
+func getEventTemplates(c *gin.Context) {
+	templates, err := database.FindEventTemplates()
+	if err != nil {
+		log.Errorf("Error getting event templates: %s", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	response.Respond(c, http.StatusOK, templates)
+}
+
+// Get Event Template
+// @Summary Get Event Template
+// @Tags Events
+// @Param template path string true "Template ID"
+// @Success 200 {object} models.EventTemplate
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 404 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/events/templates/{template} [get]
+func getEventTemplate(c *gin.Context) {
+	template, ok := findTemplate(c)
+	if !ok {
+		return
+	}
+
+	response.Respond(c, http.StatusOK, template)
+}
+
+// Create Event Template
+// @Summary Create Event Template
+// @Description Create an event template. Templates with a frequency recur, weekly every given number of weeks or
+// @Description monthly on a week of the month such as the 2nd Friday, and their events are created ahead of time.
+// @Tags Events
+// @Param data body dto.EventTemplateRequest true "Template"
+// @Success 201 {object} models.EventTemplate
+// @Failure 400 {object} response.R
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/events/templates [post]
+func postEventTemplate(c *gin.Context) {
+	data := &dto.EventTemplateRequest{}
+	if err := c.ShouldBind(&data); err != nil {
+		response.RespondError(c, http.StatusBadRequest, "Bad Request")
+		return
+	}
+
+	template := &models.EventTemplate{}
+	if msg, err := applyTemplate(template, data); err != nil {
+		log.Errorf("Error building event template: %s", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	} else if msg != "" {
+		response.RespondError(c, http.StatusBadRequest, msg)
+		return
+	}
+
+	if err := database.DB.Create(template).Error; err != nil {
+		log.Errorf("Error creating event template: %s", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	generateTemplateEvents(template)
+	response.Respond(c, http.StatusCreated, template)
+}
+
+// Update Event Template
+// @Summary Update Event Template
+// @Description Replace an event template. Events already created from it are not changed, and later events of the
+// @Description series follow the new rule.
+// @Tags Events
+// @Param template path string true "Template ID"
+// @Param data body dto.EventTemplateRequest true "Template"
+// @Success 200 {object} models.EventTemplate
+// @Failure 400 {object} response.R
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 404 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/events/templates/{template} [put]
+func putEventTemplate(c *gin.Context) {
+	data := &dto.EventTemplateRequest{}
+	if err := c.ShouldBind(&data); err != nil {
+		response.RespondError(c, http.StatusBadRequest, "Bad Request")
+		return
+	}
+
+	template, ok := findTemplate(c)
+	if !ok {
+		return
+	}
+
+	if msg, err := applyTemplate(template, data); err != nil {
+		log.Errorf("Error building event template: %s", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	} else if msg != "" {
+		response.RespondError(c, http.StatusBadRequest, msg)
+		return
+	}
+
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("event_template_id = ?", template.ID).Delete(&models.EventTemplatePosition{}).Error; err != nil {
+			return err
+		}
+		for _, position := range template.Positions {
+			position.EventTemplateID = template.ID
+		}
+		return tx.Save(template).Error
+	}); err != nil {
+		log.Errorf("Error updating event template %d: %s", template.ID, err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	generateTemplateEvents(template)
+	response.Respond(c, http.StatusOK, template)
+}
+
+// Delete Event Template
+// @Summary Delete Event Template
+// @Description Delete an event template, ending its series. Events already created from it are kept.
+// @Tags Events
+// @Param template path string true "Template ID"
+// @Success 204
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 404 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/events/templates/{template} [delete]
+func deleteEventTemplate(c *gin.Context) {
+	template, ok := findTemplate(c)
+	if !ok {
+		return
+	}
+
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(&models.Event{}).Where("template_id = ?", template.ID).Update("template_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("event_template_id = ?", template.ID).Delete(&models.EventTemplatePosition{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(template).Error
+	}); err != nil {
+		log.Errorf("Error deleting event template %d: %s", template.ID, err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	response.RespondBlank(c, http.StatusNoContent)
+}
+
+// Create Event From Template
+// @Summary Create Event From Template
+// @Description Create a single event from a template, starting at the given time
+// @Tags Events
+// @Param template path string true "Template ID"
+// @Param data body dto.EventFromTemplateRequest true "Start of the event"
+// @Success 201 {object} dto.EventsResponse
+// @Failure 400 {object} response.R
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 404 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/events/templates/{template}/events [post]
+func postEventFromTemplate(c *gin.Context) {
+	data := &dto.EventFromTemplateRequest{}
+	if err := c.ShouldBind(&data); err != nil || data.StartDate == nil {
+		response.RespondError(c, http.StatusBadRequest, "Bad Request")
+		return
+	}
+
+	template, ok := findTemplate(c)
+	if !ok {
+		return
+	}
+
+	event := events.NewFromTemplate(template, data.StartDate.UTC())
+	if err := database.DB.Create(event).Error; err != nil {
+		log.Errorf("Error creating event from template %d: %s", template.ID, err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	if events.NotificationsEnabled() {
+		events.AnnounceNew(event)
+	}
+
+	event, err := database.GetEvent(fmt.Sprint(event.ID))
+	if err != nil {
+		log.Errorf("Error getting event: %s", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	response.Respond(c, http.StatusCreated, dto.ConvEventToEventsResponse(event))
+}
+
+func findTemplate(c *gin.Context) (*models.EventTemplate, bool) {
+	template, err := database.FindEventTemplate(c.Param("template"))
+	if err != nil {
+		log.Errorf("Error getting event template: %s", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return nil, false
+	}
+	if template == nil {
+		response.RespondError(c, http.StatusNotFound, "Not Found")
+		return nil, false
+	}
+
+	return template, true
+}
+
+// applyTemplate copies the request onto the template, returning a message for the requester if it is invalid
+func applyTemplate(template *models.EventTemplate, data *dto.EventTemplateRequest) (string, error) {
+	if data.Title == "" {
+		return "Title is required", nil
+	}
+	if data.DurationMinutes <= 0 {
+		return "Duration must be at least a minute", nil
+	}
+
+	template.Title = data.Title
+	template.Description = data.Description
+	template.Banner = data.Banner
+	template.Frequency = strings.ToLower(data.Frequency)
+	template.Every = data.Every
+	template.Weekday = strings.ToLower(data.Weekday)
+	template.WeekOfMonth = data.WeekOfMonth
+	template.StartTime = data.StartTime
+	template.DurationMinutes = data.DurationMinutes
+	template.StartsOn = time.Time{}
+	if data.StartsOn != nil {
+		template.StartsOn = data.StartsOn.UTC()
+	}
+	template.EndsOn = data.EndsOn
+
+	if _, err := events.TemplateRecurrence(template); err != nil {
+		return fmt.Sprintf("Invalid recurrence: %s", err), nil
+	}
+
+	template.Positions = []*models.EventTemplatePosition{}
+	seen := map[string]bool{}
+	for _, p := range data.Positions {
+		name := strings.ToUpper(strings.TrimSpace(p.Position))
+		if name == "" || seen[name] {
+			return fmt.Sprintf("Invalid or duplicate position %q", p.Position), nil
+		}
+		seen[name] = true
+
+		position := &models.EventTemplatePosition{Position: name}
+		if p.MinRating != "" {
+			rating, err := database.FindRatingByShort(p.MinRating)
+			if err != nil {
+				return "", err
+			}
+			if rating == nil {
+				return fmt.Sprintf("Invalid rating %s", p.MinRating), nil
+			}
+			position.MinRatingID = rating.ID
+		}
+		template.Positions = append(template.Positions, position)
+	}
+
+	return "", nil
+}
+
+// generateTemplateEvents creates the upcoming events of a recurring template right away rather than waiting for the
+// scheduled job
+func generateTemplateEvents(template *models.EventTemplate) {
+	now := time.Now()
+	created, err := events.GenerateRecurring(template, now, now.AddDate(0, 0, config.Cfg.Facility.Events.RecurringDaysAhead))
+	if err != nil {
+		log.Errorf("Error generating events of template %d: %s", template.ID, err)
+	}
+	for _, event := range created {
+		log.Infof("Created event %d, %s at %s, from template %d", event.ID, event.Title, event.StartDate, template.ID)
+	}
+}
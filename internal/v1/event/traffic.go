@@ -0,0 +1,106 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package event
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/adh-partnership/api/pkg/database"
+	"github.com/adh-partnership/api/pkg/database/dto"
+	"github.com/adh-partnership/api/pkg/events"
+	"github.com/adh-partnership/api/pkg/gin/response"
+)
+
+// Get Event Traffic Statistics
+// @Summary Get Event Traffic Statistics
+// @Description Get the departures and arrivals at the event's airports during the event, with unique callsigns and
+// @Description peak hourly rates, compared with the same window one week before
+// @Tags Events
+// @Param id path string true "Event ID"
+// @Success 200 {object} dto.EventTrafficResponse
+// @Failure 404 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/events/{id}/stats [get]
+func getEventTraffic(c *gin.Context) {
+	event, err := database.GetEvent(c.Param("id"))
+	if err != nil {
+		log.Errorf("Error getting event: %s", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+	if event == nil {
+		response.RespondError(c, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	airports, err := events.EventAirports(event)
+	if err != nil {
+		log.Errorf("Error getting airports of event %d: %s", event.ID, err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	ret := &dto.EventTrafficResponse{
+		EventID:          event.ID,
+		Airports:         []string{},
+		BaselineRecorded: !event.CreatedAt.After(event.StartDate.Add(-events.BaselineOffset)),
+	}
+	for _, airport := range airports {
+		ret.Airports = append(ret.Airports, airport.ICAO)
+	}
+
+	baselineStart := event.StartDate.Add(-events.BaselineOffset)
+	var movements []*events.Movement
+	if len(ret.Airports) > 0 {
+		records, err := database.FindFlightMovements(ret.Airports, baselineStart, event.EndDate)
+		if err != nil {
+			log.Errorf("Error getting movements of event %d: %s", event.ID, err)
+			response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+		for _, m := range records {
+			movements = append(movements, &events.Movement{Airport: m.Airport, Callsign: m.Callsign, Kind: m.Kind, At: m.MovedAt})
+		}
+	}
+
+	ret.Event = convTraffic(events.CountTraffic(movements, event.StartDate, event.EndDate))
+	ret.Baseline = convTraffic(events.CountTraffic(movements, baselineStart, event.EndDate.Add(-events.BaselineOffset)))
+
+	response.Respond(c, http.StatusOK, ret)
+}
+
+func convTraffic(t *events.Traffic) *dto.EventTraffic {
+	ret := &dto.EventTraffic{
+		StartsAt:             t.Start.UTC().Truncate(time.Second),
+		EndsAt:               t.End.UTC().Truncate(time.Second),
+		Departures:           t.Departures,
+		Arrivals:             t.Arrivals,
+		UniqueCallsigns:      t.UniqueCallsigns,
+		PeakHourlyDepartures: t.PeakHourlyDepartures,
+		PeakHourlyArrivals:   t.PeakHourlyArrivals,
+		PeakHourlyMovements:  t.PeakHourlyMovements,
+		Airports:             map[string]*dto.EventAirportTraffic{},
+	}
+	for icao, a := range t.Airports {
+		ret.Airports[icao] = &dto.EventAirportTraffic{Departures: a.Departures, Arrivals: a.Arrivals, UniqueCallsigns: a.UniqueCallsigns}
+	}
+
+	return ret
+}
@@ -0,0 +1,187 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package event
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/adh-partnership/api/pkg/database"
+	"github.com/adh-partnership/api/pkg/database/models"
+	"github.com/adh-partnership/api/pkg/events"
+)
+
+// withdrawal is what changed when a controller withdrew from an event
+type withdrawal struct {
+	// Positions and shifts the controller was assigned to
+	Freed []string
+	// Freed positions and shifts given to a waitlisted controller, and those nobody could take
+	Filled []string
+	Vacant []string
+	// Controllers that left the waitlist, and those of them that were assigned a freed position or shift
+	Promoted []*models.User
+	Assigned []*models.User
+}
+
+// activeSignups returns how many of the event's signups are not waitlisted
+func activeSignups(event *models.Event) int {
+	n := 0
+	for _, signup := range event.Signups {
+		if !signup.Waitlisted {
+			n++
+		}
+	}
+
+	return n
+}
+
+// withdraw deletes the signup. Once assignments are published, the positions and shifts of the controller are freed
+// and each goes to the first waitlisted controller that can work it. A signup leaving room under the cap also lets
+// the next waitlisted signup in.
+func withdraw(event *models.Event, signup *models.EventSignup) (*withdrawal, error) {
+	ret := &withdrawal{}
+
+	var waitlist []*models.EventSignup
+	for _, s := range event.Signups {
+		if s.Waitlisted && s.ID != signup.ID {
+			waitlist = append(waitlist, s)
+		}
+	}
+	candidates, err := signupCandidates(waitlist)
+	if err != nil {
+		return nil, err
+	}
+	ratings, err := database.FindRatingNames(database.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(signup).Error; err != nil {
+			return err
+		}
+
+		promote := func(s *models.EventSignup) error {
+			s.Waitlisted = false
+			ret.Promoted = append(ret.Promoted, s.User)
+			return tx.Model(&models.EventSignup{}).Where("id = ?", s.ID).Update("waitlisted", false).Error
+		}
+
+		if event.AssignmentsPublishedAt != nil {
+			slots := buildRosterSlots(event, ratings)
+			var freed []*rosterSlot
+			for _, slot := range slots {
+				if cid, _ := slot.assigned(); cid != nil && *cid == *signup.UserID {
+					if err := assignSlot(tx, slot, nil); err != nil {
+						return err
+					}
+					freed = append(freed, slot)
+				}
+			}
+
+			bookings := map[uint][]*events.Booking{}
+			for _, slot := range slots {
+				if cid, _ := slot.assigned(); cid != nil {
+					bookings[*cid] = append(bookings[*cid], &events.Booking{Position: slot.Position.Position, Start: slot.Slot.Start, End: slot.Slot.End})
+				}
+			}
+
+			for _, slot := range freed {
+				name := events.SlotName(slot.Position, slot.Shift)
+				ret.Freed = append(ret.Freed, name)
+
+				candidate := events.Promote(slot.Slot, candidates, bookings)
+				if candidate == nil {
+					ret.Vacant = append(ret.Vacant, name)
+					continue
+				}
+
+				promoted := findSignup(event, candidate.CID)
+				if err := assignSlot(tx, slot, promoted.User); err != nil {
+					return err
+				}
+				bookings[candidate.CID] = append(bookings[candidate.CID], &events.Booking{Position: slot.Position.Position, Start: slot.Slot.Start, End: slot.Slot.End})
+				ret.Filled = append(ret.Filled, fmt.Sprintf("%s to %s %s (%d)", name, promoted.User.FirstName, promoted.User.LastName, candidate.CID))
+				ret.Assigned = append(ret.Assigned, promoted.User)
+				if promoted.Waitlisted {
+					if err := promote(promoted); err != nil {
+						return err
+					}
+				}
+			}
+		}
+
+		if signup.Waitlisted {
+			return nil
+		}
+
+		var waiting []*models.EventSignup
+		for _, s := range waitlist {
+			if s.Waitlisted {
+				waiting = append(waiting, s)
+			}
+		}
+		for _, s := range waiting[:events.Openings(event.SignupCap, activeSignups(event)-1, len(waiting))] {
+			if err := promote(s); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// promoteWaitlist lets waitlisted signups in, in the order they signed up, while there is room under the cap
+func promoteWaitlist(event *models.Event) error {
+	var waiting []*models.EventSignup
+	for _, s := range event.Signups {
+		if s.Waitlisted {
+			waiting = append(waiting, s)
+		}
+	}
+
+	for _, s := range waiting[:events.Openings(event.SignupCap, activeSignups(event), len(waiting))] {
+		if err := database.DB.Model(&models.EventSignup{}).Where("id = ?", s.ID).Update("waitlisted", false).Error; err != nil {
+			return err
+		}
+		s.Waitlisted = false
+	}
+
+	return nil
+}
+
+// assignSlot gives the position or shift of the slot to the user, or frees it when user is nil
+func assignSlot(tx *gorm.DB, slot *rosterSlot, user *models.User) error {
+	var cid *uint
+	if user != nil {
+		cid = &user.CID
+	}
+
+	if slot.Shift != nil {
+		slot.Shift.UserID, slot.Shift.User = cid, user
+		return tx.Model(&models.EventShift{}).Where("id = ?", slot.Shift.ID).Update("user_id", cid).Error
+	}
+
+	slot.Position.UserID, slot.Position.User = cid, user
+	return tx.Model(&models.EventPosition{}).Where("id = ?", slot.Position.ID).Update("user_id", cid).Error
+}
@@ -0,0 +1,87 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package feedback
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/adh-partnership/api/pkg/database"
+	"github.com/adh-partnership/api/pkg/database/dto"
+	"github.com/adh-partnership/api/pkg/feedback"
+	"github.com/adh-partnership/api/pkg/gin/response"
+	"github.com/adh-partnership/api/pkg/logger"
+)
+
+var log = logger.Logger.WithField("component", "feedback")
+
+// Get Feedback Analytics
+// @Summary Get Feedback Analytics
+// @Description Get rating distributions and approval rates for the facility, by month, by position prefix and by
+// @Description controller, with the controllers whose share of poor ratings spiked in the last 30 days of the range.
+// @Description Rejected feedback is left out of the ratings. Defaults to the last twelve months.
+// @Tags Feedback
+// @Param from query string false "First day, YYYY-MM-DD"
+// @Param to query string false "Last day, YYYY-MM-DD"
+// @Success 200 {object} dto.FeedbackAnalyticsResponse
+// @Failure 400 {object} response.R
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/feedback/analytics [get]
+func getFeedbackAnalytics(c *gin.Context) {
+	from, to := feedback.DefaultRange(time.Now())
+	if c.Query("from") != "" {
+		t, err := time.Parse("2006-01-02", c.Query("from"))
+		if err != nil {
+			response.RespondError(c, http.StatusBadRequest, "Invalid from")
+			return
+		}
+		from = t
+	}
+	if c.Query("to") != "" {
+		t, err := time.Parse("2006-01-02", c.Query("to"))
+		if err != nil {
+			response.RespondError(c, http.StatusBadRequest, "Invalid to")
+			return
+		}
+		to = t.AddDate(0, 0, 1)
+	}
+	if !to.After(from) {
+		response.RespondError(c, http.StatusBadRequest, "Invalid range")
+		return
+	}
+
+	fs, err := database.FindFeedbackInRange(0, &from, &to)
+	if err != nil {
+		log.Errorf("Error getting feedback: %s", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	response.Respond(c, http.StatusOK, &dto.FeedbackAnalyticsResponse{
+		From:        from,
+		To:          to,
+		Facility:    feedback.SummaryResponse(feedback.Summarize(fs)),
+		Monthly:     feedback.MonthsResponse(feedback.ByMonth(fs, from, to)),
+		Positions:   feedback.PositionsResponse(feedback.ByPositionPrefix(fs)),
+		Controllers: feedback.ControllersResponse(feedback.ByController(fs)),
+		Outliers:    feedback.OutliersResponse(feedback.Outliers(fs, to, feedback.OutlierWindow)),
+	})
+}
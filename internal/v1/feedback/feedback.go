@@ -19,6 +19,7 @@ package feedback
 import (
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -102,7 +103,8 @@ func getSingleFeedback(c *gin.Context) {
 
 // Submit Pilot Feedback
 // @Summary Submit Pilot Feedback
-// @Description Submit feedback for a pilot
+// @Description Submit feedback for a pilot. It is checked against the controller's sessions and the submitter's flights
+// @Description within the facility, and the result is shown to moderators.
 // @Tags Feedback
 // @Param data body dto.FeedbackRequest true "Feedback"
 // @Success 204
@@ -136,13 +138,15 @@ func postFeedback(c *gin.Context) {
 	var err error
 	if dto.Controller != "" {
 		controller, err = database.FindUserByCID(dto.Controller)
-		if err != nil {
+		if err != nil || controller == nil {
 			response.RespondError(c, http.StatusBadRequest, "Invalid controller")
 			return
 		}
 	}
 	feedback := &models.Feedback{
+		SubmitterID:  user.CID,
 		Submitter:    user,
+		ControllerID: controller.CID,
 		Controller:   controller,
 		Rating:       dto.Rating,
 		Comments:     dto.Comments,
@@ -152,6 +156,14 @@ func postFeedback(c *gin.Context) {
 		ContactEmail: user.Email,
 	}
 
+	// Verification only helps moderators, so feedback that could not be checked is still accepted
+	verification := "not checked"
+	if err := verify(feedback, time.Now()); err != nil {
+		log.Errorf("Error verifying feedback for %d: %s", controller.CID, err)
+	} else {
+		verification = fmt.Sprintf("%s (%.0f%%)", feedback.VerificationResult, feedback.VerificationConfidence*100)
+	}
+
 	if err := database.DB.Create(feedback).Error; err != nil {
 		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
 		return
@@ -174,6 +186,9 @@ func postFeedback(c *gin.Context) {
 				).
 				AddField(
 					discord.NewField().SetName("Comments").SetValue(dto.Comments).SetInline(false),
+				).
+				AddField(
+					discord.NewField().SetName("Verification").SetValue(verification).SetInline(false),
 				),
 		).Send("pending_feedback")
 
@@ -24,7 +24,9 @@ import (
 
 func Routes(r *gin.RouterGroup) {
 	r.GET("", getFeedback)
+	r.GET("/analytics", auth.NotGuest, auth.InGroup("admin"), getFeedbackAnalytics)
 	r.GET("/:id", getSingleFeedback)
 	r.POST("", auth.NotGuest, postFeedback)
 	r.PATCH("/:id", auth.NotGuest, auth.InGroup("admin"), patchFeedback)
+	r.POST("/:id/verify", auth.NotGuest, auth.InGroup("admin"), postVerifyFeedback)
 }
@@ -0,0 +1,127 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package feedback
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/adh-partnership/api/pkg/config"
+	"github.com/adh-partnership/api/pkg/database"
+	"github.com/adh-partnership/api/pkg/database/dto"
+	"github.com/adh-partnership/api/pkg/database/models"
+	"github.com/adh-partnership/api/pkg/events"
+	"github.com/adh-partnership/api/pkg/feedback"
+	"github.com/adh-partnership/api/pkg/gin/response"
+)
+
+// Verify Pilot Feedback
+// @Summary Verify Pilot Feedback
+// @Description Check feedback again against the controller's sessions and the flights of the submitter in the hours
+// @Description before it was submitted, such as after the controller's session ended
+// @Tags Feedback
+// @Param id path int true "Feedback ID"
+// @Success 200 {object} dto.FeedbackResponse
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 404 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/feedback/{id}/verify [post]
+func postVerifyFeedback(c *gin.Context) {
+	f := &models.Feedback{}
+	if err := database.DB.Preload(clause.Associations).Where("id = ?", c.Param("id")).First(f).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			response.RespondError(c, http.StatusNotFound, "Invalid feedback ID")
+			return
+		}
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	submitted := time.Now()
+	if f.CreatedAt != nil {
+		submitted = *f.CreatedAt
+	}
+	if err := verify(f, submitted); err != nil {
+		log.Errorf("Error verifying feedback %d: %s", f.ID, err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	if err := database.DB.Model(&models.Feedback{}).Where("id = ?", f.ID).Updates(map[string]interface{}{
+		"verification_result":     f.VerificationResult,
+		"verification_confidence": f.VerificationConfidence,
+		"verification_notes":      f.VerificationNotes,
+		"verified_at":             f.VerifiedAt,
+	}).Error; err != nil {
+		log.Errorf("Error saving verification of feedback %d: %s", f.ID, err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	response.Respond(c, http.StatusOK, dto.ConvertSingleFeedbacktoResponse(f, true))
+}
+
+// verify checks the feedback against the controller's sessions and the submitter's flights in the hours before it
+// was submitted, recording the result on it
+func verify(f *models.Feedback, submitted time.Time) error {
+	from := submitted.Add(-time.Duration(config.Cfg.Facility.Feedback.VerificationHours) * time.Hour)
+
+	stats, err := database.FindControllerStatsBetween([]uint{f.ControllerID}, from, submitted)
+	if err != nil {
+		return err
+	}
+	online, err := database.FindOnlineControllersBefore([]uint{f.ControllerID}, submitted)
+	if err != nil {
+		return err
+	}
+	logs, err := database.FindFlightLogs(f.SubmitterID, f.Callsign, from, submitted)
+	if err != nil {
+		return err
+	}
+
+	var sessions []*events.Session
+	for _, stat := range stats {
+		sessions = append(sessions, &events.Session{
+			Callsign: stat.Position,
+			Start:    stat.LogonTime,
+			End:      stat.LogonTime.Add(time.Duration(stat.Duration) * time.Second),
+		})
+	}
+	for _, controller := range online {
+		sessions = append(sessions, &events.Session{Callsign: controller.Position, Start: controller.LogonTime, End: controller.UpdatedAt})
+	}
+
+	var flights []*feedback.Flight
+	for _, l := range logs {
+		flights = append(flights, &feedback.Flight{Callsign: l.Callsign, CID: uint(l.CID), Start: l.FirstSeen, End: l.LastSeen})
+	}
+
+	v := feedback.Verify(f.Position, f.Callsign, f.SubmitterID, from, submitted, sessions, flights)
+	now := time.Now()
+	f.VerificationResult = v.Result
+	f.VerificationConfidence = v.Confidence
+	f.VerificationNotes = strings.Join(v.Notes, "\n")
+	f.VerifiedAt = &now
+
+	return nil
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/adh-partnership/api/internal/v1/admin"
 	"github.com/adh-partnership/api/internal/v1/airport"
 	"github.com/adh-partnership/api/internal/v1/authorization"
+	"github.com/adh-partnership/api/internal/v1/calendar"
 	"github.com/adh-partnership/api/internal/v1/certifications"
 	"github.com/adh-partnership/api/internal/v1/email"
 	"github.com/adh-partnership/api/internal/v1/event"
@@ -46,6 +47,7 @@ func init() {
 	routeGroups["/admin"] = admin.Routes
 	routeGroups["/airports"] = airport.Routes
 	routeGroups["/authorization"] = authorization.Routes
+	routeGroups["/calendar"] = calendar.Routes
 	routeGroups["/certifications"] = certifications.Routes
 	routeGroups["/email"] = email.Routes
 	routeGroups["/events"] = event.Routes
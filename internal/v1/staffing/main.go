@@ -29,5 +29,10 @@ var log = logger.Logger.WithField("component", "staffing")
 func Routes(r *gin.RouterGroup) {
 	if config.Cfg.Features.StaffingRequest {
 		r.POST("", auth.NotGuest, requestStaffing)
+		r.GET("", auth.NotGuest, auth.InGroup("events"), getStaffingRequests)
+		r.GET("/mine", auth.NotGuest, getMyStaffingRequests)
+		r.GET("/:id", auth.NotGuest, getStaffingRequest)
+		r.PATCH("/:id", auth.NotGuest, auth.InGroup("events"), patchStaffingRequest)
+		r.POST("/:id/convert", auth.NotGuest, auth.InGroup("events"), postConvertStaffingRequest)
 	}
 }
@@ -0,0 +1,325 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package staffing
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/adh-partnership/api/pkg/auth"
+	"github.com/adh-partnership/api/pkg/config"
+	"github.com/adh-partnership/api/pkg/database"
+	"github.com/adh-partnership/api/pkg/database/dto"
+	"github.com/adh-partnership/api/pkg/database/models"
+	"github.com/adh-partnership/api/pkg/database/models/constants"
+	"github.com/adh-partnership/api/pkg/events"
+	"github.com/adh-partnership/api/pkg/gin/response"
+)
+
+// Get Staffing Requests
+// @Summary Get Staffing Requests [Feature Gated]
+// @Description Get staffing requests, newest first [Feature Gated]
+// @Tags Staffing
+// @Param status query string false "Only requests with this status"
+// @Success 200 {object} []dto.StaffingRequestResponse
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/staffing/ [get]
+func getStaffingRequests(c *gin.Context) {
+	requests, err := database.FindStaffingRequests(c.Query("status"))
+	if err != nil {
+		log.Errorf("Error getting staffing requests: %s", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	response.Respond(c, http.StatusOK, dto.ConvStaffingRequestsToResponse(requests))
+}
+
+// Get My Staffing Requests
+// @Summary Get My Staffing Requests [Feature Gated]
+// @Description Get the staffing requests the user submitted and their status, without staff notes [Feature Gated]
+// @Tags Staffing
+// @Success 200 {object} []dto.StaffingRequestResponse
+// @Failure 401 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/staffing/mine [get]
+func getMyStaffingRequests(c *gin.Context) {
+	user := c.MustGet("x-user").(*models.User)
+
+	requests, err := database.FindUserStaffingRequests(user.CID)
+	if err != nil {
+		log.Errorf("Error getting staffing requests of %d: %s", user.CID, err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	for _, request := range requests {
+		request.StaffNotes = ""
+	}
+
+	response.Respond(c, http.StatusOK, dto.ConvStaffingRequestsToResponse(requests))
+}
+
+// Get Staffing Request
+// @Summary Get Staffing Request [Feature Gated]
+// @Description Get a staffing request. Requesters can see their own requests, without staff notes. [Feature Gated]
+// @Tags Staffing
+// @Param id path string true "Request ID"
+// @Success 200 {object} dto.StaffingRequestResponse
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 404 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/staffing/{id} [get]
+func getStaffingRequest(c *gin.Context) {
+	user := c.MustGet("x-user").(*models.User)
+
+	request, ok := findRequest(c)
+	if !ok {
+		return
+	}
+
+	if !auth.InGroup(user, "events") {
+		if request.UserID != user.CID {
+			response.RespondError(c, http.StatusForbidden, "Forbidden")
+			return
+		}
+		request.StaffNotes = ""
+	}
+
+	response.Respond(c, http.StatusOK, dto.ConvStaffingRequestToResponse(request))
+}
+
+// Update Staffing Request
+// @Summary Update Staffing Request [Feature Gated]
+// @Description Accept or decline a staffing request, or update its staff notes. Converted requests cannot change
+// @Description status. [Feature Gated]
+// @Tags Staffing
+// @Param id path string true "Request ID"
+// @Param data body dto.StaffingRequestUpdate true "Changes"
+// @Success 200 {object} dto.StaffingRequestResponse
+// @Failure 400 {object} response.R
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 404 {object} response.R
+// @Failure 409 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/staffing/{id} [patch]
+func patchStaffingRequest(c *gin.Context) {
+	user := c.MustGet("x-user").(*models.User)
+
+	data := &dto.StaffingRequestUpdate{}
+	if err := c.ShouldBind(&data); err != nil {
+		response.RespondError(c, http.StatusBadRequest, "Bad Request")
+		return
+	}
+
+	request, ok := findRequest(c)
+	if !ok {
+		return
+	}
+
+	if data.Status != nil && *data.Status != request.Status {
+		switch *data.Status {
+		case constants.StaffingRequestStatusNew, constants.StaffingRequestStatusAccepted, constants.StaffingRequestStatusDeclined:
+		default:
+			response.RespondError(c, http.StatusBadRequest, "Invalid status")
+			return
+		}
+		if request.Status == constants.StaffingRequestStatusConverted {
+			response.RespondError(c, http.StatusConflict, "Request has already been converted into an event")
+			return
+		}
+		request.Status = *data.Status
+		request.HandledByID = &user.CID
+		request.HandledBy = user
+	}
+	if data.StaffNotes != nil {
+		request.StaffNotes = *data.StaffNotes
+	}
+
+	if err := database.DB.Model(&models.StaffingRequest{}).Where("id = ?", request.ID).Updates(map[string]interface{}{
+		"status":        request.Status,
+		"staff_notes":   request.StaffNotes,
+		"handled_by_id": request.HandledByID,
+	}).Error; err != nil {
+		log.Errorf("Error updating staffing request %d: %s", request.ID, err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	response.Respond(c, http.StatusOK, dto.ConvStaffingRequestToResponse(request))
+}
+
+// Convert Staffing Request
+// @Summary Convert Staffing Request [Feature Gated]
+// @Description Create an event from an accepted staffing request, with positions at the departure and arrival
+// @Description airports within the facility [Feature Gated]
+// @Tags Staffing
+// @Param id path string true "Request ID"
+// @Param data body dto.StaffingConversionRequest true "Event details"
+// @Success 201 {object} dto.EventsResponse
+// @Failure 400 {object} response.R
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 404 {object} response.R
+// @Failure 409 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/staffing/{id}/convert [post]
+func postConvertStaffingRequest(c *gin.Context) {
+	user := c.MustGet("x-user").(*models.User)
+
+	data := &dto.StaffingConversionRequest{}
+	if err := c.ShouldBind(&data); err != nil {
+		response.RespondError(c, http.StatusBadRequest, "Bad Request")
+		return
+	}
+
+	request, ok := findRequest(c)
+	if !ok {
+		return
+	}
+
+	if request.Status != constants.StaffingRequestStatusAccepted {
+		response.RespondError(c, http.StatusConflict, "Only accepted requests can be converted into an event")
+		return
+	}
+
+	start, end := data.StartDate, data.EndDate
+	if start == nil {
+		start = parseDate(request.StartDate)
+	}
+	if end == nil {
+		end = parseDate(request.EndDate)
+	}
+	if start == nil || end == nil || !end.After(*start) {
+		response.RespondError(c, http.StatusBadRequest, "Valid start and end dates are required")
+		return
+	}
+
+	event := &models.Event{
+		Title:       data.Title,
+		Description: data.Description,
+		Banner:      request.BannerURL,
+		StartDate:   start.UTC(),
+		EndDate:     end.UTC(),
+	}
+	if event.Title == "" {
+		event.Title = fmt.Sprintf("Group Flight: %s to %s", request.DepartureAirport, request.ArrivalAirport)
+		if request.Organization != "" {
+			event.Title = fmt.Sprintf("%s %s", request.Organization, event.Title)
+		}
+	}
+	if event.Description == "" {
+		event.Description = strings.TrimSpace(fmt.Sprintf("%s\n\nExpected pilots: %d", request.Comments, request.Pilots))
+	}
+
+	seen := map[string]bool{}
+	for _, id := range []string{request.DepartureAirport, request.ArrivalAirport} {
+		names, err := airportPositions(id)
+		if err != nil {
+			log.Errorf("Error getting positions of airport %s: %s", id, err)
+			response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+		for _, name := range names {
+			if !seen[name] {
+				seen[name] = true
+				event.Positions = append(event.Positions, events.NewPosition(name, 0))
+			}
+		}
+	}
+
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(event).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.StaffingRequest{}).Where("id = ?", request.ID).Updates(map[string]interface{}{
+			"status":        constants.StaffingRequestStatusConverted,
+			"event_id":      event.ID,
+			"handled_by_id": user.CID,
+		}).Error
+	}); err != nil {
+		log.Errorf("Error converting staffing request %d: %s", request.ID, err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	if events.NotificationsEnabled() {
+		// Announced on a copy, the event is reloaded for the response while the announcement is sent
+		announced := *event
+		go events.AnnounceNew(&announced)
+	}
+
+	event, err := database.GetEvent(fmt.Sprint(event.ID))
+	if err != nil {
+		log.Errorf("Error getting event: %s", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	response.Respond(c, http.StatusCreated, dto.ConvEventToEventsResponse(event))
+}
+
+func findRequest(c *gin.Context) (*models.StaffingRequest, bool) {
+	request, err := database.FindStaffingRequest(c.Param("id"))
+	if err != nil {
+		log.Errorf("Error getting staffing request: %s", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return nil, false
+	}
+	if request == nil {
+		response.RespondError(c, http.StatusNotFound, "Not Found")
+		return nil, false
+	}
+
+	return request, true
+}
+
+// airportPositions returns the positions to staff at the airport, none if it is unknown or outside the facility
+func airportPositions(id string) ([]string, error) {
+	airport, err := database.FindAirportByID(strings.ToUpper(id))
+	if err != nil {
+		return nil, err
+	}
+	if airport == nil || (airport.ARTCC != "" && !strings.EqualFold(airport.ARTCC, config.Cfg.VATUSA.Facility)) {
+		return nil, nil
+	}
+
+	towerType := airport.TwrTypeCode
+	if airport.ATC != nil && airport.ATC.FacilityType != "" {
+		towerType = airport.ATC.FacilityType
+	}
+
+	return events.AirportPositions(airport.ID, towerType), nil
+}
+
+func parseDate(s string) *time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return nil
+	}
+
+	return &t
+}
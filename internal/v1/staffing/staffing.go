@@ -20,21 +20,24 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/adh-partnership/api/pkg/database"
 	"github.com/adh-partnership/api/pkg/database/dto"
 	"github.com/adh-partnership/api/pkg/database/models"
+	"github.com/adh-partnership/api/pkg/database/models/constants"
 	"github.com/adh-partnership/api/pkg/discord"
 	"github.com/adh-partnership/api/pkg/gin/response"
 )
 
 // Submit a staffing request. [Feature Gated]
 // @Summary Submit a staffing request [Feature Gated]
-// @Description Submit a staffing request [Feature Gated]
+// @Description Submit a staffing request. It is stored with the status new for staff to handle. [Feature Gated]
 // @Tags Staffing
 // @Param data body dto.StaffingRequest true "Request Data"
-// @Success 204
+// @Success 201 {object} dto.StaffingRequestResponse
 // @Failure 400 {object} response.R "Invalid form submission"
 // @Failure 401 {object} response.R "Not logged in"
 // @Failure 404 {object} response.R "Not Found -- feature disabled"
@@ -43,30 +46,51 @@ import (
 func requestStaffing(c *gin.Context) {
 	user := c.MustGet("x-user").(*models.User)
 
-	var dto dto.StaffingRequest
-	if err := c.ShouldBind(&dto); err != nil {
+	var data dto.StaffingRequest
+	if err := c.ShouldBind(&data); err != nil {
 		log.Debugf("Error binding dto: %s", err)
 		response.RespondError(c, http.StatusBadRequest, "Invalid request")
 		return
 	}
 
+	request := &models.StaffingRequest{
+		UserID:           user.CID,
+		DepartureAirport: strings.ToUpper(data.DepartureAirport),
+		ArrivalAirport:   strings.ToUpper(data.ArrivalAirport),
+		StartDate:        data.StartDate,
+		EndDate:          data.EndDate,
+		Pilots:           data.Pilots,
+		ContactInfo:      data.ContactInfo,
+		Organization:     data.Organization,
+		BannerURL:        data.BannerURL,
+		Comments:         data.Comments,
+		Status:           constants.StaffingRequestStatusNew,
+	}
+	if err := database.DB.Create(request).Error; err != nil {
+		log.Errorf("Error storing staffing request: %s", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
 	err := discord.NewMessage().
 		SetContent("New staffing request").
 		AddEmbed(discord.NewEmbed().
+			AddField(discord.NewField().SetName("Request ID").SetValue(fmt.Sprint(request.ID))).
 			AddField(discord.NewField().SetName("Requester").SetValue(fmt.Sprintf("%s %s (%d)", user.FirstName, user.LastName, user.CID))).
-			AddField(discord.NewField().SetName("Start Date").SetValue(dto.StartDate)).
-			AddField(discord.NewField().SetName("End Date").SetValue(dto.EndDate)).
-			AddField(discord.NewField().SetName("DepartureAirport").SetValue(dto.DepartureAirport)).
-			AddField(discord.NewField().SetName("ArrivalAirport").SetValue(dto.ArrivalAirport)).
-			AddField(discord.NewField().SetName("Pilots").SetValue(strconv.Itoa(dto.Pilots))).
-			AddField(discord.NewField().SetName("Contact").SetValue(dto.ContactInfo)).
-			AddField(discord.NewField().SetName("Organization").SetValue(dto.Organization)).
-			AddField(discord.NewField().SetName("Banner").SetValue(dto.BannerURL)).
-			AddField(discord.NewField().SetName("Comments").SetValue(dto.Comments)),
+			AddField(discord.NewField().SetName("Start Date").SetValue(data.StartDate)).
+			AddField(discord.NewField().SetName("End Date").SetValue(data.EndDate)).
+			AddField(discord.NewField().SetName("DepartureAirport").SetValue(data.DepartureAirport)).
+			AddField(discord.NewField().SetName("ArrivalAirport").SetValue(data.ArrivalAirport)).
+			AddField(discord.NewField().SetName("Pilots").SetValue(strconv.Itoa(data.Pilots))).
+			AddField(discord.NewField().SetName("Contact").SetValue(data.ContactInfo)).
+			AddField(discord.NewField().SetName("Organization").SetValue(data.Organization)).
+			AddField(discord.NewField().SetName("Banner").SetValue(data.BannerURL)).
+			AddField(discord.NewField().SetName("Comments").SetValue(data.Comments)),
 		).Send("staffing_request")
 	if err != nil {
 		log.Errorf("Error sending staffing request message to Discord: %s", err.Error())
 	}
 
-	response.RespondBlank(c, http.StatusNoContent)
+	request.User = user
+	response.Respond(c, http.StatusCreated, dto.ConvStaffingRequestToResponse(request))
 }
@@ -0,0 +1,329 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package training
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/adh-partnership/api/pkg/auth"
+	"github.com/adh-partnership/api/pkg/config"
+	"github.com/adh-partnership/api/pkg/database"
+	"github.com/adh-partnership/api/pkg/database/dto"
+	"github.com/adh-partnership/api/pkg/database/models"
+	"github.com/adh-partnership/api/pkg/database/models/constants"
+	"github.com/adh-partnership/api/pkg/gin/response"
+	"github.com/adh-partnership/api/pkg/training"
+)
+
+// Get Training Availability
+// @Summary Get Training Availability
+// @Description Get the published availability of instructors and mentors
+// @Tags training
+// @Param cid query string false "Instructor CID filter"
+// @Success 200 {object} []dto.TrainingAvailability
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/training/availability [GET]
+func getTrainingAvailability(c *gin.Context) {
+	availability, err := database.FindTrainingAvailabilities(c.Query("cid"))
+	if err != nil {
+		log.Errorf("Error getting training availability: %s", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	response.Respond(c, http.StatusOK, dto.ConvertTrainingAvailabilitiesToDTO(availability))
+}
+
+// Create Training Availability
+// @Summary Create Training Availability
+// @Description Publish an availability window for the logged in user. Recurring windows require weekday (0 = Sunday),
+// @Description start_time and end_time (HH:MM, UTC). One-off windows require start and end.
+// @Tags training
+// @Param availability body dto.TrainingAvailabilityRequest true "Availability"
+// @Success 201 {object} dto.TrainingAvailability
+// @Failure 400 {object} response.R
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/training/availability [POST]
+func postTrainingAvailability(c *gin.Context) {
+	var data dto.TrainingAvailabilityRequest
+	if err := c.ShouldBind(&data); err != nil {
+		response.RespondError(c, http.StatusBadRequest, "Bad Request")
+		return
+	}
+
+	user := c.MustGet("x-user").(*models.User)
+
+	if !models.IsValidTrainingAvailabilityType(data.Type) {
+		response.RespondError(c, http.StatusBadRequest, "Invalid type")
+		return
+	}
+
+	availability := &models.TrainingAvailability{
+		InstructorID: user.CID,
+		Instructor:   user,
+		Type:         data.Type,
+		Notes:        data.Notes,
+	}
+
+	if data.Type == constants.TrainingAvailabilityTypeRecurring {
+		if data.Weekday < 0 || data.Weekday > 6 {
+			response.RespondError(c, http.StatusBadRequest, "Invalid weekday")
+			return
+		}
+		start, err := training.ParseClock(data.StartTime)
+		if err != nil {
+			response.RespondError(c, http.StatusBadRequest, "Invalid start_time")
+			return
+		}
+		end, err := training.ParseClock(data.EndTime)
+		if err != nil || end == start {
+			response.RespondError(c, http.StatusBadRequest, "Invalid end_time")
+			return
+		}
+		availability.Weekday = data.Weekday
+		availability.StartTime = data.StartTime
+		availability.EndTime = data.EndTime
+	} else {
+		if data.Start == nil || data.End == nil || !data.End.After(*data.Start) || data.End.Before(time.Now()) {
+			response.RespondError(c, http.StatusBadRequest, "Invalid start or end")
+			return
+		}
+		availability.Start = data.Start
+		availability.End = data.End
+	}
+
+	if err := database.DB.Omit("Instructor").Create(availability).Error; err != nil {
+		log.Errorf("Error creating training availability: %+v (%s)", availability, err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	response.Respond(c, http.StatusCreated, dto.ConvertTrainingAvailabilityToDTO(availability))
+}
+
+// Delete Training Availability
+// @Summary Delete Training Availability
+// @Description Delete an availability window. Only the owner or the training administrator may delete it.
+// @Tags training
+// @Param id path string true "Availability ID"
+// @Success 204
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 404 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/training/availability/{id} [DELETE]
+func deleteTrainingAvailability(c *gin.Context) {
+	user := c.MustGet("x-user").(*models.User)
+
+	availability, err := database.FindTrainingAvailabilityByID(c.Param("id"))
+	if err != nil {
+		log.Errorf("Error getting training availability %s: %s", c.Param("id"), err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	if availability == nil {
+		response.RespondError(c, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	if availability.InstructorID != user.CID && !auth.HasRole(user, "ta") && !auth.InGroup(user, "admin") {
+		response.RespondError(c, http.StatusForbidden, "Forbidden")
+		return
+	}
+
+	if err := database.DB.Delete(availability).Error; err != nil {
+		log.Errorf("Error deleting training availability: %s", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	response.RespondBlank(c, http.StatusNoContent)
+}
+
+// Get Training Request Matches
+// @Summary Get Training Request Matches
+// @Description Get suggested instructors for an open training request, based on the overlap between the request's slots and
+// @Description published availability. Suggestions are ranked by the instructor's certification for the position and their
+// @Description number of upcoming sessions.
+// @Tags training
+// @Param id path string true "Training Request ID"
+// @Success 200 {object} []dto.TrainingMatch
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 404 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/training/requests/{id}/matches [GET]
+func getTrainingRequestMatches(c *gin.Context) {
+	request, err := database.FindTrainingSessionRequestByID(c.Param("id"))
+	if err != nil {
+		log.Errorf("Error getting training request %s: %s", c.Param("id"), err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	if request == nil {
+		response.RespondError(c, http.StatusNotFound, "Training Request Not Found")
+		return
+	}
+
+	candidates, err := buildCandidates()
+	if err != nil {
+		log.Errorf("Error building training candidates: %s", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	response.Respond(c, http.StatusOK, convertMatches(findMatches(request, candidates)))
+}
+
+// Get Training Demand
+// @Summary Get Training Demand
+// @Description Get open training requests by position, including those no published availability can cover
+// @Tags training
+// @Success 200 {object} []dto.TrainingDemand
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/training/demand [GET]
+func getTrainingDemand(c *gin.Context) {
+	requests, err := database.FindTrainingSessionRequestWithFilter(&database.TrainingSessionRequestFilter{
+		Status: constants.TrainingSessionStatusOpen,
+	})
+	if err != nil {
+		log.Errorf("Error getting training requests: %s", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	candidates, err := buildCandidates()
+	if err != nil {
+		log.Errorf("Error building training candidates: %s", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	demand := map[string]*dto.TrainingDemand{}
+	for _, request := range requests {
+		d, ok := demand[request.Position]
+		if !ok {
+			d = &dto.TrainingDemand{
+				Position: request.Position,
+				Requests: []*dto.TrainingRequest{},
+			}
+			demand[request.Position] = d
+		}
+
+		d.Open++
+		for _, slot := range request.Slots {
+			if slot.Start != nil && slot.End != nil {
+				d.RequestedHours += slot.End.Sub(*slot.Start).Hours()
+			}
+		}
+
+		if len(findMatches(request, candidates)) == 0 {
+			d.Unmatched++
+			d.Requests = append(d.Requests, dto.ConvertTrainingRequestToDTO(request))
+		}
+	}
+
+	ret := []*dto.TrainingDemand{}
+	for _, d := range demand {
+		ret = append(ret, d)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Position < ret[j].Position
+	})
+
+	response.Respond(c, http.StatusOK, ret)
+}
+
+func findMatches(request *models.TrainingRequest, candidates []*training.Candidate) []*training.Match {
+	cfg := config.Cfg.Facility.TrainingRequests
+	return training.FindMatches(
+		request,
+		candidates,
+		cfg.Certifications[request.Position],
+		time.Duration(cfg.MinSessionLength)*time.Minute,
+		time.Now(),
+	)
+}
+
+// buildCandidates groups the published availability by instructor along with their certifications and workload
+func buildCandidates() ([]*training.Candidate, error) {
+	availability, err := database.FindTrainingAvailabilities("")
+	if err != nil {
+		return nil, err
+	}
+
+	workload, err := database.CountScheduledTrainingSessions()
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := []*training.Candidate{}
+	byInstructor := map[uint]*training.Candidate{}
+	for _, a := range availability {
+		if a.Instructor == nil {
+			continue
+		}
+
+		candidate, ok := byInstructor[a.InstructorID]
+		if !ok {
+			certs, err := database.FindUserCertifications(a.Instructor)
+			if err != nil {
+				return nil, err
+			}
+			candidate = &training.Candidate{
+				Instructor:     a.Instructor,
+				Certifications: map[string]string{},
+				Workload:       workload[a.InstructorID],
+			}
+			for _, cert := range certs {
+				candidate.Certifications[cert.Name] = cert.Value
+			}
+			byInstructor[a.InstructorID] = candidate
+			candidates = append(candidates, candidate)
+		}
+
+		candidate.Availability = append(candidate.Availability, a)
+	}
+
+	return candidates, nil
+}
+
+func convertMatches(matches []*training.Match) []*dto.TrainingMatch {
+	res := []*dto.TrainingMatch{}
+	for _, m := range matches {
+		res = append(res, &dto.TrainingMatch{
+			Instructor:    dto.ConvUserToUserResponse(m.Instructor),
+			Certification: m.Certification,
+			Workload:      m.Workload,
+			Start:         m.Start,
+			End:           m.End,
+		})
+	}
+	return res
+}
@@ -19,6 +19,7 @@ package training
 import (
 	"github.com/gin-gonic/gin"
 
+	"github.com/adh-partnership/api/pkg/config"
 	"github.com/adh-partnership/api/pkg/gin/middleware/auth"
 	"github.com/adh-partnership/api/pkg/logger"
 )
@@ -26,7 +27,54 @@ import (
 var log = logger.Logger.WithField("component", "training")
 
 func Routes(r *gin.RouterGroup) {
+	if config.Cfg.Facility.TrainingRequests.Enabled {
+		r.GET("/requests", auth.NotGuest, getTrainingRequests)
+		r.POST("/requests", auth.NotGuest, postTrainingRequest)
+		r.GET("/requests/:id", auth.NotGuest, getTrainingRequest)
+		r.PATCH("/requests/:id", auth.NotGuest, patchTrainingRequest)
+		r.GET("/requests/:id/matches", auth.NotGuest, auth.InGroup("training"), getTrainingRequestMatches)
+		r.GET("/demand", auth.NotGuest, auth.InGroup("training"), getTrainingDemand)
+
+		r.GET("/availability", auth.NotGuest, auth.InGroup("training"), getTrainingAvailability)
+		r.POST("/availability", auth.NotGuest, auth.InGroup("training"), postTrainingAvailability)
+		r.DELETE("/availability/:id", auth.NotGuest, auth.InGroup("training"), deleteTrainingAvailability)
+	}
+
+	r.GET("/syllabus", auth.NotGuest, getSyllabus)
+	r.POST("/syllabus", auth.NotGuest, auth.HasRole("ta"), postSyllabusItem)
+	r.PUT("/syllabus/:id", auth.NotGuest, auth.HasRole("ta"), putSyllabusItem)
+	r.DELETE("/syllabus/:id", auth.NotGuest, auth.HasRole("ta"), deleteSyllabusItem)
+	r.GET("/stalled", auth.NotGuest, auth.InGroup("training"), getStalledStudents)
+	r.GET("/solo", getSoloEndorsements)
+	r.GET("/solo/history", auth.NotGuest, auth.InGroup("training"), getSoloEndorsementHistory)
+	r.POST("/solo", auth.NotGuest, auth.HasRole("ta", "ins"), postSoloEndorsement)
+	r.DELETE("/solo/:id", auth.NotGuest, auth.HasRole("ta", "ins"), deleteSoloEndorsement)
+	r.GET("/reports/instructors", auth.NotGuest, auth.HasRole("ta"), getInstructorWorkloadReport)
+	r.GET("/reports/students", auth.NotGuest, auth.HasRole("ta"), getStudentThroughputReport)
+	r.GET("/reports/no-shows", auth.NotGuest, auth.HasRole("ta"), getNoShowReport)
+	r.GET("/reports/certifications", auth.NotGuest, auth.HasRole("ta"), getTimeToCertificationReport)
+	r.GET("/reports/backlog", auth.NotGuest, auth.HasRole("ta"), getBacklogReport)
+	r.GET("/ots/rubrics", auth.NotGuest, auth.InGroup("training"), getOTSRubrics)
+	r.POST("/ots/rubrics", auth.NotGuest, auth.HasRole("ta"), postOTSRubric)
+	r.PUT("/ots/rubrics/:id", auth.NotGuest, auth.HasRole("ta"), putOTSRubric)
+	r.DELETE("/ots/rubrics/:id", auth.NotGuest, auth.HasRole("ta"), deleteOTSRubric)
+	r.POST("/ots/evaluations", auth.NotGuest, auth.InGroup("training"), postOTSEvaluation)
+	r.GET("/ots/evaluations/:id", auth.NotGuest, getOTSEvaluation)
+	r.GET("/promotions", auth.NotGuest, auth.InGroup("training"), getPromotionRequests)
+	r.POST("/promotions", auth.NotGuest, auth.InGroup("training"), postPromotionRequest)
+	r.GET("/promotions/:id", auth.NotGuest, getPromotionRequest)
+	r.PATCH("/promotions/:id", auth.NotGuest, auth.HasRole("ta"), patchPromotionRequest)
+	r.GET("/mentors/students", auth.NotGuest, auth.InGroup("training"), getMentorStudents)
+	r.GET("/mentors/caseload", auth.NotGuest, auth.HasRole("ta"), getMentorCaseload)
+	r.POST("/mentors/assignments", auth.NotGuest, auth.HasRole("ta"), postMentorAssignment)
+	r.DELETE("/mentors/assignments/:id", auth.NotGuest, auth.HasRole("ta"), deleteMentorAssignment)
+	r.GET("/sync", auth.NotGuest, auth.HasRole("ta"), getTrainingNoteSync)
+	r.POST("/sync/:id/retry", auth.NotGuest, auth.HasRole("ta"), postTrainingNoteSyncRetry)
+
 	r.GET("/:cid", auth.NotGuest, getTraining)
+	r.GET("/:cid/progress", auth.NotGuest, getTrainingProgress)
+	r.GET("/:cid/ots", auth.NotGuest, getOTSEvaluations)
+	r.GET("/:cid/mentors", auth.NotGuest, getMentorHistory)
 	r.POST("/:cid", auth.NotGuest, auth.InGroup("training"), postTraining)
 	r.PUT("/:cid/:id", auth.NotGuest, auth.InGroup("training"), putTraining)
 	r.DELETE("/:cid/:id", auth.NotGuest, auth.InGroup("training"), deleteTraining)
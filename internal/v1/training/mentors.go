@@ -0,0 +1,335 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package training
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm/clause"
+
+	"github.com/adh-partnership/api/pkg/auth"
+	"github.com/adh-partnership/api/pkg/config"
+	"github.com/adh-partnership/api/pkg/database"
+	"github.com/adh-partnership/api/pkg/database/dto"
+	"github.com/adh-partnership/api/pkg/database/models"
+	"github.com/adh-partnership/api/pkg/database/models/constants"
+	"github.com/adh-partnership/api/pkg/gin/response"
+	"github.com/adh-partnership/api/pkg/training"
+)
+
+// Get My Students
+// @Summary Get My Students
+// @Description Get the students currently assigned to the logged in mentor, with their latest training note,
+// @Description the certification they are working towards and their open training requests
+// @Tags training
+// @Success 200 {object} []dto.MentorStudent
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/training/mentors/students [GET]
+func getMentorStudents(c *gin.Context) {
+	user := c.MustGet("x-user").(*models.User)
+
+	assignments, err := database.FindActiveMentorAssignments(user.CID)
+	if err != nil {
+		log.Errorf("Error getting mentor assignments for %d: %s", user.CID, err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	var cids []uint
+	for _, a := range assignments {
+		cids = append(cids, a.StudentID)
+	}
+
+	notes, err := database.FindTrainingNotesForStudents(cids)
+	if err != nil {
+		log.Errorf("Error getting training notes for students of %d: %s", user.CID, err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	progress, err := database.FindTrainingNoteProgressForStudents(cids)
+	if err != nil {
+		log.Errorf("Error getting training progress for students of %d: %s", user.CID, err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	requests, err := database.FindActiveTrainingSessionRequestsForStudents(cids)
+	if err != nil {
+		log.Errorf("Error getting training requests for students of %d: %s", user.CID, err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	items, err := database.FindSyllabusItems("")
+	if err != nil {
+		log.Errorf("Error getting syllabus items: %s", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	ret := []*dto.MentorStudent{}
+	for _, a := range assignments {
+		s := &dto.MentorStudent{
+			AssignmentID: a.ID,
+			Student:      dto.ConvUserToUserResponse(a.Student),
+			AssignedAt:   a.StartsAt,
+			Notes:        a.Notes,
+			LatestNote:   training.LatestTrainingNote(notes[a.StudentID]),
+			OpenRequests: []*dto.TrainingRequest{},
+		}
+
+		values := map[string]string{}
+		for name, cert := range s.Student.Certifications {
+			values[name] = cert.Value
+		}
+		built := training.BuildProgress(database.GetCertifications(), values, items, progress[a.StudentID])
+		s.CurrentCertification = convertProgress(training.NextCertification(built))
+
+		for _, r := range requests[a.StudentID] {
+			s.OpenRequests = append(s.OpenRequests, dto.ConvertTrainingRequestToDTO(r))
+		}
+
+		ret = append(ret, s)
+	}
+
+	response.Respond(c, http.StatusOK, ret)
+}
+
+// Get Mentor Caseload
+// @Summary Get Mentor Caseload
+// @Description Get every training staff member's active students, flagging overloaded mentors, along with the
+// @Description students in training that have no mentor assigned
+// @Tags training
+// @Success 200 {object} dto.Caseload
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/training/mentors/caseload [GET]
+func getMentorCaseload(c *gin.Context) {
+	staff, err := database.FindUsersWithRoles(auth.Groups["training"])
+	if err != nil {
+		log.Errorf("Error getting training staff: %s", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	assignments, err := database.FindActiveMentorAssignments(0)
+	if err != nil {
+		log.Errorf("Error getting mentor assignments: %s", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	students, err := database.FindStudentsInTraining()
+	if err != nil {
+		log.Errorf("Error getting students in training: %s", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	maxStudents := config.Cfg.Facility.TrainingRequests.MaxStudentsPerMentor
+	response.Respond(c, http.StatusOK, convertCaseload(
+		training.BuildCaseload(staff, assignments, maxStudents),
+		training.UnassignedStudents(students, assignments),
+		maxStudents,
+	))
+}
+
+// Assign Mentor
+// @Summary Assign Mentor
+// @Description Assign a mentor to a student. If the student already has a mentor, that assignment is ended as reassigned.
+// @Tags training
+// @Param assignment body dto.MentorAssignmentRequest true "Mentor Assignment"
+// @Success 201 {object} models.MentorAssignment
+// @Failure 400 {object} response.R
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 404 {object} response.R
+// @Failure 409 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/training/mentors/assignments [POST]
+func postMentorAssignment(c *gin.Context) {
+	var data dto.MentorAssignmentRequest
+	if err := c.ShouldBind(&data); err != nil || data.CID == 0 || data.MentorCID == 0 {
+		response.RespondError(c, http.StatusBadRequest, "Bad Request")
+		return
+	}
+
+	user := c.MustGet("x-user").(*models.User)
+
+	if data.CID == data.MentorCID {
+		response.RespondError(c, http.StatusBadRequest, "A student cannot mentor themselves")
+		return
+	}
+
+	student, err := database.FindUserByCID(fmt.Sprint(data.CID))
+	if err != nil {
+		log.Errorf("Error finding student %d: %s", data.CID, err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+	if student == nil {
+		response.RespondError(c, http.StatusNotFound, "Student Not Found")
+		return
+	}
+
+	mentor, err := database.FindUserByCID(fmt.Sprint(data.MentorCID))
+	if err != nil {
+		log.Errorf("Error finding mentor %d: %s", data.MentorCID, err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+	if mentor == nil {
+		response.RespondError(c, http.StatusNotFound, "Mentor Not Found")
+		return
+	}
+	if !auth.HasRoleList(mentor, auth.Groups["training"]) {
+		response.RespondError(c, http.StatusBadRequest, "Mentor is not a member of the training staff")
+		return
+	}
+
+	current, err := database.FindActiveMentorAssignments(mentor.CID)
+	if err != nil {
+		log.Errorf("Error getting mentor assignments for %d: %s", mentor.CID, err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+	for _, a := range current {
+		if a.StudentID == student.CID {
+			response.RespondError(c, http.StatusConflict, "Student is already assigned to this mentor")
+			return
+		}
+	}
+
+	assignment := &models.MentorAssignment{
+		StudentID:    student.CID,
+		Student:      student,
+		MentorID:     mentor.CID,
+		Mentor:       mentor,
+		AssignedByID: user.CID,
+		AssignedBy:   user,
+		Notes:        data.Notes,
+		StartsAt:     time.Now(),
+	}
+
+	if _, err := database.AssignMentor(assignment); err != nil {
+		log.Errorf("Error creating mentor assignment: %+v (%s)", assignment, err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	response.Respond(c, http.StatusCreated, assignment)
+}
+
+// End Mentor Assignment
+// @Summary End Mentor Assignment
+// @Description End a mentor assignment, leaving the student without a mentor
+// @Tags training
+// @Param id path string true "Mentor Assignment ID"
+// @Success 200 {object} models.MentorAssignment
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 404 {object} response.R
+// @Failure 409 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/training/mentors/assignments/{id} [DELETE]
+func deleteMentorAssignment(c *gin.Context) {
+	assignment, err := database.FindMentorAssignmentByID(c.Param("id"))
+	if err != nil {
+		log.Errorf("Error getting mentor assignment %s: %s", c.Param("id"), err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+	if assignment == nil {
+		response.RespondError(c, http.StatusNotFound, "Not Found")
+		return
+	}
+	if assignment.EndedAt != nil {
+		response.RespondError(c, http.StatusConflict, "Mentor assignment has already ended")
+		return
+	}
+
+	now := time.Now()
+	assignment.EndedAt = &now
+	assignment.EndReason = constants.MentorEndedRemoved
+	if err := database.DB.Omit(clause.Associations).Save(assignment).Error; err != nil {
+		log.Errorf("Error ending mentor assignment: %+v (%s)", assignment, err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	response.Respond(c, http.StatusOK, assignment)
+}
+
+// Get Mentor History
+// @Summary Get Mentor History
+// @Description Get a student's mentor assignments, newest first
+// @Tags training
+// @Param cid path string true "CID"
+// @Success 200 {object} []models.MentorAssignment
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/training/{cid}/mentors [GET]
+func getMentorHistory(c *gin.Context) {
+	user := c.MustGet("x-user").(*models.User)
+
+	if !auth.InGroup(user, "training") && fmt.Sprint(user.CID) != c.Param("cid") {
+		response.RespondError(c, http.StatusForbidden, "Forbidden")
+		return
+	}
+
+	assignments, err := database.FindMentorAssignments(database.Atou(c.Param("cid")))
+	if err != nil {
+		log.Errorf("Error getting mentor assignments for %s: %s", c.Param("cid"), err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	response.Respond(c, http.StatusOK, assignments)
+}
+
+func convertCaseload(caseload []*training.MentorCaseload, unassigned []*models.User, maxStudents int) *dto.Caseload {
+	ret := &dto.Caseload{
+		MaxStudentsPerMentor: maxStudents,
+		Mentors:              []*dto.MentorCaseload{},
+		Unassigned:           []*dto.UserResponse{},
+	}
+	for _, c := range caseload {
+		m := &dto.MentorCaseload{
+			Mentor:     dto.ConvUserToUserResponse(c.Mentor),
+			Students:   []*dto.UserResponse{},
+			Count:      c.Count,
+			Overloaded: c.Overloaded,
+		}
+		for _, s := range c.Students {
+			m.Students = append(m.Students, dto.ConvUserToUserResponse(s))
+		}
+		ret.Mentors = append(ret.Mentors, m)
+	}
+	for _, s := range unassigned {
+		ret.Unassigned = append(ret.Unassigned, dto.ConvUserToUserResponse(s))
+	}
+
+	return ret
+}
@@ -0,0 +1,470 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package training
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/adh-partnership/api/pkg/auth"
+	"github.com/adh-partnership/api/pkg/database"
+	"github.com/adh-partnership/api/pkg/database/dto"
+	"github.com/adh-partnership/api/pkg/database/models"
+	"github.com/adh-partnership/api/pkg/database/models/constants"
+	"github.com/adh-partnership/api/pkg/gin/response"
+	"github.com/adh-partnership/api/pkg/training"
+)
+
+// Get OTS Rubrics
+// @Summary Get OTS Rubrics
+// @Tags training
+// @Param position query string false "Position filter"
+// @Success 200 {object} []models.OTSRubric
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/training/ots/rubrics [GET]
+func getOTSRubrics(c *gin.Context) {
+	rubrics, err := database.FindOTSRubrics(c.Query("position"))
+	if err != nil {
+		log.Errorf("Error getting OTS rubrics: %s", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	response.Respond(c, http.StatusOK, rubrics)
+}
+
+// Create OTS Rubric
+// @Summary Create OTS Rubric
+// @Description Create an OTS evaluation rubric for a position. If auto_certify is set, a pass upgrades the student
+// @Description to certified on the rubric's certification.
+// @Tags training
+// @Param rubric body dto.OTSRubricRequest true "Rubric"
+// @Success 201 {object} models.OTSRubric
+// @Failure 400 {object} response.R
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/training/ots/rubrics [POST]
+func postOTSRubric(c *gin.Context) {
+	var data dto.OTSRubricRequest
+	if err := c.ShouldBind(&data); err != nil {
+		response.RespondError(c, http.StatusBadRequest, "Bad Request")
+		return
+	}
+
+	if msg := validateOTSRubric(&data); msg != "" {
+		response.RespondError(c, http.StatusBadRequest, msg)
+		return
+	}
+
+	rubric := &models.OTSRubric{
+		Name:          data.Name,
+		Position:      data.Position,
+		Certification: data.Certification,
+		AutoCertify:   data.AutoCertify,
+	}
+	for _, category := range data.Categories {
+		rubric.Categories = append(rubric.Categories, &models.OTSRubricCategory{
+			Order:       category.Order,
+			Name:        category.Name,
+			Description: category.Description,
+			Critical:    category.Critical,
+		})
+	}
+
+	if err := database.DB.Create(rubric).Error; err != nil {
+		log.Errorf("Error creating OTS rubric: %+v (%s)", rubric, err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	response.Respond(c, http.StatusCreated, rubric)
+}
+
+// Update OTS Rubric
+// @Summary Update OTS Rubric
+// @Description Update an OTS rubric. Categories are matched by ID, categories without an ID are added and those left
+// @Description out are removed. Existing evaluations keep the category names they were scored with.
+// @Tags training
+// @Param id path string true "Rubric ID"
+// @Param rubric body dto.OTSRubricRequest true "Rubric"
+// @Success 200 {object} models.OTSRubric
+// @Failure 400 {object} response.R
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 404 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/training/ots/rubrics/{id} [PUT]
+func putOTSRubric(c *gin.Context) {
+	var data dto.OTSRubricRequest
+	if err := c.ShouldBind(&data); err != nil {
+		response.RespondError(c, http.StatusBadRequest, "Bad Request")
+		return
+	}
+
+	if msg := validateOTSRubric(&data); msg != "" {
+		response.RespondError(c, http.StatusBadRequest, msg)
+		return
+	}
+
+	rubric, err := database.FindOTSRubricByID(c.Param("id"))
+	if err != nil {
+		log.Errorf("Error getting OTS rubric %s: %s", c.Param("id"), err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+	if rubric == nil {
+		response.RespondError(c, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	existing := map[uint]*models.OTSRubricCategory{}
+	for _, category := range rubric.Categories {
+		existing[category.ID] = category
+	}
+
+	var categories []*models.OTSRubricCategory
+	for _, req := range data.Categories {
+		category := &models.OTSRubricCategory{RubricID: rubric.ID}
+		if req.ID != 0 {
+			var ok bool
+			category, ok = existing[req.ID]
+			if !ok {
+				response.RespondError(c, http.StatusBadRequest, fmt.Sprintf("Category %d is not part of the rubric", req.ID))
+				return
+			}
+			delete(existing, req.ID)
+		}
+		category.Order = req.Order
+		category.Name = req.Name
+		category.Description = req.Description
+		category.Critical = req.Critical
+		categories = append(categories, category)
+	}
+
+	rubric.Name = data.Name
+	rubric.Position = data.Position
+	rubric.Certification = data.Certification
+	rubric.AutoCertify = data.AutoCertify
+	rubric.Categories = categories
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(rubric).Error; err != nil {
+			return err
+		}
+		for _, category := range existing {
+			if err := tx.Delete(category).Error; err != nil {
+				return err
+			}
+		}
+		for _, category := range categories {
+			if err := tx.Save(category).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Errorf("Error updating OTS rubric: %+v (%s)", rubric, err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	response.Respond(c, http.StatusOK, rubric)
+}
+
+// Delete OTS Rubric
+// @Summary Delete OTS Rubric
+// @Description Retire an OTS rubric. Existing evaluations are kept.
+// @Tags training
+// @Param id path string true "Rubric ID"
+// @Success 204
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 404 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/training/ots/rubrics/{id} [DELETE]
+func deleteOTSRubric(c *gin.Context) {
+	rubric, err := database.FindOTSRubricByID(c.Param("id"))
+	if err != nil {
+		log.Errorf("Error getting OTS rubric %s: %s", c.Param("id"), err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+	if rubric == nil {
+		response.RespondError(c, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	if err := database.DB.Select("Categories").Delete(rubric).Error; err != nil {
+		log.Errorf("Error deleting OTS rubric: %s", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	response.RespondBlank(c, http.StatusNoContent)
+}
+
+// Get OTS Evaluations
+// @Summary Get OTS Evaluations
+// @Description Get a student's OTS evaluations
+// @Tags training
+// @Param cid path string true "CID"
+// @Success 200 {object} []models.OTSEvaluation
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/training/{cid}/ots [GET]
+func getOTSEvaluations(c *gin.Context) {
+	user := c.MustGet("x-user").(*models.User)
+
+	if !auth.InGroup(user, "training") && fmt.Sprint(user.CID) != c.Param("cid") {
+		response.RespondError(c, http.StatusForbidden, "Forbidden")
+		return
+	}
+
+	evaluations, err := database.FindOTSEvaluations(database.Atou(c.Param("cid")))
+	if err != nil {
+		log.Errorf("Error getting OTS evaluations for %s: %s", c.Param("cid"), err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	response.Respond(c, http.StatusOK, evaluations)
+}
+
+// Get OTS Evaluation
+// @Summary Get OTS Evaluation
+// @Tags training
+// @Param id path string true "Evaluation ID"
+// @Success 200 {object} models.OTSEvaluation
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 404 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/training/ots/evaluations/{id} [GET]
+func getOTSEvaluation(c *gin.Context) {
+	user := c.MustGet("x-user").(*models.User)
+
+	evaluation, err := database.FindOTSEvaluationByID(c.Param("id"))
+	if err != nil {
+		log.Errorf("Error getting OTS evaluation %s: %s", c.Param("id"), err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+	if evaluation == nil {
+		response.RespondError(c, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	if !auth.InGroup(user, "training") && evaluation.StudentID != user.CID {
+		response.RespondError(c, http.StatusForbidden, "Forbidden")
+		return
+	}
+
+	response.Respond(c, http.StatusOK, evaluation)
+}
+
+// Create OTS Evaluation
+// @Summary Create OTS Evaluation
+// @Description Record the evaluation of an OTS training note against a rubric for the same position. If the student
+// @Description passes and the rubric has auto_certify set, the student is certified on the rubric's certification and the
+// @Description change is recorded. If the student does not meet the certification's prerequisites, nothing is recorded.
+// @Tags training
+// @Param evaluation body dto.OTSEvaluationRequest true "Evaluation"
+// @Success 201 {object} models.OTSEvaluation
+// @Failure 400 {object} response.R
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 404 {object} response.R
+// @Failure 409 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/training/ots/evaluations [POST]
+func postOTSEvaluation(c *gin.Context) {
+	var data dto.OTSEvaluationRequest
+	if err := c.ShouldBind(&data); err != nil {
+		response.RespondError(c, http.StatusBadRequest, "Bad Request")
+		return
+	}
+
+	user := c.MustGet("x-user").(*models.User)
+
+	note := &models.TrainingNote{}
+	if err := database.DB.Find(note, data.TrainingNoteID).Error; err != nil || note.ID == 0 {
+		response.RespondError(c, http.StatusNotFound, "Training Note Not Found")
+		return
+	}
+	if note.Type != "live-ots" && note.Type != "simulation-ots" {
+		response.RespondError(c, http.StatusBadRequest, "Training note is not an OTS")
+		return
+	}
+
+	var count int64
+	if err := database.DB.Model(&models.OTSEvaluation{}).Where(models.OTSEvaluation{TrainingNoteID: note.ID}).Count(&count).Error; err != nil {
+		log.Errorf("Error counting OTS evaluations for note %d: %s", note.ID, err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+	if count > 0 {
+		response.RespondError(c, http.StatusConflict, "Training note already has an evaluation")
+		return
+	}
+
+	rubric, err := database.FindOTSRubricByID(fmt.Sprint(data.RubricID))
+	if err != nil {
+		log.Errorf("Error getting OTS rubric %d: %s", data.RubricID, err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+	if rubric == nil {
+		response.RespondError(c, http.StatusNotFound, "Rubric Not Found")
+		return
+	}
+	if !strings.EqualFold(strings.TrimSpace(rubric.Position), strings.TrimSpace(note.Position)) {
+		response.RespondError(c, http.StatusBadRequest, fmt.Sprintf("Rubric is for %s but the training note is for %s", rubric.Position, note.Position))
+		return
+	}
+
+	var scores []*models.OTSEvaluationScore
+	for _, score := range data.Scores {
+		if score == nil {
+			continue
+		}
+		scores = append(scores, &models.OTSEvaluationScore{
+			CategoryID: score.CategoryID,
+			Score:      score.Score,
+			Comments:   score.Comments,
+		})
+	}
+	scores, err = training.ScoreEvaluation(rubric, data.Result, scores)
+	if err != nil {
+		response.RespondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	evaluation := &models.OTSEvaluation{
+		RubricID:       rubric.ID,
+		TrainingNoteID: note.ID,
+		StudentID:      note.ControllerID,
+		InstructorID:   user.CID,
+		Position:       rubric.Position,
+		Certification:  rubric.Certification,
+		Result:         data.Result,
+		Comments:       data.Comments,
+		Scores:         scores,
+	}
+
+	certify := data.Result == constants.OTSResultPass && rubric.AutoCertify && rubric.Certification != ""
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Rubric", "Student", "Instructor", "CertificationChange").Create(evaluation).Error; err != nil {
+			return err
+		}
+		if !certify {
+			return nil
+		}
+
+		var current []*models.UserCertification
+		if err := tx.Where(models.UserCertification{CID: note.ControllerID, Name: rubric.Certification}).Find(&current).Error; err != nil {
+			return err
+		}
+		if len(current) > 0 && (current[0].Value == constants.CertificationCertified || current[0].Value == constants.CertificationCanTrain) {
+			return nil
+		}
+
+		change := &models.CertificationChange{
+			CID:            note.ControllerID,
+			Certification:  rubric.Certification,
+			NewValue:       constants.CertificationCertified,
+			Source:         constants.CertificationChangeSourceOTS,
+			ActorID:        &user.CID,
+			Reason:         fmt.Sprintf("Passed OTS evaluation %d (%s)", evaluation.ID, rubric.Name),
+			TrainingNoteID: &note.ID,
+		}
+		if err := database.ChangeUserCertification(tx, change); err != nil {
+			return err
+		}
+		evaluation.CertificationChangeID = &change.ID
+		evaluation.CertificationChange = change
+
+		return tx.Model(evaluation).Update("certification_change_id", change.ID).Error
+	})
+	var prerequisiteErr *database.PrerequisiteError
+	if errors.As(err, &prerequisiteErr) {
+		response.RespondError(c, http.StatusBadRequest, prerequisiteErr.Error())
+		return
+	}
+	if err != nil {
+		log.Errorf("Error creating OTS evaluation: %+v (%s)", evaluation, err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	if evaluation.CertificationChange != nil {
+		endSoloOnCertification(note.ControllerID, rubric.Certification)
+	}
+
+	response.Respond(c, http.StatusCreated, evaluation)
+}
+
+// endSoloOnCertification closes any open solo endorsement on a certification the student has just been certified on
+func endSoloOnCertification(cid uint, certification string) {
+	endorsements, err := database.FindSoloEndorsements(fmt.Sprint(cid))
+	if err != nil {
+		log.Errorf("Error getting solo endorsements for %d: %s", cid, err)
+		return
+	}
+
+	for _, e := range endorsements {
+		if e.EndedAt != nil || e.Certification != certification {
+			continue
+		}
+		if err := database.EndSoloEndorsement(e, constants.SoloEndedCertified, "", nil, time.Now()); err != nil {
+			log.Errorf("Error ending solo endorsement %d: %s", e.ID, err)
+		}
+	}
+}
+
+func validateOTSRubric(data *dto.OTSRubricRequest) string {
+	if data.Name == "" || data.Position == "" {
+		return "Name and position are required"
+	}
+	if data.Certification != "" && !database.ValidCertification(data.Certification) {
+		return "Invalid certification"
+	}
+	if data.AutoCertify && data.Certification == "" {
+		return "Certification is required to auto certify"
+	}
+	if len(data.Categories) == 0 {
+		return "At least one category is required"
+	}
+	for _, category := range data.Categories {
+		if category == nil || category.Name == "" {
+			return "Categories require a name"
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,327 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package training
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm/clause"
+
+	"github.com/adh-partnership/api/pkg/auth"
+	"github.com/adh-partnership/api/pkg/database"
+	"github.com/adh-partnership/api/pkg/database/dto"
+	"github.com/adh-partnership/api/pkg/database/models"
+	"github.com/adh-partnership/api/pkg/database/models/constants"
+	"github.com/adh-partnership/api/pkg/discord"
+	"github.com/adh-partnership/api/pkg/gin/response"
+	"github.com/adh-partnership/api/pkg/network/vatusa"
+	"github.com/adh-partnership/api/pkg/training"
+)
+
+const promotionWebhook = "promotions"
+
+// Get Promotion Requests
+// @Summary Get Promotion Requests
+// @Tags training
+// @Param status query string false "Status filter (recommended, approved, submitted, rejected)"
+// @Param cid query string false "Student CID filter"
+// @Success 200 {object} []models.PromotionRequest
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/training/promotions [GET]
+func getPromotionRequests(c *gin.Context) {
+	requests, err := database.FindPromotionRequests(c.Query("status"), c.Query("cid"))
+	if err != nil {
+		log.Errorf("Error getting promotion requests: %s", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	response.Respond(c, http.StatusOK, requests)
+}
+
+// Get Promotion Request
+// @Summary Get Promotion Request
+// @Tags training
+// @Param id path string true "Promotion Request ID"
+// @Success 200 {object} models.PromotionRequest
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 404 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/training/promotions/{id} [GET]
+func getPromotionRequest(c *gin.Context) {
+	user := c.MustGet("x-user").(*models.User)
+
+	request, err := database.FindPromotionRequestByID(c.Param("id"))
+	if err != nil {
+		log.Errorf("Error getting promotion request %s: %s", c.Param("id"), err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+	if request == nil {
+		response.RespondError(c, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	if !auth.InGroup(user, "training") && request.StudentID != user.CID {
+		response.RespondError(c, http.StatusForbidden, "Forbidden")
+		return
+	}
+
+	response.Respond(c, http.StatusOK, request)
+}
+
+// Recommend Promotion
+// @Summary Recommend Promotion
+// @Description Recommend a student for promotion to the next rating, linking the supporting training notes and OTS evaluations
+// @Tags training
+// @Param request body dto.PromotionRequestCreateRequest true "Promotion Request"
+// @Success 201 {object} models.PromotionRequest
+// @Failure 400 {object} response.R
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 404 {object} response.R
+// @Failure 409 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/training/promotions [POST]
+func postPromotionRequest(c *gin.Context) {
+	var data dto.PromotionRequestCreateRequest
+	if err := c.ShouldBind(&data); err != nil || data.Position == "" {
+		response.RespondError(c, http.StatusBadRequest, "Bad Request")
+		return
+	}
+
+	user := c.MustGet("x-user").(*models.User)
+
+	student, err := database.FindUserByCID(fmt.Sprint(data.CID))
+	if err != nil {
+		log.Errorf("Error finding student %d: %s", data.CID, err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+	if student == nil {
+		response.RespondError(c, http.StatusNotFound, "Student Not Found")
+		return
+	}
+	if student.CID == user.CID {
+		response.RespondError(c, http.StatusForbidden, "Cannot recommend yourself")
+		return
+	}
+
+	rating, err := database.FindRatingByShort(data.Rating)
+	if err != nil {
+		log.Errorf("Error finding rating %s: %s", data.Rating, err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+	if rating == nil {
+		response.RespondError(c, http.StatusBadRequest, "Invalid rating")
+		return
+	}
+	if err := training.ValidatePromotionRating(student.RatingID, rating.ID); err != nil {
+		response.RespondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	pending, err := database.FindPromotionRequests("", fmt.Sprint(student.CID))
+	if err != nil {
+		log.Errorf("Error getting promotion requests for %d: %s", student.CID, err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+	for _, p := range pending {
+		if p.Status == constants.PromotionStatusRecommended || p.Status == constants.PromotionStatusApproved {
+			response.RespondError(c, http.StatusConflict, "Student already has a pending promotion request")
+			return
+		}
+	}
+
+	var notes []*models.TrainingNote
+	if len(data.TrainingNoteIDs) > 0 {
+		if err := database.DB.Where("id IN ?", data.TrainingNoteIDs).Find(&notes).Error; err != nil {
+			log.Errorf("Error getting training notes: %s", err)
+			response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+	}
+	var evaluations []*models.OTSEvaluation
+	if len(data.OTSEvaluationIDs) > 0 {
+		if err := database.DB.Where("id IN ?", data.OTSEvaluationIDs).Find(&evaluations).Error; err != nil {
+			log.Errorf("Error getting OTS evaluations: %s", err)
+			response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+	}
+	if len(notes) != len(data.TrainingNoteIDs) || len(evaluations) != len(data.OTSEvaluationIDs) {
+		response.RespondError(c, http.StatusBadRequest, "Invalid training notes or OTS evaluations")
+		return
+	}
+	for _, note := range notes {
+		if note.ControllerID != student.CID {
+			response.RespondError(c, http.StatusBadRequest, "Training notes must belong to the student")
+			return
+		}
+	}
+	for _, evaluation := range evaluations {
+		if evaluation.StudentID != student.CID {
+			response.RespondError(c, http.StatusBadRequest, "OTS evaluations must belong to the student")
+			return
+		}
+	}
+
+	fromRating := student.Rating
+	request := &models.PromotionRequest{
+		StudentID:       student.CID,
+		Student:         student,
+		FromRatingID:    student.RatingID,
+		FromRating:      &fromRating,
+		ToRatingID:      rating.ID,
+		ToRating:        rating,
+		Position:        data.Position,
+		Status:          constants.PromotionStatusRecommended,
+		Notes:           data.Notes,
+		RecommendedByID: user.CID,
+		RecommendedBy:   user,
+		TrainingNotes:   notes,
+		OTSEvaluations:  evaluations,
+	}
+
+	if err := database.DB.Omit("Student", "FromRating", "ToRating", "RecommendedBy", "ReviewedBy", "TrainingNotes.*", "OTSEvaluations.*").
+		Create(request).Error; err != nil {
+		log.Errorf("Error creating promotion request: %+v (%s)", request, err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	notifyPromotion(request, fmt.Sprintf("%s %s has recommended a promotion", user.FirstName, user.LastName))
+
+	response.Respond(c, http.StatusCreated, request)
+}
+
+// Review Promotion Request
+// @Summary Review Promotion Request
+// @Description Approve or reject a promotion request. Approving submits the promotion to VATUSA; if that fails the
+// @Description request stays approved with the error recorded, and approving it again retries the submission.
+// @Tags training
+// @Param id path string true "Promotion Request ID"
+// @Param review body dto.PromotionRequestReviewRequest true "Review"
+// @Success 200 {object} models.PromotionRequest
+// @Failure 400 {object} response.R
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 404 {object} response.R
+// @Failure 409 {object} response.R
+// @Failure 500 {object} response.R
+// @Failure 502 {object} response.R
+// @Router /v1/training/promotions/{id} [PATCH]
+func patchPromotionRequest(c *gin.Context) {
+	var data dto.PromotionRequestReviewRequest
+	if err := c.ShouldBind(&data); err != nil || !models.IsValidPromotionStatus(data.Status) {
+		response.RespondError(c, http.StatusBadRequest, "Bad Request")
+		return
+	}
+
+	user := c.MustGet("x-user").(*models.User)
+
+	request, err := database.FindPromotionRequestByID(c.Param("id"))
+	if err != nil {
+		log.Errorf("Error getting promotion request %s: %s", c.Param("id"), err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+	if request == nil {
+		response.RespondError(c, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	if !training.CanTransitionPromotion(request.Status, data.Status) {
+		response.RespondError(c, http.StatusConflict, fmt.Sprintf("Cannot move a %s promotion request to %s", request.Status, data.Status))
+		return
+	}
+
+	request.ReviewedByID = &user.CID
+	request.ReviewedBy = user
+	if data.ReviewNotes != "" {
+		request.ReviewNotes = data.ReviewNotes
+	}
+
+	var submitErr error
+	if data.Status == constants.PromotionStatusRejected {
+		request.Status = constants.PromotionStatusRejected
+	} else {
+		submitErr = training.SubmitPromotion(vatusa.DefaultClient, request, time.Now())
+		if submitErr != nil {
+			log.Errorf("Error submitting promotion request %d to VATUSA: %s", request.ID, submitErr)
+		}
+	}
+
+	if err := database.DB.Omit(clause.Associations).Save(request).Error; err != nil {
+		log.Errorf("Error updating promotion request: %+v (%s)", request, err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	if request.Status == constants.PromotionStatusSubmitted {
+		if err := database.DB.Model(&models.User{}).Where(models.User{CID: request.StudentID}).Update("rating_id", request.ToRatingID).Error; err != nil {
+			log.Errorf("Error updating rating for %d: %s", request.StudentID, err)
+		}
+	}
+
+	switch {
+	case submitErr != nil:
+		notifyPromotion(request, fmt.Sprintf("Promotion approved by %s %s but the VATUSA submission failed: %s", user.FirstName, user.LastName, submitErr))
+		response.RespondError(c, http.StatusBadGateway, "Promotion approved but the VATUSA submission failed, approve again to retry")
+		return
+	case request.Status == constants.PromotionStatusSubmitted:
+		notifyPromotion(request, fmt.Sprintf("Promotion approved by %s %s and submitted to VATUSA", user.FirstName, user.LastName))
+	default:
+		notifyPromotion(request, fmt.Sprintf("Promotion rejected by %s %s", user.FirstName, user.LastName))
+	}
+
+	response.Respond(c, http.StatusOK, request)
+}
+
+func notifyPromotion(request *models.PromotionRequest, content string) {
+	rating := func(r *models.Rating) string {
+		if r == nil {
+			return "Unknown"
+		}
+		return r.Short
+	}
+
+	embed := discord.NewEmbed().
+		AddField(discord.NewField().SetName("Student").SetValue(formatTrainingUser(request.Student)).SetInline(true)).
+		AddField(discord.NewField().SetName("Promotion").SetValue(fmt.Sprintf("%s to %s", rating(request.FromRating), rating(request.ToRating))).SetInline(true)).
+		AddField(discord.NewField().SetName("Position").SetValue(request.Position).SetInline(true)).
+		AddField(discord.NewField().SetName("Recommended By").SetValue(formatTrainingUser(request.RecommendedBy)).SetInline(true)).
+		AddField(discord.NewField().SetName("Status").SetValue(request.Status).SetInline(true))
+
+	if request.ReviewNotes != "" {
+		embed.AddField(discord.NewField().SetName("Review Notes").SetValue(request.ReviewNotes).SetInline(false))
+	} else if request.Notes != "" {
+		embed.AddField(discord.NewField().SetName("Notes").SetValue(request.Notes).SetInline(false))
+	}
+
+	if err := discord.NewMessage().SetContent(content).AddEmbed(embed).Send(promotionWebhook); err != nil {
+		log.Warnf("Error sending promotion message to Discord: %s", err)
+	}
+}
@@ -0,0 +1,246 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package training
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/adh-partnership/api/pkg/database"
+	"github.com/adh-partnership/api/pkg/database/models"
+	"github.com/adh-partnership/api/pkg/gin/response"
+	"github.com/adh-partnership/api/pkg/training"
+)
+
+// Get Instructor Workload Report
+// @Summary Get Instructor Workload Report
+// @Description Get sessions, no-shows and hours per instructor per month
+// @Tags training
+// @Param from query string false "From, ie 2020-01-01"
+// @Param to query string false "To (inclusive), ie 2020-03-31"
+// @Param format query string false "Set to csv to download the report as CSV"
+// @Success 200 {object} []training.InstructorWorkloadRow
+// @Failure 400 {object} response.R
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/training/reports/instructors [GET]
+func getInstructorWorkloadReport(c *gin.Context) {
+	notes, ok := reportNotes(c)
+	if !ok {
+		return
+	}
+
+	respondReport(c, "instructor-workload", training.InstructorWorkloadHeader, training.InstructorWorkload(notes))
+}
+
+// Get Student Throughput Report
+// @Summary Get Student Throughput Report
+// @Description Get sessions, no-shows and hours per student
+// @Tags training
+// @Param from query string false "From, ie 2020-01-01"
+// @Param to query string false "To (inclusive), ie 2020-03-31"
+// @Param format query string false "Set to csv to download the report as CSV"
+// @Success 200 {object} []training.StudentThroughputRow
+// @Failure 400 {object} response.R
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/training/reports/students [GET]
+func getStudentThroughputReport(c *gin.Context) {
+	notes, ok := reportNotes(c)
+	if !ok {
+		return
+	}
+
+	respondReport(c, "student-throughput", training.StudentThroughputHeader, training.StudentThroughput(notes))
+}
+
+// Get No-Show Report
+// @Summary Get No-Show Report
+// @Description Get the no-show rate of training sessions per month
+// @Tags training
+// @Param from query string false "From, ie 2020-01-01"
+// @Param to query string false "To (inclusive), ie 2020-03-31"
+// @Param format query string false "Set to csv to download the report as CSV"
+// @Success 200 {object} []training.NoShowRow
+// @Failure 400 {object} response.R
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/training/reports/no-shows [GET]
+func getNoShowReport(c *gin.Context) {
+	notes, ok := reportNotes(c)
+	if !ok {
+		return
+	}
+
+	respondReport(c, "no-shows", training.NoShowHeader, training.NoShowRates(notes))
+}
+
+// Get Time to Certification Report
+// @Summary Get Time to Certification Report
+// @Description Get the number of days between joining the roster and each certification, for certifications granted in the range
+// @Tags training
+// @Param from query string false "From, ie 2020-01-01"
+// @Param to query string false "To (inclusive), ie 2020-03-31"
+// @Param format query string false "Set to csv to download the report as CSV"
+// @Success 200 {object} []training.TimeToCertificationRow
+// @Failure 400 {object} response.R
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/training/reports/certifications [GET]
+func getTimeToCertificationReport(c *gin.Context) {
+	from, to, ok := reportRange(c)
+	if !ok {
+		return
+	}
+
+	certified, err := database.FindCertificationDates()
+	if err != nil {
+		log.Errorf("Error getting certification dates: %s", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	var cids []uint
+	for cid, certs := range certified {
+		for name, date := range certs {
+			if (from != nil && date.Before(*from)) || (to != nil && !date.Before(*to)) {
+				delete(certs, name)
+			}
+		}
+		if len(certs) > 0 {
+			cids = append(cids, cid)
+		}
+	}
+
+	var users []*models.User
+	if len(cids) > 0 {
+		if err := database.DB.Where("cid IN ?", cids).Find(&users).Error; err != nil {
+			log.Errorf("Error getting users: %s", err)
+			response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+	}
+
+	respondReport(c, "time-to-certification", training.TimeToCertificationHeader, training.TimeToCertification(users, certified))
+}
+
+// Get Training Request Backlog Report
+// @Summary Get Training Request Backlog Report
+// @Description Get the open and accepted training requests per position, with how long open requests have been waiting
+// @Tags training
+// @Param from query string false "Only requests created from, ie 2020-01-01"
+// @Param to query string false "Only requests created up to (inclusive), ie 2020-03-31"
+// @Param format query string false "Set to csv to download the report as CSV"
+// @Success 200 {object} []training.BacklogRow
+// @Failure 400 {object} response.R
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/training/reports/backlog [GET]
+func getBacklogReport(c *gin.Context) {
+	from, to, ok := reportRange(c)
+	if !ok {
+		return
+	}
+
+	requests, err := database.FindTrainingSessionRequests()
+	if err != nil {
+		log.Errorf("Error getting training requests: %s", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	var filtered []*models.TrainingRequest
+	for _, request := range requests {
+		if request.CreatedAt == nil || (from != nil && request.CreatedAt.Before(*from)) || (to != nil && !request.CreatedAt.Before(*to)) {
+			continue
+		}
+		filtered = append(filtered, request)
+	}
+
+	respondReport(c, "training-backlog", training.BacklogHeader, training.RequestBacklog(filtered, time.Now()))
+}
+
+// reportRange parses the from and to query parameters. to is inclusive, so the returned bound is the following day.
+func reportRange(c *gin.Context) (*time.Time, *time.Time, bool) {
+	var from, to *time.Time
+	if c.Query("from") != "" {
+		t, err := time.Parse("2006-01-02", c.Query("from"))
+		if err != nil {
+			response.RespondError(c, http.StatusBadRequest, "Invalid from")
+			return nil, nil, false
+		}
+		from = &t
+	}
+	if c.Query("to") != "" {
+		t, err := time.Parse("2006-01-02", c.Query("to"))
+		if err != nil {
+			response.RespondError(c, http.StatusBadRequest, "Invalid to")
+			return nil, nil, false
+		}
+		t = t.AddDate(0, 0, 1)
+		to = &t
+	}
+	if from != nil && to != nil && !to.After(*from) {
+		response.RespondError(c, http.StatusBadRequest, "Invalid range")
+		return nil, nil, false
+	}
+
+	return from, to, true
+}
+
+func reportNotes(c *gin.Context) ([]*models.TrainingNote, bool) {
+	from, to, ok := reportRange(c)
+	if !ok {
+		return nil, false
+	}
+
+	notes, err := database.FindTrainingNotesInRange(from, to)
+	if err != nil {
+		log.Errorf("Error getting training notes: %s", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return nil, false
+	}
+
+	return notes, true
+}
+
+// respondReport responds with the report rows, as CSV if requested with format=csv or an Accept header of text/csv
+func respondReport[T interface{ CSVRecord() []string }](c *gin.Context, name string, header []string, rows []T) {
+	if c.Query("format") != "csv" && c.GetHeader("Accept") != "text/csv" {
+		response.Respond(c, http.StatusOK, rows)
+		return
+	}
+
+	records := make([][]string, 0, len(rows))
+	for _, row := range rows {
+		records = append(records, row.CSVRecord())
+	}
+
+	filename := name
+	if c.Query("from") != "" || c.Query("to") != "" {
+		filename += fmt.Sprintf("_%s_%s", c.Query("from"), c.Query("to"))
+	}
+	response.RespondCSV(c, http.StatusOK, filename+".csv", header, records)
+}
@@ -0,0 +1,410 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package training
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/adh-partnership/api/pkg/auth"
+	"github.com/adh-partnership/api/pkg/config"
+	"github.com/adh-partnership/api/pkg/database"
+	"github.com/adh-partnership/api/pkg/database/dto"
+	"github.com/adh-partnership/api/pkg/database/models"
+	"github.com/adh-partnership/api/pkg/database/models/constants"
+	"github.com/adh-partnership/api/pkg/discord"
+	"github.com/adh-partnership/api/pkg/gin/response"
+	"github.com/adh-partnership/api/pkg/training"
+)
+
+var errTooManyRequests = errors.New("too many open training requests")
+
+// Get Training Requests
+// @Summary Get Training Requests
+// @Description Get training session requests. Training staff may filter by any student, everyone else only sees their own requests.
+// @Tags training
+// @Param cid query string false "Student CID filter (training staff only)"
+// @Param status query string false "Status filter, valid values: open, accepted, completed, cancelled"
+// @Success 200 {object} []dto.TrainingRequest
+// @Failure 400 {object} response.R
+// @Failure 401 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/training/requests [GET]
+func getTrainingRequests(c *gin.Context) {
+	user := c.MustGet("x-user").(*models.User)
+
+	filter := &database.TrainingSessionRequestFilter{
+		CID:    c.Query("cid"),
+		Status: c.Query("status"),
+	}
+
+	if !auth.InGroup(user, "training") {
+		filter.CID = fmt.Sprint(user.CID)
+	}
+
+	if filter.Status != "" && !models.IsValidTrainingStatus(filter.Status) {
+		response.RespondError(c, http.StatusBadRequest, "Invalid status")
+		return
+	}
+
+	requests, err := database.FindTrainingSessionRequestWithFilter(filter)
+	if err != nil {
+		log.Errorf("Error getting training requests: %s", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	response.Respond(c, http.StatusOK, dto.ConvertTrainingRequestsToDTO(requests))
+}
+
+// Get Training Request
+// @Summary Get Training Request
+// @Tags training
+// @Param id path string true "Training Request ID"
+// @Success 200 {object} dto.TrainingRequest
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 404 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/training/requests/{id} [GET]
+func getTrainingRequest(c *gin.Context) {
+	user := c.MustGet("x-user").(*models.User)
+
+	request, err := database.FindTrainingSessionRequestByID(c.Param("id"))
+	if err != nil {
+		log.Errorf("Error getting training request %s: %s", c.Param("id"), err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	if request == nil {
+		response.RespondError(c, http.StatusNotFound, "Training Request Not Found")
+		return
+	}
+
+	if request.StudentID != user.CID && !auth.InGroup(user, "training") {
+		response.RespondError(c, http.StatusForbidden, "Forbidden")
+		return
+	}
+
+	response.Respond(c, http.StatusOK, dto.ConvertTrainingRequestToDTO(request))
+}
+
+// Create Training Request
+// @Summary Create Training Request
+// @Description Create a training session request with availability slots for the logged in user.
+// @Tags training
+// @Param request body dto.TrainingRequestCreateRequest true "Training Request"
+// @Success 201 {object} dto.TrainingRequest
+// @Failure 400 {object} response.R
+// @Failure 401 {object} response.R
+// @Failure 409 {object} response.R "Conflict - maximum number of open requests reached"
+// @Failure 500 {object} response.R
+// @Router /v1/training/requests [POST]
+func postTrainingRequest(c *gin.Context) {
+	var data dto.TrainingRequestCreateRequest
+	if err := c.ShouldBind(&data); err != nil {
+		response.RespondError(c, http.StatusBadRequest, "Bad Request")
+		return
+	}
+
+	user := c.MustGet("x-user").(*models.User)
+
+	if !models.IsValidPosition(data.Position) {
+		response.RespondError(c, http.StatusBadRequest, "Invalid position")
+		return
+	}
+
+	if len(data.Slots) == 0 {
+		response.RespondError(c, http.StatusBadRequest, "At least one slot is required")
+		return
+	}
+
+	var slots []*models.TrainingRequestSlot
+	for _, slot := range data.Slots {
+		if slot == nil || slot.Start == nil || slot.End == nil || !slot.End.After(*slot.Start) || slot.End.Before(time.Now()) {
+			response.RespondError(c, http.StatusBadRequest, "Invalid slot")
+			return
+		}
+		slots = append(slots, &models.TrainingRequestSlot{
+			Start: slot.Start,
+			End:   slot.End,
+		})
+	}
+
+	request := &models.TrainingRequest{
+		StudentID: user.CID,
+		Position:  data.Position,
+		Status:    constants.TrainingSessionStatusOpen,
+		Notes:     data.Notes,
+		Slots:     slots,
+	}
+
+	limit := config.Cfg.Facility.TrainingRequests.MaxRequestsPerUser
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if limit > 0 {
+			// Lock the student so concurrent requests are counted one after the other
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&models.User{}, "cid = ?", user.CID).Error; err != nil {
+				return err
+			}
+			count, err := database.CountActiveTrainingSessionRequests(tx, user.CID)
+			if err != nil {
+				return err
+			}
+			if count >= int64(limit) {
+				return errTooManyRequests
+			}
+		}
+		return tx.Create(request).Error
+	}); err != nil {
+		if errors.Is(err, errTooManyRequests) {
+			response.RespondError(c, http.StatusConflict, fmt.Sprintf("You may only have %d open training requests", limit))
+			return
+		}
+		log.Errorf("Error creating training request: %+v (%s)", request, err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	request, err := database.FindTrainingSessionRequestByID(request.ID.String())
+	if err != nil || request == nil {
+		log.Errorf("Error getting created training request: %s", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	notifyTrainingRequest(request, "New training request submitted", false)
+
+	response.Respond(c, http.StatusCreated, dto.ConvertTrainingRequestToDTO(request))
+}
+
+// Update Training Request
+// @Summary Update Training Request
+// @Description Update a training request. Students may change the position and notes of their own open requests, or cancel them.
+// @Description Training staff may accept (requires start and end within one of the student's slots, instructor defaults to the logged in user),
+// @Description complete or cancel requests.
+// @Tags training
+// @Param id path string true "Training Request ID"
+// @Param request body dto.TrainingRequestEditRequest true "Training Request"
+// @Success 200 {object} dto.TrainingRequest
+// @Failure 400 {object} response.R
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 404 {object} response.R
+// @Failure 409 {object} response.R "Conflict - invalid status transition"
+// @Failure 500 {object} response.R
+// @Router /v1/training/requests/{id} [PATCH]
+func patchTrainingRequest(c *gin.Context) {
+	var data dto.TrainingRequestEditRequest
+	if err := c.ShouldBind(&data); err != nil {
+		response.RespondError(c, http.StatusBadRequest, "Bad Request")
+		return
+	}
+
+	user := c.MustGet("x-user").(*models.User)
+	isTraining := auth.InGroup(user, "training")
+
+	request, err := database.FindTrainingSessionRequestByID(c.Param("id"))
+	if err != nil {
+		log.Errorf("Error getting training request %s: %s", c.Param("id"), err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	if request == nil {
+		response.RespondError(c, http.StatusNotFound, "Training Request Not Found")
+		return
+	}
+
+	if request.StudentID != user.CID && !isTraining {
+		response.RespondError(c, http.StatusForbidden, "Forbidden")
+		return
+	}
+
+	// Students can only edit their request details or cancel it
+	if !isTraining && (data.InstructorNotes != "" || data.Instructor != 0 || data.Start != "" || data.End != "" ||
+		(data.Status != "" && data.Status != constants.TrainingSessionStatusCancelled)) {
+		response.RespondError(c, http.StatusForbidden, "Forbidden")
+		return
+	}
+
+	if request.Status == constants.TrainingSessionStatusCompleted || request.Status == constants.TrainingSessionStatusCancelled {
+		response.RespondError(c, http.StatusConflict, "Training request is already closed")
+		return
+	}
+
+	if data.Position != "" && data.Position != request.Position {
+		if request.Status != constants.TrainingSessionStatusOpen {
+			response.RespondError(c, http.StatusConflict, "Position can only be changed on open requests")
+			return
+		}
+		if !models.IsValidPosition(data.Position) {
+			response.RespondError(c, http.StatusBadRequest, "Invalid position")
+			return
+		}
+		request.Position = data.Position
+	}
+
+	if data.Notes != "" {
+		request.Notes = data.Notes
+	}
+
+	if data.InstructorNotes != "" {
+		request.InstructorNotes = data.InstructorNotes
+	}
+
+	oldStatus := request.Status
+	if data.Status != "" && data.Status != request.Status {
+		if !models.IsValidTrainingStatus(data.Status) {
+			response.RespondError(c, http.StatusBadRequest, "Invalid status")
+			return
+		}
+
+		switch data.Status {
+		case constants.TrainingSessionStatusAccepted:
+			if request.Status != constants.TrainingSessionStatusOpen {
+				response.RespondError(c, http.StatusConflict, "Only open requests can be accepted")
+				return
+			}
+
+			start, err := time.Parse(time.RFC3339, data.Start)
+			if err != nil {
+				response.RespondError(c, http.StatusBadRequest, "Invalid start")
+				return
+			}
+			end, err := time.Parse(time.RFC3339, data.End)
+			if err != nil || !end.After(start) {
+				response.RespondError(c, http.StatusBadRequest, "Invalid end")
+				return
+			}
+			if !training.WithinSlots(request.Slots, start, end) {
+				response.RespondError(c, http.StatusBadRequest, "Start and end must fall within one of the student's slots")
+				return
+			}
+
+			instructor := user
+			if data.Instructor != 0 && data.Instructor != user.CID {
+				instructor, err = database.FindUserByCID(fmt.Sprint(data.Instructor))
+				if err != nil {
+					log.Errorf("Error finding instructor %d: %s", data.Instructor, err)
+					response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+					return
+				}
+				if instructor == nil || !auth.InGroup(instructor, "training") {
+					response.RespondError(c, http.StatusBadRequest, "Invalid instructor")
+					return
+				}
+			}
+
+			request.Instructor = instructor
+			request.InstructorID = &instructor.CID
+			request.Start = &start
+			request.End = &end
+		case constants.TrainingSessionStatusCompleted:
+			if request.Status != constants.TrainingSessionStatusAccepted {
+				response.RespondError(c, http.StatusConflict, "Only accepted requests can be completed")
+				return
+			}
+		case constants.TrainingSessionStatusOpen:
+			// Putting an accepted request back into the queue, clear the scheduling details
+			request.Instructor = nil
+			request.InstructorID = nil
+			request.Start = nil
+			request.End = nil
+		}
+
+		request.Status = data.Status
+	}
+
+	if err := database.DB.Omit(clause.Associations).Save(request).Error; err != nil {
+		log.Errorf("Error updating training request: %+v (%s)", request, err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	if oldStatus != request.Status {
+		notifyTrainingRequest(
+			request,
+			fmt.Sprintf("Training request %s by %s %s", request.Status, user.FirstName, user.LastName),
+			oldStatus == constants.TrainingSessionStatusAccepted || request.Status == constants.TrainingSessionStatusAccepted,
+		)
+	}
+
+	response.Respond(c, http.StatusOK, dto.ConvertTrainingRequestToDTO(request))
+}
+
+// notifyTrainingRequest announces a training request change to the training staff webhook. Scheduled
+// sessions are also announced on the scheduled webhook, and when show_all_scheduled is set, so are changes
+// to sessions that had previously been scheduled.
+func notifyTrainingRequest(request *models.TrainingRequest, content string, scheduled bool) {
+	cfg := config.Cfg.Facility.TrainingRequests
+	if !cfg.SendToDiscord {
+		return
+	}
+
+	embed := discord.NewEmbed().
+		AddField(discord.NewField().SetName("Student").SetValue(formatTrainingUser(request.Student)).SetInline(true)).
+		AddField(discord.NewField().SetName("Position").SetValue(request.Position).SetInline(true)).
+		AddField(discord.NewField().SetName("Status").SetValue(request.Status).SetInline(true))
+
+	if request.Instructor != nil {
+		embed.AddField(discord.NewField().SetName("Instructor").SetValue(formatTrainingUser(request.Instructor)).SetInline(true))
+	}
+
+	if request.Start != nil && request.End != nil {
+		embed.AddField(discord.NewField().SetName("Session").SetValue(
+			fmt.Sprintf("%s - %s", request.Start.UTC().Format("2006-01-02 15:04Z"), request.End.UTC().Format("15:04Z")),
+		).SetInline(false))
+	} else if len(request.Slots) > 0 {
+		var slots []string
+		for _, slot := range request.Slots {
+			slots = append(slots, fmt.Sprintf("%s - %s", slot.Start.UTC().Format("2006-01-02 15:04Z"), slot.End.UTC().Format("2006-01-02 15:04Z")))
+		}
+		embed.AddField(discord.NewField().SetName("Availability").SetValue(strings.Join(slots, "\n")).SetInline(false))
+	}
+
+	if request.Notes != "" {
+		embed.AddField(discord.NewField().SetName("Notes").SetValue(request.Notes).SetInline(false))
+	}
+
+	if err := discord.NewMessage().SetContent(content).AddEmbed(embed).Send(cfg.Discord.TrainingStaff); err != nil {
+		log.Warnf("Error sending training request message to Discord: %s", err)
+	}
+
+	if !scheduled || (request.Status != constants.TrainingSessionStatusAccepted && !cfg.Discord.ShowAllScheduled) {
+		return
+	}
+
+	if err := discord.NewMessage().SetContent(content).AddEmbed(embed).Send(cfg.Discord.Scheduled); err != nil {
+		log.Warnf("Error sending scheduled training message to Discord: %s", err)
+	}
+}
+
+func formatTrainingUser(user *models.User) string {
+	if user == nil {
+		return "Unknown"
+	}
+
+	return fmt.Sprintf("%s %s (%d)", user.FirstName, user.LastName, user.CID)
+}
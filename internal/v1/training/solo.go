@@ -0,0 +1,268 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package training
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/adh-partnership/api/pkg/config"
+	"github.com/adh-partnership/api/pkg/database"
+	"github.com/adh-partnership/api/pkg/database/dto"
+	"github.com/adh-partnership/api/pkg/database/models"
+	"github.com/adh-partnership/api/pkg/database/models/constants"
+	"github.com/adh-partnership/api/pkg/gin/response"
+	"github.com/adh-partnership/api/pkg/training"
+)
+
+// Get Active Solo Endorsements
+// @Summary Get Active Solo Endorsements
+// @Description Get the solo endorsements currently in effect
+// @Tags training
+// @Success 200 {object} []dto.PublicSoloEndorsement
+// @Failure 500 {object} response.R
+// @Router /v1/training/solo [GET]
+func getSoloEndorsements(c *gin.Context) {
+	endorsements, err := database.FindActiveSoloEndorsements(time.Now())
+	if err != nil {
+		log.Errorf("Error getting active solo endorsements: %s", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	response.Respond(c, http.StatusOK, dto.ConvertSoloEndorsementsToPublicDTO(endorsements))
+}
+
+// Get Solo Endorsement History
+// @Summary Get Solo Endorsement History
+// @Description Get all solo endorsements, including those that have lapsed or were revoked
+// @Tags training
+// @Param cid query string false "Student CID filter"
+// @Success 200 {object} []models.SoloEndorsement
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/training/solo/history [GET]
+func getSoloEndorsementHistory(c *gin.Context) {
+	endorsements, err := database.FindSoloEndorsements(c.Query("cid"))
+	if err != nil {
+		log.Errorf("Error getting solo endorsements: %s", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	response.Respond(c, http.StatusOK, endorsements)
+}
+
+// Issue Solo Endorsement
+// @Summary Issue Solo Endorsement
+// @Description Issue a solo endorsement, setting the student's certification to solo until it expires. An endorsement
+// @Description with a later starts_at is pending and the student is made solo when it starts.
+// @Tags training
+// @Param endorsement body dto.SoloEndorsementRequest true "Solo Endorsement"
+// @Success 201 {object} models.SoloEndorsement
+// @Failure 400 {object} response.R
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 404 {object} response.R
+// @Failure 409 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/training/solo [POST]
+func postSoloEndorsement(c *gin.Context) {
+	var data dto.SoloEndorsementRequest
+	if err := c.ShouldBind(&data); err != nil || data.Position == "" || data.Reason == "" {
+		response.RespondError(c, http.StatusBadRequest, "Bad Request")
+		return
+	}
+
+	user := c.MustGet("x-user").(*models.User)
+
+	if !database.ValidCertification(data.Certification) {
+		response.RespondError(c, http.StatusBadRequest, "Invalid certification")
+		return
+	}
+
+	now := time.Now()
+	startsAt := now
+	if data.StartsAt != nil {
+		startsAt = *data.StartsAt
+	}
+	maxDuration := time.Duration(config.Cfg.Facility.Solo.MaxDays) * 24 * time.Hour
+	if !data.ExpiresAt.After(startsAt) || !data.ExpiresAt.After(now) || data.ExpiresAt.Sub(startsAt) > maxDuration {
+		response.RespondError(c, http.StatusBadRequest, fmt.Sprintf("Expiry must be after the start and within %d days", config.Cfg.Facility.Solo.MaxDays))
+		return
+	}
+
+	student, err := database.FindUserByCID(fmt.Sprint(data.CID))
+	if err != nil {
+		log.Errorf("Error finding student %d: %s", data.CID, err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+	if student == nil {
+		response.RespondError(c, http.StatusNotFound, "Student Not Found")
+		return
+	}
+	if student.CID == user.CID {
+		response.RespondError(c, http.StatusForbidden, "Cannot endorse yourself")
+		return
+	}
+
+	current, err := database.FindUserCertificationValue(student.CID, data.Certification)
+	if err != nil {
+		log.Errorf("Error getting certification %s for %d: %s", data.Certification, student.CID, err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+	if current == constants.CertificationCertified || current == constants.CertificationCanTrain {
+		response.RespondError(c, http.StatusConflict, "Student already holds this certification")
+		return
+	}
+
+	open, err := database.FindSoloEndorsements(fmt.Sprint(student.CID))
+	if err != nil {
+		log.Errorf("Error getting solo endorsements for %d: %s", student.CID, err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+	for _, e := range open {
+		if e.EndedAt == nil && e.Certification == data.Certification {
+			response.RespondError(c, http.StatusConflict, "Student already has a solo endorsement for this certification")
+			return
+		}
+	}
+
+	endorsement := &models.SoloEndorsement{
+		StudentID:     student.CID,
+		Student:       student,
+		InstructorID:  user.CID,
+		Instructor:    user,
+		Certification: data.Certification,
+		Position:      data.Position,
+		Reason:        data.Reason,
+		PreviousValue: current,
+		StartsAt:      startsAt,
+		ExpiresAt:     data.ExpiresAt,
+		Pending:       startsAt.After(now),
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Create(endorsement).Error; err != nil {
+			return err
+		}
+
+		// The solo job makes the student solo once a later start comes
+		if endorsement.Pending {
+			return nil
+		}
+
+		return database.ChangeUserCertification(tx, &models.CertificationChange{
+			CID:               student.CID,
+			Certification:     data.Certification,
+			NewValue:          constants.CertificationSolo,
+			Source:            constants.CertificationChangeSourceSolo,
+			ActorID:           &user.CID,
+			Reason:            fmt.Sprintf("Solo endorsement %d on %s: %s", endorsement.ID, data.Position, data.Reason),
+			SoloEndorsementID: &endorsement.ID,
+		})
+	})
+	var prerequisiteErr *database.PrerequisiteError
+	if errors.As(err, &prerequisiteErr) {
+		response.RespondError(c, http.StatusBadRequest, prerequisiteErr.Error())
+		return
+	}
+	if err != nil {
+		log.Errorf("Error creating solo endorsement: %+v (%s)", endorsement, err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	content := "Solo endorsement issued"
+	if endorsement.Pending {
+		content = fmt.Sprintf("Solo endorsement issued, starting %s", startsAt.UTC().Format("2006-01-02 15:04Z"))
+	}
+	if err := training.NotifySolo(endorsement, content); err != nil {
+		log.Warnf("Error sending solo endorsement message to Discord: %s", err)
+	}
+
+	response.Respond(c, http.StatusCreated, endorsement)
+}
+
+// Revoke Solo Endorsement
+// @Summary Revoke Solo Endorsement
+// @Description Revoke a solo endorsement before it expires, restoring the student's previous certification
+// @Tags training
+// @Param id path string true "Solo Endorsement ID"
+// @Param revoke body dto.SoloEndorsementRevokeRequest false "Revocation"
+// @Success 200 {object} models.SoloEndorsement
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 404 {object} response.R
+// @Failure 409 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/training/solo/{id} [DELETE]
+func deleteSoloEndorsement(c *gin.Context) {
+	var data dto.SoloEndorsementRevokeRequest
+	_ = c.ShouldBind(&data)
+
+	user := c.MustGet("x-user").(*models.User)
+
+	endorsement, err := database.FindSoloEndorsementByID(c.Param("id"))
+	if err != nil {
+		log.Errorf("Error getting solo endorsement %s: %s", c.Param("id"), err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+	if endorsement == nil {
+		response.RespondError(c, http.StatusNotFound, "Not Found")
+		return
+	}
+	if endorsement.EndedAt != nil {
+		response.RespondError(c, http.StatusConflict, "Solo endorsement has already ended")
+		return
+	}
+
+	current, err := database.FindUserCertificationValue(endorsement.StudentID, endorsement.Certification)
+	if err != nil {
+		log.Errorf("Error getting certification %s for %d: %s", endorsement.Certification, endorsement.StudentID, err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	restore, _ := training.SoloRestoreValue(endorsement, current)
+	if err := database.EndSoloEndorsement(endorsement, constants.SoloEndedRevoked, restore, &user.CID, time.Now()); err != nil {
+		log.Errorf("Error revoking solo endorsement %d: %s", endorsement.ID, err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	content := fmt.Sprintf("Solo endorsement revoked by %s %s", user.FirstName, user.LastName)
+	if data.Reason != "" {
+		content += ": " + data.Reason
+	}
+	if err := training.NotifySolo(endorsement, content); err != nil {
+		log.Warnf("Error sending solo endorsement message to Discord: %s", err)
+	}
+
+	response.Respond(c, http.StatusOK, endorsement)
+}
@@ -0,0 +1,378 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package training
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/adh-partnership/api/pkg/auth"
+	"github.com/adh-partnership/api/pkg/config"
+	"github.com/adh-partnership/api/pkg/database"
+	"github.com/adh-partnership/api/pkg/database/dto"
+	"github.com/adh-partnership/api/pkg/database/models"
+	"github.com/adh-partnership/api/pkg/database/models/constants"
+	"github.com/adh-partnership/api/pkg/gin/response"
+	"github.com/adh-partnership/api/pkg/training"
+)
+
+// Get Syllabus
+// @Summary Get Syllabus
+// @Description Get the syllabus items, ordered by certification and item order
+// @Tags training
+// @Param certification query string false "Certification name filter"
+// @Success 200 {object} []models.SyllabusItem
+// @Failure 500 {object} response.R
+// @Router /v1/training/syllabus [GET]
+func getSyllabus(c *gin.Context) {
+	items, err := database.FindSyllabusItems(c.Query("certification"))
+	if err != nil {
+		log.Errorf("Error getting syllabus items: %s", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	response.Respond(c, http.StatusOK, items)
+}
+
+// Create Syllabus Item
+// @Summary Create Syllabus Item
+// @Tags training
+// @Param item body dto.SyllabusItemRequest true "Syllabus Item"
+// @Success 201 {object} models.SyllabusItem
+// @Failure 400 {object} response.R
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/training/syllabus [POST]
+func postSyllabusItem(c *gin.Context) {
+	var data dto.SyllabusItemRequest
+	if err := c.ShouldBind(&data); err != nil || data.Title == "" {
+		response.RespondError(c, http.StatusBadRequest, "Bad Request")
+		return
+	}
+
+	if !database.ValidCertification(data.Certification) {
+		response.RespondError(c, http.StatusBadRequest, "Invalid certification")
+		return
+	}
+
+	item := &models.SyllabusItem{
+		Certification: data.Certification,
+		Order:         data.Order,
+		Title:         data.Title,
+		Description:   data.Description,
+	}
+
+	if err := database.DB.Create(item).Error; err != nil {
+		log.Errorf("Error creating syllabus item: %+v (%s)", item, err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	response.Respond(c, http.StatusCreated, item)
+}
+
+// Update Syllabus Item
+// @Summary Update Syllabus Item
+// @Tags training
+// @Param id path string true "Syllabus Item ID"
+// @Param item body dto.SyllabusItemRequest true "Syllabus Item"
+// @Success 200 {object} models.SyllabusItem
+// @Failure 400 {object} response.R
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 404 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/training/syllabus/{id} [PUT]
+func putSyllabusItem(c *gin.Context) {
+	var data dto.SyllabusItemRequest
+	if err := c.ShouldBind(&data); err != nil || data.Title == "" {
+		response.RespondError(c, http.StatusBadRequest, "Bad Request")
+		return
+	}
+
+	if !database.ValidCertification(data.Certification) {
+		response.RespondError(c, http.StatusBadRequest, "Invalid certification")
+		return
+	}
+
+	item, err := database.FindSyllabusItemByID(c.Param("id"))
+	if err != nil {
+		log.Errorf("Error getting syllabus item %s: %s", c.Param("id"), err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	if item == nil {
+		response.RespondError(c, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	item.Certification = data.Certification
+	item.Order = data.Order
+	item.Title = data.Title
+	item.Description = data.Description
+
+	if err := database.DB.Save(item).Error; err != nil {
+		log.Errorf("Error updating syllabus item: %+v (%s)", item, err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	response.Respond(c, http.StatusOK, item)
+}
+
+// Delete Syllabus Item
+// @Summary Delete Syllabus Item
+// @Description Delete a syllabus item, along with the progress recorded against it
+// @Tags training
+// @Param id path string true "Syllabus Item ID"
+// @Success 204
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 404 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/training/syllabus/{id} [DELETE]
+func deleteSyllabusItem(c *gin.Context) {
+	item, err := database.FindSyllabusItemByID(c.Param("id"))
+	if err != nil {
+		log.Errorf("Error getting syllabus item %s: %s", c.Param("id"), err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	if item == nil {
+		response.RespondError(c, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	if err := database.DB.Where(models.TrainingNoteProgress{SyllabusItemID: item.ID}).Delete(&models.TrainingNoteProgress{}).Error; err != nil {
+		log.Errorf("Error deleting syllabus item progress: %s", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	if err := database.DB.Delete(item).Error; err != nil {
+		log.Errorf("Error deleting syllabus item: %s", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	response.RespondBlank(c, http.StatusNoContent)
+}
+
+// Get Training Progress
+// @Summary Get Training Progress
+// @Description Get a student's syllabus progress for every certification and toward their next certification
+// @Tags training
+// @Param cid path string true "CID"
+// @Success 200 {object} dto.TrainingProgress
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 404 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/training/{cid}/progress [GET]
+func getTrainingProgress(c *gin.Context) {
+	user := c.MustGet("x-user").(*models.User)
+
+	if !auth.InGroup(user, "training") && fmt.Sprint(user.CID) != c.Param("cid") {
+		response.RespondError(c, http.StatusForbidden, "Forbidden")
+		return
+	}
+
+	student, err := database.FindUserByCID(c.Param("cid"))
+	if err != nil {
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+	if student == nil {
+		response.RespondError(c, http.StatusNotFound, "Student Not Found")
+		return
+	}
+
+	certs, err := database.FindUserCertifications(student)
+	if err != nil {
+		log.Errorf("Error getting certifications for %d: %s", student.CID, err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+	values := map[string]string{}
+	for _, cert := range certs {
+		values[cert.Name] = cert.Value
+	}
+
+	items, err := database.FindSyllabusItems("")
+	if err != nil {
+		log.Errorf("Error getting syllabus items: %s", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	marks, err := database.FindTrainingNoteProgress(student.CID)
+	if err != nil {
+		log.Errorf("Error getting training progress for %d: %s", student.CID, err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	sessions, err := database.FindLastTrainingSessions()
+	if err != nil {
+		log.Errorf("Error getting last training sessions: %s", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	progress := training.BuildProgress(database.GetCertifications(), values, items, marks)
+	ret := &dto.TrainingProgress{
+		Student:           dto.ConvUserToUserResponse(student),
+		Certifications:    []*dto.CertificationProgress{},
+		NextCertification: convertProgress(training.NextCertification(progress)),
+	}
+	for _, p := range progress {
+		ret.Certifications = append(ret.Certifications, convertProgress(p))
+	}
+	if last, ok := sessions[student.CID]; ok {
+		ret.LastSession = &last
+	}
+	ret.Stalled = isInTraining(values) && training.IsStalled(ret.LastSession, time.Now(), stalledAfter())
+
+	response.Respond(c, http.StatusOK, ret)
+}
+
+// Get Stalled Students
+// @Summary Get Stalled Students
+// @Description Get students in training that have not had a session within the configured number of days
+// @Tags training
+// @Success 200 {object} []dto.StalledStudent
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/training/stalled [GET]
+func getStalledStudents(c *gin.Context) {
+	students, err := database.FindStudentsInTraining()
+	if err != nil {
+		log.Errorf("Error getting students in training: %s", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	sessions, err := database.FindLastTrainingSessions()
+	if err != nil {
+		log.Errorf("Error getting last training sessions: %s", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	now := time.Now()
+	ret := []*dto.StalledStudent{}
+	for _, student := range students {
+		var lastSession *time.Time
+		if last, ok := sessions[student.CID]; ok {
+			lastSession = &last
+		}
+		if !training.IsStalled(lastSession, now, stalledAfter()) {
+			continue
+		}
+
+		s := &dto.StalledStudent{
+			Student:     dto.ConvUserToUserResponse(student),
+			LastSession: lastSession,
+			InTraining:  []string{},
+		}
+		if lastSession != nil {
+			s.DaysSinceSession = int(now.Sub(*lastSession).Hours() / 24)
+		}
+		for name, cert := range s.Student.Certifications {
+			if cert.Value == constants.CertificationTraining || cert.Value == constants.CertificationSolo {
+				s.InTraining = append(s.InTraining, name)
+			}
+		}
+		ret = append(ret, s)
+	}
+
+	response.Respond(c, http.StatusOK, ret)
+}
+
+// buildTrainingNoteProgress validates the syllabus marks of a training note request
+func buildTrainingNoteProgress(student *models.User, data []*dto.TrainingNoteProgressRequest) ([]*models.TrainingNoteProgress, error) {
+	var progress []*models.TrainingNoteProgress
+	for _, p := range data {
+		if p == nil || !models.IsValidSyllabusProgressStatus(p.Status) {
+			return nil, fmt.Errorf("invalid progress status")
+		}
+
+		item, err := database.FindSyllabusItemByID(fmt.Sprint(p.ItemID))
+		if err != nil {
+			return nil, err
+		}
+		if item == nil {
+			return nil, fmt.Errorf("invalid syllabus item %d", p.ItemID)
+		}
+
+		progress = append(progress, &models.TrainingNoteProgress{
+			ControllerID:   student.CID,
+			SyllabusItemID: item.ID,
+			Status:         p.Status,
+			Comments:       p.Comments,
+		})
+	}
+
+	return progress, nil
+}
+
+func isInTraining(certs map[string]string) bool {
+	for _, v := range certs {
+		if v == constants.CertificationTraining || v == constants.CertificationSolo {
+			return true
+		}
+	}
+
+	return false
+}
+
+func stalledAfter() time.Duration {
+	return time.Duration(config.Cfg.Facility.TrainingRequests.StalledAfterDays) * 24 * time.Hour
+}
+
+func convertProgress(progress *training.CertificationProgress) *dto.CertificationProgress {
+	if progress == nil {
+		return nil
+	}
+
+	ret := &dto.CertificationProgress{
+		Certification: progress.Certification,
+		DisplayName:   progress.DisplayName,
+		Value:         progress.Value,
+		Satisfactory:  progress.Satisfactory,
+		Total:         progress.Total,
+		Items:         []*dto.ItemProgress{},
+	}
+	for _, item := range progress.Items {
+		ret.Items = append(ret.Items, &dto.ItemProgress{
+			Item:       item.Item,
+			Status:     item.Status,
+			Comments:   item.Comments,
+			LastMarked: item.LastMarked,
+		})
+	}
+
+	return ret
+}
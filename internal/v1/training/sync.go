@@ -0,0 +1,107 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package training
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/adh-partnership/api/pkg/database"
+	"github.com/adh-partnership/api/pkg/database/dto"
+	"github.com/adh-partnership/api/pkg/database/models"
+	"github.com/adh-partnership/api/pkg/database/models/constants"
+	"github.com/adh-partnership/api/pkg/gin/response"
+	"github.com/adh-partnership/api/pkg/training"
+)
+
+// Get Training Note Sync Queue
+// @Summary Get Training Note Sync Queue
+// @Description Get training notes by VATUSA sync status, defaults to notes that failed to sync. Includes deleted notes
+// @Description whose deletion has not reached VATUSA.
+// @Tags training
+// @Param status query string false "Sync status (pending, failed)"
+// @Success 200 {object} []dto.TrainingNoteSync
+// @Failure 400 {object} response.R
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/training/sync [GET]
+func getTrainingNoteSync(c *gin.Context) {
+	status := c.DefaultQuery("status", constants.TrainingNoteSyncFailed)
+	if status != constants.TrainingNoteSyncFailed && status != constants.TrainingNoteSyncPending {
+		response.RespondError(c, http.StatusBadRequest, "Invalid status")
+		return
+	}
+
+	notes, err := database.FindTrainingNotesBySyncStatus(status)
+	if err != nil {
+		log.Errorf("Error getting training notes with sync status %s: %s", status, err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	response.Respond(c, http.StatusOK, dto.ConvertTrainingNotesToSync(notes))
+}
+
+// Retry Training Note Sync
+// @Summary Retry Training Note Sync
+// @Description Queue a training note that failed to sync to be pushed to VATUSA again
+// @Tags training
+// @Param id path string true "Training Note ID"
+// @Success 200 {object} dto.TrainingNoteSync
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 404 {object} response.R
+// @Failure 409 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/training/sync/{id}/retry [POST]
+func postTrainingNoteSyncRetry(c *gin.Context) {
+	note, err := database.FindTrainingNoteForSync(c.Param("id"))
+	if err != nil {
+		log.Errorf("Error getting training note %s: %s", c.Param("id"), err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	if note == nil {
+		response.RespondError(c, http.StatusNotFound, "Training Note Not Found")
+		return
+	}
+
+	if note.SyncStatus != constants.TrainingNoteSyncFailed {
+		response.RespondError(c, http.StatusConflict, "Training note has not failed to sync")
+		return
+	}
+
+	training.QueueSync(note, note.SyncAction, time.Now())
+	if err := database.DB.Model(&models.TrainingNote{}).Unscoped().Where(models.TrainingNote{ID: note.ID}).
+		Updates(map[string]interface{}{
+			"sync_status":   note.SyncStatus,
+			"sync_action":   note.SyncAction,
+			"sync_attempts": note.SyncAttempts,
+			"sync_error":    note.SyncError,
+			"next_sync_at":  note.NextSyncAt,
+		}).Error; err != nil {
+		log.Errorf("Error queueing training note %d for sync: %s", note.ID, err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	response.Respond(c, http.StatusOK, dto.ConvertTrainingNoteToSync(note))
+}
@@ -19,16 +19,19 @@ package training
 import (
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 
 	"github.com/adh-partnership/api/pkg/auth"
 	"github.com/adh-partnership/api/pkg/database"
 	"github.com/adh-partnership/api/pkg/database/dto"
 	"github.com/adh-partnership/api/pkg/database/models"
+	"github.com/adh-partnership/api/pkg/database/models/constants"
 	"github.com/adh-partnership/api/pkg/gin/response"
-	"github.com/adh-partnership/api/pkg/network/vatusa"
+	"github.com/adh-partnership/api/pkg/training"
 )
 
 // Get Training Records for cid
@@ -49,7 +52,8 @@ func getTraining(c *gin.Context) {
 		return
 	}
 
-	if err := database.DB.Preload(clause.Associations).Where(models.TrainingNote{ControllerID: database.Atou(c.Param("cid"))}).Find(&notes).Error; err != nil {
+	if err := database.DB.Preload(clause.Associations).Preload("Progress.SyllabusItem").
+		Where(models.TrainingNote{ControllerID: database.Atou(c.Param("cid"))}).Find(&notes).Error; err != nil {
 		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
 		return
 	}
@@ -87,7 +91,13 @@ func postTraining(c *gin.Context) {
 		return
 	}
 
-	training := models.TrainingNote{
+	progress, err := buildTrainingNoteProgress(student, trainingRequest.Progress)
+	if err != nil {
+		response.RespondError(c, http.StatusBadRequest, "Invalid progress")
+		return
+	}
+
+	note := models.TrainingNote{
 		Controller:  student,
 		Instructor:  user,
 		Position:    trainingRequest.Position,
@@ -95,37 +105,18 @@ func postTraining(c *gin.Context) {
 		Duration:    trainingRequest.Duration,
 		Comments:    trainingRequest.Comments,
 		SessionDate: &trainingRequest.SessionDate,
+		Progress:    progress,
 	}
 
-	status, id, err := vatusa.SubmitTrainingNote(
-		fmt.Sprint(student.CID),
-		fmt.Sprint(user.CID),
-		training.Position,
-		*training.SessionDate,
-		training.Duration,
-		training.Comments,
-		training.Type,
-	)
-	if err != nil {
-		log.Errorf("VATUSA returned status code %d: %+v (%+v)", status, training, err)
-		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
-		return
-	}
-	if status > 299 {
-		log.Errorf("VATUSA returned status code %d: %+v", status, training)
-		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
-		return
-	}
+	training.QueueSync(&note, constants.TrainingNoteSyncActionCreate, time.Now())
 
-	training.VATUSAID = uint(id)
-
-	if err := database.DB.Create(&training).Error; err != nil {
-		log.Errorf("Failed to create training note: %+v (%+v)", training, err)
+	if err := database.DB.Create(&note).Error; err != nil {
+		log.Errorf("Failed to create training note: %+v (%+v)", note, err)
 		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
 		return
 	}
 
-	response.Respond(c, http.StatusCreated, training)
+	response.Respond(c, http.StatusCreated, note)
 }
 
 // Update Training Record
@@ -148,41 +139,46 @@ func putTraining(c *gin.Context) {
 		return
 	}
 
-	training := &models.TrainingNote{}
-	if err := database.DB.Preload(clause.Associations).Find(training, c.Param("id")).Error; err != nil {
+	note := &models.TrainingNote{}
+	if err := database.DB.Preload(clause.Associations).Find(note, c.Param("id")).Error; err != nil || note.ID == 0 {
 		response.RespondError(c, http.StatusNotFound, "Training Note Not Found")
 		return
 	}
 
-	training.Position = trainingRequest.Position
-	training.Type = trainingRequest.Type
-	training.Duration = trainingRequest.Duration
-	training.Comments = trainingRequest.Comments
-	training.SessionDate = &trainingRequest.SessionDate
-
-	status, err := vatusa.EditTrainingNote(
-		fmt.Sprint(training.VATUSAID),
-		fmt.Sprint(training.Controller.CID),
-		fmt.Sprint(training.Instructor.CID),
-		training.Position,
-		*training.SessionDate,
-		training.Duration,
-		training.Comments,
-		training.Type,
-	)
-	if err != nil || status > 299 {
-		log.Errorf("VATUSA returned status code %d: %+v (%+v)", status, training, err)
-		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
-		return
+	note.Position = trainingRequest.Position
+	note.Type = trainingRequest.Type
+	note.Duration = trainingRequest.Duration
+	note.Comments = trainingRequest.Comments
+	note.SessionDate = &trainingRequest.SessionDate
+
+	var progress []*models.TrainingNoteProgress
+	if trainingRequest.Progress != nil {
+		var err error
+		progress, err = buildTrainingNoteProgress(note.Controller, trainingRequest.Progress)
+		if err != nil {
+			response.RespondError(c, http.StatusBadRequest, "Invalid progress")
+			return
+		}
 	}
 
-	if err := database.DB.Save(training).Error; err != nil {
-		log.Errorf("Failed to update training note: %+v (%+v)", training, err)
+	training.QueueSync(note, constants.TrainingNoteSyncActionUpdate, time.Now())
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if trainingRequest.Progress != nil {
+			if err := tx.Where(models.TrainingNoteProgress{TrainingNoteID: note.ID}).Delete(&models.TrainingNoteProgress{}).Error; err != nil {
+				return err
+			}
+			note.Progress = progress
+		}
+		return tx.Save(note).Error
+	})
+	if err != nil {
+		log.Errorf("Failed to update training note: %+v (%+v)", note, err)
 		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
 		return
 	}
 
-	response.Respond(c, http.StatusOK, training)
+	response.Respond(c, http.StatusOK, note)
 }
 
 // Delete Training Record
@@ -204,21 +200,22 @@ func deleteTraining(c *gin.Context) {
 		return
 	}
 
-	training := &models.TrainingNote{}
-	if err := database.DB.Find(training, c.Param("id")).Error; err != nil {
+	note := &models.TrainingNote{}
+	if err := database.DB.Find(note, c.Param("id")).Error; err != nil || note.ID == 0 {
 		response.RespondError(c, http.StatusNotFound, "Training Note Not Found")
 		return
 	}
 
-	status, err := vatusa.DeleteTrainingNote(fmt.Sprint(training.VATUSAID))
-	if err != nil || status > 299 {
-		log.Errorf("VATUSA returned status code %d: %+v (%+v)", status, training, err)
+	// The note is soft deleted so it disappears right away, the sync job removes it once VATUSA has been updated
+	training.QueueSync(note, constants.TrainingNoteSyncActionDelete, time.Now())
+	if err := database.DB.Omit(clause.Associations).Save(note).Error; err != nil {
+		log.Errorf("Failed to queue training note deletion: %+v (%+v)", note, err)
 		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
 		return
 	}
 
-	if err := database.DB.Delete(training).Error; err != nil {
-		log.Errorf("Failed to delete training note: %+v (%+v)", training, err)
+	if err := database.DB.Delete(note).Error; err != nil {
+		log.Errorf("Failed to delete training note: %+v (%+v)", note, err)
 		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
 		return
 	}
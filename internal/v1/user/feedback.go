@@ -0,0 +1,93 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package user
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/adh-partnership/api/pkg/auth"
+	"github.com/adh-partnership/api/pkg/database"
+	"github.com/adh-partnership/api/pkg/database/dto"
+	models "github.com/adh-partnership/api/pkg/database/models"
+	"github.com/adh-partnership/api/pkg/database/models/constants"
+	"github.com/adh-partnership/api/pkg/feedback"
+	"github.com/adh-partnership/api/pkg/gin/response"
+)
+
+// Get User Feedback Summary
+// @Summary Get User Feedback Summary
+// @Description Get the rating distribution of a controller's feedback over the last twelve months, overall, by month
+// @Description and by position prefix. Controllers viewing their own summary only see approved feedback.
+// @Tags user
+// @Param cid path string true "CID"
+// @Success 200 {object} dto.FeedbackSummaryResponse
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 404 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/user/:cid/feedback [GET]
+func getUserFeedbackSummary(c *gin.Context) {
+	reqUser := c.MustGet("x-user").(*models.User)
+
+	staff := auth.InGroup(reqUser, "admin")
+	if fmt.Sprint(reqUser.CID) != c.Param("cid") && !staff {
+		response.RespondError(c, http.StatusForbidden, "Forbidden")
+		return
+	}
+
+	user, err := database.FindUserByCID(c.Param("cid"))
+	if err != nil {
+		log.Errorf("Error finding user %s: %s", c.Param("cid"), err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+	if user == nil {
+		response.RespondError(c, http.StatusNotFound, "User not found")
+		return
+	}
+
+	from, to := feedback.DefaultRange(time.Now())
+	fs, err := database.FindFeedbackInRange(user.CID, &from, &to)
+	if err != nil {
+		log.Errorf("Error getting feedback of %d: %s", user.CID, err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	if !staff {
+		var approved []*models.Feedback
+		for _, f := range fs {
+			if f.Status == constants.FeedbackStatusApproved {
+				approved = append(approved, f)
+			}
+		}
+		fs = approved
+	}
+
+	response.Respond(c, http.StatusOK, &dto.FeedbackSummaryResponse{
+		CID:       user.CID,
+		From:      from,
+		To:        to,
+		Summary:   feedback.SummaryResponse(feedback.Summarize(fs)),
+		Monthly:   feedback.MonthsResponse(feedback.ByMonth(fs, from, to)),
+		Positions: feedback.PositionsResponse(feedback.ByPositionPrefix(fs)),
+	})
+}
@@ -198,7 +198,12 @@ func patchUser(c *gin.Context) {
 		}
 	}
 
-	errors := dto.PatchUserFromUserResponse(oldUser, req)
+	if req.CertificationOverride && !auth.InGroup(user, "admin") {
+		response.RespondError(c, http.StatusForbidden, "Only administrators can override certification prerequisites")
+		return
+	}
+
+	errors := dto.PatchUserFromUserResponse(oldUser, req, &user.CID)
 	if len(errors) > 0 {
 		response.RespondError(c, http.StatusBadRequest, strings.Join(errors, ", "))
 		return
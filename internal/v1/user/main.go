@@ -45,10 +45,16 @@ func Routes(r *gin.RouterGroup) {
 
 	r.GET("/all", getFullRoster)
 	r.GET("/roster", getRoster)
+	r.GET("/roster/export", auth.NotGuest, auth.InGroup("admin"), getRosterExport)
+	r.POST("/roster/import", auth.NotGuest, auth.InGroup("admin"), postRosterImport)
 	r.GET("/staff", getStaff)
 
 	r.GET("/roles", auth.NotGuest, getUserRoles)
 	r.GET("/:cid/roles", getUserRoles)
 	r.PUT("/:cid/roles/:role", auth.NotGuest, putUserRoles)
 	r.DELETE("/:cid/roles/:role", auth.NotGuest, deleteUserRoles)
+
+	r.GET("/:cid/certifications/history", auth.NotGuest, getUserCertificationHistory)
+	r.GET("/:cid/noshows", auth.NotGuest, getUserNoShows)
+	r.GET("/:cid/feedback", auth.NotGuest, getUserFeedbackSummary)
 }
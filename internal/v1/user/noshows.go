@@ -0,0 +1,69 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package user
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/adh-partnership/api/pkg/auth"
+	"github.com/adh-partnership/api/pkg/database"
+	models "github.com/adh-partnership/api/pkg/database/models"
+	"github.com/adh-partnership/api/pkg/gin/response"
+)
+
+// Get User No Shows
+// @Summary Get User No Shows
+// @Description Get the event positions a user was recorded as not working, newest first
+// @Tags user
+// @Param cid path string true "CID"
+// @Success 200 {object} []models.EventNoShow
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 404 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/user/:cid/noshows [GET]
+func getUserNoShows(c *gin.Context) {
+	reqUser := c.MustGet("x-user").(*models.User)
+
+	if fmt.Sprint(reqUser.CID) != c.Param("cid") && !auth.InGroup(reqUser, "events") {
+		response.RespondError(c, http.StatusForbidden, "Forbidden")
+		return
+	}
+
+	user, err := database.FindUserByCID(c.Param("cid"))
+	if err != nil {
+		log.Errorf("Error finding user %s: %s", c.Param("cid"), err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+	if user == nil {
+		response.RespondError(c, http.StatusNotFound, "User not found")
+		return
+	}
+
+	noShows, err := database.FindUserNoShows(user.CID)
+	if err != nil {
+		log.Errorf("Error getting no shows of %d: %s", user.CID, err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	response.Respond(c, http.StatusOK, noShows)
+}
@@ -0,0 +1,109 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package user
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	models "github.com/adh-partnership/api/pkg/database/models"
+	"github.com/adh-partnership/api/pkg/gin/response"
+	"github.com/adh-partnership/api/pkg/rosterfile"
+)
+
+// Export Roster
+// @Summary Export Roster
+// @Description Export the roster with every certification, as CSV or JSON
+// @Tags user
+// @Param format query string false "csv (default) or json"
+// @Success 200
+// @Failure 400 {object} response.R
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/user/roster/export [GET]
+func getRosterExport(c *gin.Context) {
+	format := c.DefaultQuery("format", rosterfile.FormatCSV)
+	if !rosterfile.IsValidFormat(format) {
+		response.RespondError(c, http.StatusBadRequest, "Invalid format")
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := rosterfile.Export(&buf, format); err != nil {
+		log.Errorf("Error exporting roster: %s", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	contentType := "text/csv; charset=utf-8"
+	if format == rosterfile.FormatJSON {
+		contentType = "application/json; charset=utf-8"
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fmt.Sprintf("roster-%s.%s", time.Now().Format("2006-01-02"), format)))
+	c.Data(http.StatusOK, contentType, buf.Bytes())
+}
+
+// Import Roster
+// @Summary Import Roster
+// @Description Import certification values from a CSV or JSON roster file, in the format of the export, sent as the
+// @Description request body. Imports are dry runs that only report the changes and errors unless dry_run=false,
+// @Description which applies them in a single transaction if there are no errors.
+// @Tags user
+// @Param format query string false "csv (default) or json"
+// @Param dry_run query bool false "Set to false to apply the import, defaults to true"
+// @Param reason query string false "Reason recorded against every change"
+// @Param override query bool false "Set certifications even if their prerequisites are not met, requires a reason"
+// @Success 200 {object} rosterfile.ImportResult
+// @Failure 400 {object} rosterfile.ImportResult
+// @Failure 401 {object} response.R
+// @Failure 403 {object} response.R
+// @Failure 500 {object} response.R
+// @Router /v1/user/roster/import [POST]
+func postRosterImport(c *gin.Context) {
+	user := c.MustGet("x-user").(*models.User)
+
+	format := c.DefaultQuery("format", rosterfile.FormatCSV)
+	if !rosterfile.IsValidFormat(format) {
+		response.RespondError(c, http.StatusBadRequest, "Invalid format")
+		return
+	}
+
+	result, err := rosterfile.Import(c.Request.Body, rosterfile.ImportOptions{
+		Format:   format,
+		ActorID:  &user.CID,
+		Reason:   c.Query("reason"),
+		Override: c.Query("override") == "true",
+		DryRun:   c.Query("dry_run") != "false",
+	})
+	if err != nil {
+		log.Errorf("Error importing roster: %s", err)
+		response.RespondError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	if len(result.Errors) > 0 {
+		response.Respond(c, http.StatusBadRequest, result)
+		return
+	}
+
+	response.Respond(c, http.StatusOK, result)
+}
@@ -65,6 +65,42 @@ func sanitizeConfig(cfg *Config) {
 	if cfg.Server.Port == "" {
 		cfg.Server.Port = "8080"
 	}
+	if cfg.Facility.TrainingRequests.MinSessionLength == 0 {
+		cfg.Facility.TrainingRequests.MinSessionLength = 60
+	}
+	if cfg.Facility.TrainingRequests.StalledAfterDays == 0 {
+		cfg.Facility.TrainingRequests.StalledAfterDays = 30
+	}
+	if cfg.Facility.TrainingRequests.MaxStudentsPerMentor == 0 {
+		cfg.Facility.TrainingRequests.MaxStudentsPerMentor = 5
+	}
+	if cfg.Facility.Events.AttendanceGraceMinutes == 0 {
+		cfg.Facility.Events.AttendanceGraceMinutes = 15
+	}
+	if cfg.Facility.Feedback.VerificationHours == 0 {
+		cfg.Facility.Feedback.VerificationHours = 12
+	}
+	if cfg.Facility.Feedback.FlightLogDays == 0 {
+		cfg.Facility.Feedback.FlightLogDays = 30
+	}
+	if cfg.Facility.Events.RecurringDaysAhead == 0 {
+		cfg.Facility.Events.RecurringDaysAhead = 28
+	}
+	if cfg.Facility.Events.Notifications.AnnouncementWebhook == "" {
+		cfg.Facility.Events.Notifications.AnnouncementWebhook = "events"
+	}
+	if cfg.Facility.Events.Notifications.AssignmentWebhook == "" {
+		cfg.Facility.Events.Notifications.AssignmentWebhook = "event_assignments"
+	}
+	if cfg.Facility.Events.Notifications.StaffWebhook == "" {
+		cfg.Facility.Events.Notifications.StaffWebhook = "events_staff"
+	}
+	if cfg.Facility.Solo.MaxDays == 0 {
+		cfg.Facility.Solo.MaxDays = 30
+	}
+	if cfg.Facility.Solo.WarnDaysBefore == 0 {
+		cfg.Facility.Solo.WarnDaysBefore = 5
+	}
 	if !strings.HasSuffix(cfg.Storage.BaseURL, "/") {
 		cfg.Storage.BaseURL += "/"
 	}
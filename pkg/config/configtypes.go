@@ -116,15 +116,75 @@ type ConfigFacility struct {
 	Stats            ConfigFacilityStats    `json:"stats"`
 	Visiting         ConfigFacilityVisiting `json:"visiting"`
 	TrainingRequests ConfigFacilityTraining `json:"training_requests"`
+	Solo             ConfigFacilitySolo     `json:"solo"`
+	Events           ConfigFacilityEvents   `json:"events"`
+	Feedback         ConfigFacilityFeedback `json:"feedback"`
 	FrontendURL      string                 `json:"frontend_url"`
 }
 
+type ConfigFacilityEvents struct {
+	// Certification new event positions need by default, keyed by the full callsign or by its facility type suffix
+	// such as TWR. Positions without an entry need no certification
+	PositionCertifications map[string]string                `json:"position_certifications"`
+	Notifications          ConfigFacilityEventNotifications `json:"notifications"`
+	// Minutes a controller may log on after their assignment starts or off before it ends and still have attended
+	AttendanceGraceMinutes int `json:"attendance_grace_minutes"`
+	// Events of recurring templates are created this many days before they start
+	RecurringDaysAhead int `json:"recurring_days_ahead"`
+	// Partnership API instances of facilities co-hosting events, keyed by facility ID such as ZLC
+	Partners map[string]ConfigFacilityEventPartner `json:"partners"`
+}
+
+type ConfigFacilityEventPartner struct {
+	// Base URL of the partner's API, such as https://api.zlcartcc.org
+	URL string `json:"url"`
+	// API key issued by the partner for this facility
+	APIKey string `json:"api_key"`
+}
+
+type ConfigFacilityFeedback struct {
+	// Hours before a submission in which the controller's sessions and the submitter's flights are looked for
+	VerificationHours int `json:"verification_hours"`
+	// Days the flights seen within the facility are kept for verifying feedback
+	FlightLogDays int `json:"flight_log_days"`
+}
+
+type ConfigFacilityEventNotifications struct {
+	Enabled bool `json:"enabled"`
+	// Webhook new events, and changes to events that were announced, are posted to
+	AnnouncementWebhook string `json:"announcement_webhook"`
+	// Webhook controllers are mentioned on when they are assigned, reminded or told about changes. Webhooks cannot
+	// send direct messages, so this should be a channel controllers watch
+	AssignmentWebhook string `json:"assignment_webhook"`
+	// Webhook events staff are told on when a controller withdraws after assignments are published
+	StaffWebhook string `json:"staff_webhook"`
+	// Also email controllers when they are assigned, reminded or told about changes
+	Email bool `json:"email"`
+	// Assigned controllers are reminded this many hours before the event starts, once for each entry
+	ReminderHours []int `json:"reminder_hours"`
+}
+
+type ConfigFacilitySolo struct {
+	// Longest solo endorsement, in days, that can be issued. Defaults to 30
+	MaxDays int `json:"max_days"`
+	// Students and training staff are warned this many days before an endorsement expires. Defaults to 5
+	WarnDaysBefore int `json:"warn_days_before"`
+}
+
 type ConfigFacilityTraining struct {
 	Enabled            bool                          `json:"enabled"`
 	Discord            ConfigFacilityTrainingDiscord `json:"discord"`
 	Positions          []string                      `json:"positions"`
 	MaxRequestsPerUser int                           `json:"max_requests_per_user"`
 	SendToDiscord      bool                          `json:"send_to_discord"`
+	// Maps a training position to the certification an instructor should hold to train on it
+	Certifications map[string]string `json:"certifications"`
+	// Minimum overlap, in minutes, for a suggested session. Defaults to 60
+	MinSessionLength int `json:"min_session_length"`
+	// Students in training without a session for this many days are flagged as stalled. Defaults to 30
+	StalledAfterDays int `json:"stalled_after_days"`
+	// Mentors with more active students than this are flagged as overloaded on the caseload view. Defaults to 5
+	MaxStudentsPerMentor int `json:"max_students_per_mentor"`
 }
 
 type ConfigFacilityTrainingDiscord struct {
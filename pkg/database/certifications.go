@@ -17,9 +17,13 @@
 package database
 
 import (
+	"strings"
 	"sync"
 
+	"gorm.io/gorm"
+
 	"github.com/adh-partnership/api/pkg/database/models"
+	"github.com/adh-partnership/api/pkg/database/models/constants"
 )
 
 var (
@@ -34,8 +38,23 @@ func GetCertifications() []models.Certification {
 		certs := []models.Certification{}
 		if err := DB.Model(&models.Certification{}).Find(&certs).Error; err != nil {
 			log.Errorf("Error getting certifications: %v", err)
+			mutex.Unlock()
+			return nil
+		}
+		prerequisites, err := FindCertificationPrerequisites(DB, "")
+		if err != nil {
+			log.Errorf("Error getting certification prerequisites: %v", err)
+			mutex.Unlock()
 			return nil
 		}
+		for i := range certs {
+			certs[i].Prerequisites = []*models.CertificationPrerequisite{}
+			for _, p := range prerequisites {
+				if p.Certification == certs[i].Name {
+					certs[i].Prerequisites = append(certs[i].Prerequisites, p)
+				}
+			}
+		}
 		certCache = certs
 		log.Infof("Populated certifications cache with %d entries: %+v", len(certCache), certCache)
 		mutex.Unlock()
@@ -64,3 +83,73 @@ func ValidCertification(key string) bool {
 
 	return false
 }
+
+// FindUserCertificationValue returns the user's value for a certification, none if it has not been set
+func FindUserCertificationValue(cid uint, name string) (string, error) {
+	var certs []*models.UserCertification
+	if err := DB.Where(models.UserCertification{CID: cid, Name: name}).Limit(1).Find(&certs).Error; err != nil {
+		return "", err
+	}
+	if len(certs) == 0 {
+		return constants.CertificationNone, nil
+	}
+
+	return certs[0].Value, nil
+}
+
+// ChangeUserCertification sets change.CID's certification to change.NewValue and records the change, filling in the
+// old value. Setting the value it already has is a no-op and is not recorded. Unless change.Override is set, with a
+// reason, a *PrerequisiteError is returned if the certification's prerequisites are not met. Pass a transaction as
+// tx to make it part of a larger change.
+func ChangeUserCertification(tx *gorm.DB, change *models.CertificationChange) error {
+	var certs []*models.UserCertification
+	if err := tx.Where(models.UserCertification{CID: change.CID, Name: change.Certification}).Find(&certs).Error; err != nil {
+		return err
+	}
+
+	change.OldValue = constants.CertificationNone
+	if len(certs) > 0 {
+		change.OldValue = certs[0].Value
+	}
+	if change.OldValue == change.NewValue {
+		return nil
+	}
+
+	if change.Override {
+		if strings.TrimSpace(change.Reason) == "" {
+			return ErrOverrideReasonRequired
+		}
+	} else if err := checkCertificationChange(tx, change); err != nil {
+		return err
+	}
+
+	if len(certs) == 0 {
+		if err := tx.Create(&models.UserCertification{CID: change.CID, Name: change.Certification, Value: change.NewValue}).Error; err != nil {
+			return err
+		}
+	} else {
+		for _, cert := range certs {
+			cert.Value = change.NewValue
+			if err := tx.Save(cert).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	return tx.Omit("Actor").Create(change).Error
+}
+
+// FindCertificationChanges returns a user's certification history, newest first. If certification is not empty, only
+// changes to that certification are returned.
+func FindCertificationChanges(cid uint, certification string) ([]*models.CertificationChange, error) {
+	var changes []*models.CertificationChange
+	tx := DB.Preload("Actor").Where(models.CertificationChange{CID: cid}).Order("created_at desc, id desc")
+	if certification != "" {
+		tx = tx.Where(models.CertificationChange{Certification: certification})
+	}
+	if err := tx.Find(&changes).Error; err != nil {
+		return nil, err
+	}
+
+	return changes, nil
+}
@@ -0,0 +1,200 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/adh-partnership/api/pkg/database/models"
+	"github.com/adh-partnership/api/pkg/database/models/constants"
+)
+
+func TestChangeUserCertificationRecordsChange(t *testing.T) {
+	conn := &fakeConn{}
+	change := &models.CertificationChange{
+		CID:           1,
+		Certification: "tower",
+		NewValue:      constants.CertificationTraining,
+		Source:        "manual",
+	}
+
+	assert.NoError(t, ChangeUserCertification(openFakeDB(t, conn), change))
+	assert.Equal(t, constants.CertificationNone, change.OldValue)
+	assert.Len(t, conn.execs("INSERT INTO `user_certifications`"), 1)
+	changes := conn.execs("INSERT INTO `certification_changes`")
+	if assert.Len(t, changes, 1) {
+		assert.Subset(t, changes[0].args, []driver.Value{"tower", constants.CertificationNone, constants.CertificationTraining, "manual"})
+	}
+}
+
+func TestChangeUserCertificationUnchanged(t *testing.T) {
+	conn := &fakeConn{rows: []fakeRow{{"id": int64(1), "cid": int64(1), "name": "tower", "value": constants.CertificationTraining}}}
+	change := &models.CertificationChange{
+		CID:           1,
+		Certification: "tower",
+		NewValue:      constants.CertificationTraining,
+		Source:        "manual",
+	}
+
+	assert.NoError(t, ChangeUserCertification(openFakeDB(t, conn), change))
+	assert.Equal(t, constants.CertificationTraining, change.OldValue)
+	assert.Empty(t, conn.execs(""))
+}
+
+func TestChangeUserCertificationStoresActorAndReason(t *testing.T) {
+	conn := &fakeConn{rows: []fakeRow{{"id": int64(1), "cid": int64(1), "name": "tower", "value": constants.CertificationSolo}}}
+	actor := uint(42)
+	change := &models.CertificationChange{
+		CID:           1,
+		Certification: "tower",
+		NewValue:      constants.CertificationCertified,
+		Source:        "manual",
+		ActorID:       &actor,
+		Reason:        "Passed the OTS on another ARTCC's rubric",
+		Override:      true,
+	}
+
+	assert.NoError(t, ChangeUserCertification(openFakeDB(t, conn), change))
+	assert.Equal(t, constants.CertificationSolo, change.OldValue)
+	assert.Len(t, conn.execs("UPDATE `user_certifications`"), 1)
+	changes := conn.execs("INSERT INTO `certification_changes`")
+	if assert.Len(t, changes, 1) {
+		assert.Subset(t, changes[0].args, []driver.Value{int64(42), "Passed the OTS on another ARTCC's rubric", true})
+	}
+}
+
+func TestChangeUserCertificationOverrideRequiresReason(t *testing.T) {
+	conn := &fakeConn{}
+	change := &models.CertificationChange{
+		CID:           1,
+		Certification: "tower",
+		NewValue:      constants.CertificationCertified,
+		Source:        "manual",
+		Override:      true,
+	}
+
+	assert.ErrorIs(t, ChangeUserCertification(openFakeDB(t, conn), change), ErrOverrideReasonRequired)
+	assert.Empty(t, conn.execs(""))
+}
+
+func openFakeDB(t *testing.T, conn *fakeConn) *gorm.DB {
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      sql.OpenDB(conn),
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{Logger: logger.Discard, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatalf("Error opening fake database: %s", err)
+	}
+
+	return db
+}
+
+type fakeRow map[string]driver.Value
+
+type fakeStatement struct {
+	query string
+	args  []driver.Value
+}
+
+// fakeConn is a database/sql connection that answers every query with rows and records every other statement, so
+// tests can see what would have been written without a database.
+type fakeConn struct {
+	rows       []fakeRow
+	statements []fakeStatement
+}
+
+// execs returns the recorded statements starting with prefix
+func (c *fakeConn) execs(prefix string) []fakeStatement {
+	var statements []fakeStatement
+	for _, s := range c.statements {
+		if strings.HasPrefix(s.query, prefix) {
+			statements = append(statements, s)
+		}
+	}
+
+	return statements
+}
+
+func (c *fakeConn) Connect(context.Context) (driver.Conn, error) { return c, nil }
+func (c *fakeConn) Driver() driver.Driver                        { return nil }
+func (c *fakeConn) Close() error                                 { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)                    { return c, nil }
+func (c *fakeConn) Commit() error                                { return nil }
+func (c *fakeConn) Rollback() error                              { return nil }
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepared statements are not supported")
+}
+
+func (c *fakeConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	statement := fakeStatement{query: query}
+	for _, arg := range args {
+		statement.args = append(statement.args, arg.Value)
+	}
+	c.statements = append(c.statements, statement)
+
+	return fakeResult(len(c.statements)), nil
+}
+
+func (c *fakeConn) QueryContext(context.Context, string, []driver.NamedValue) (driver.Rows, error) {
+	var columns []string
+	for _, row := range c.rows {
+		for column := range row {
+			columns = append(columns, column)
+		}
+		break
+	}
+
+	return &fakeRows{columns: columns, rows: c.rows}, nil
+}
+
+// fakeResult reports one affected row, with the statement's number as the inserted ID
+type fakeResult int64
+
+func (r fakeResult) LastInsertId() (int64, error) { return int64(r), nil }
+func (r fakeResult) RowsAffected() (int64, error) { return 1, nil }
+
+type fakeRows struct {
+	columns []string
+	rows    []fakeRow
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	for i, column := range r.columns {
+		dest[i] = r.rows[0][column]
+	}
+	r.rows = r.rows[1:]
+
+	return nil
+}
@@ -28,11 +28,28 @@ type EventRequest struct {
 	Banner      string     `json:"banner"`
 	StartDate   *time.Time `json:"start_date"`
 	EndDate     *time.Time `json:"end_date"`
+	// Signups are not accepted or changed after this time, nil leaves it unchanged and the zero time clears it
+	SignupsCloseAt *time.Time `json:"signups_close_at"`
+	// Signups beyond the cap are waitlisted, 0 for no cap and nil leaves it unchanged
+	SignupCap *int `json:"signup_cap"`
+	// Partner facilities co-hosting the event, nil leaves them unchanged
+	Facilities []string `json:"facilities"`
 }
 
 type EventPositionRequest struct {
 	Position string `json:"position"`
 	UserID   uint   `json:"cid"`
+	// Locked positions keep their controller when the assignment solver runs, nil leaves it unchanged
+	Locked *bool `json:"locked"`
+	// Certifications needed to work the position, nil leaves them unchanged
+	Certifications []string `json:"certifications"`
+	// Short name of the minimum rating needed to work the position, nil leaves it unchanged and empty clears it
+	MinRating *string `json:"min_rating"`
+	// Assign the controller even if they are not eligible for the position
+	Override bool `json:"override"`
+	// Facility staffing the position, the host or a co-hosting facility. Empty leaves it unchanged and defaults to
+	// the host for new positions
+	Facility string `json:"facility"`
 }
 
 type EventSignupRequest struct {
@@ -40,6 +57,55 @@ type EventSignupRequest struct {
 	Choice2 string `json:"choice2"`
 	Choice3 string `json:"choice3"`
 	Notes   string `json:"notes"`
+	// Window the controller is available for, open ended where nil
+	AvailableFrom  *time.Time `json:"available_from"`
+	AvailableUntil *time.Time `json:"available_until"`
+}
+
+type EventShiftRequest struct {
+	StartsAt *time.Time `json:"starts_at"`
+	EndsAt   *time.Time `json:"ends_at"`
+	UserID   uint       `json:"cid"`
+	// Assign the controller even if they are not eligible for the position or not available for the shift
+	Override bool `json:"override"`
+}
+
+type EventNoShowRequest struct {
+	UserID   uint   `json:"cid"`
+	Position string `json:"position"`
+	// Shift of the position the controller missed, for positions split into shifts
+	ShiftID *uint  `json:"shift_id"`
+	Notes   string `json:"notes"`
+}
+
+type EventTemplateRequest struct {
+	Title       string                          `json:"title"`
+	Description string                          `json:"description"`
+	Banner      string                          `json:"banner"`
+	Positions   []*EventTemplatePositionRequest `json:"positions"`
+	// Empty for templates that do not recur, otherwise weekly or monthly
+	Frequency string `json:"frequency"`
+	// Number of weeks or months between events, defaults to 1
+	Every int `json:"every"`
+	// Day of the week events are on, such as friday
+	Weekday string `json:"weekday"`
+	// Week of the month monthly events are on, 1 to 4 or -1 for the last
+	WeekOfMonth int `json:"week_of_month"`
+	// Zulu time of day events start at as HH:MM
+	StartTime       string     `json:"start_time"`
+	DurationMinutes int        `json:"duration_minutes"`
+	StartsOn        *time.Time `json:"starts_on"`
+	EndsOn          *time.Time `json:"ends_on"`
+}
+
+type EventTemplatePositionRequest struct {
+	Position string `json:"position"`
+	// Short name of the minimum rating needed to work the position, empty for none
+	MinRating string `json:"min_rating"`
+}
+
+type EventFromTemplateRequest struct {
+	StartDate *time.Time `json:"start_date"`
 }
 
 type EventsResponse struct {
@@ -51,8 +117,19 @@ type EventsResponse struct {
 	EndDate     time.Time                `json:"end_date"`
 	Positions   []*EventPositionResponse `json:"positions"`
 	Signups     []*EventSignupResponse   `json:"signups"`
-	CreatedAt   time.Time                `json:"created_at"`
-	UpdatedAt   time.Time                `json:"updated_at"`
+	// Partner facilities co-hosting the event
+	Facilities []string `json:"facilities"`
+	// Positions the requesting controller can work, only set for logged in users
+	EligiblePositions []string `json:"eligible_positions,omitempty"`
+	// Set while an unpublished roster proposed by the assignment solver exists
+	AssignmentDraftAt      *time.Time `json:"assignment_draft_at"`
+	AssignmentsPublishedAt *time.Time `json:"assignments_published_at"`
+	AnnouncedAt            *time.Time `json:"announced_at"`
+	TemplateID             *uint      `json:"template_id"`
+	SignupsCloseAt         *time.Time `json:"signups_close_at"`
+	SignupCap              int        `json:"signup_cap"`
+	CreatedAt              time.Time  `json:"created_at"`
+	UpdatedAt              time.Time  `json:"updated_at"`
 }
 
 type EventPositionResponse struct {
@@ -60,6 +137,160 @@ type EventPositionResponse struct {
 	Position string        `json:"position"`
 	UserID   *uint         `json:"cid"`
 	User     *UserResponse `json:"user"`
+	Locked   bool          `json:"locked"`
+	Facility string        `json:"facility"`
+	// Partner controller assigned through the shared roster
+	ExternalCID  *uint  `json:"external_cid"`
+	ExternalName string `json:"external_name"`
+
+	Certifications []string              `json:"certifications"`
+	MinRatingID    int                   `json:"min_rating_id"`
+	Shifts         []*EventShiftResponse `json:"shifts"`
+}
+
+type EventShiftResponse struct {
+	ID       uint          `json:"id"`
+	StartsAt time.Time     `json:"starts_at"`
+	EndsAt   time.Time     `json:"ends_at"`
+	UserID   *uint         `json:"cid"`
+	User     *UserResponse `json:"user"`
+	// Partner controller assigned through the shared roster
+	ExternalCID  *uint  `json:"external_cid"`
+	ExternalName string `json:"external_name"`
+}
+
+type EventAssignmentDraft struct {
+	EventID   uint                       `json:"event_id"`
+	DraftedAt *time.Time                 `json:"drafted_at"`
+	Positions []*EventAssignmentResponse `json:"positions"`
+	Filled    int                        `json:"filled"`
+	Unfilled  int                        `json:"unfilled"`
+	// Number of controllers that got their first, second and third choice
+	ByChoice map[int]int `json:"by_choice"`
+	Visitors int         `json:"visitors"`
+	Signups  int         `json:"signups"`
+}
+
+type EventAssignmentResponse struct {
+	PositionID uint          `json:"position_id"`
+	ShiftID    uint          `json:"shift_id,omitempty"`
+	StartsAt   *time.Time    `json:"starts_at,omitempty"`
+	EndsAt     *time.Time    `json:"ends_at,omitempty"`
+	Position   string        `json:"position"`
+	Locked     bool          `json:"locked"`
+	UserID     *uint         `json:"cid"`
+	User       *UserResponse `json:"user"`
+	// Which of the controller's choices the position was, 0 if they did not ask for it
+	Choice int `json:"choice"`
+}
+
+type EventAttendanceResponse struct {
+	EventID     uint                    `json:"event_id"`
+	Assignments []*EventAttendanceEntry `json:"assignments"`
+	Controllers []*EventAttendanceTotal `json:"controllers"`
+	// Assignments nobody worked, whether or not the no show has been recorded yet
+	NoShows []*EventAttendanceEntry `json:"no_shows"`
+}
+
+type EventAttendanceEntry struct {
+	PositionID uint          `json:"position_id"`
+	ShiftID    uint          `json:"shift_id,omitempty"`
+	Position   string        `json:"position"`
+	StartsAt   time.Time     `json:"starts_at"`
+	EndsAt     time.Time     `json:"ends_at"`
+	UserID     uint          `json:"cid"`
+	User       *UserResponse `json:"user"`
+	// One of attended, late, left_early, no_show or different_position
+	Status        string     `json:"status"`
+	WorkedMinutes int        `json:"worked_minutes"`
+	LoggedOnAt    *time.Time `json:"logged_on_at"`
+	LoggedOffAt   *time.Time `json:"logged_off_at"`
+	// Callsigns other than the assigned position the controller worked during the assignment
+	OtherPositions []string `json:"other_positions"`
+	// Set once the no show has been recorded on the controller's record
+	NoShowID *uint `json:"no_show_id"`
+}
+
+type EventAttendanceTotal struct {
+	UserID            uint          `json:"cid"`
+	User              *UserResponse `json:"user"`
+	Assignments       int           `json:"assignments"`
+	Attended          int           `json:"attended"`
+	Late              int           `json:"late"`
+	LeftEarly         int           `json:"left_early"`
+	NoShows           int           `json:"no_shows"`
+	DifferentPosition int           `json:"different_position"`
+	WorkedMinutes     int           `json:"worked_minutes"`
+}
+
+type EventTrafficResponse struct {
+	EventID uint `json:"event_id"`
+	// ICAO identifiers of the airports the event's positions are at
+	Airports []string      `json:"airports"`
+	Event    *EventTraffic `json:"event"`
+	// The same window one week before the event
+	Baseline *EventTraffic `json:"baseline"`
+	// False when the event was created after its baseline window began, so the baseline is incomplete
+	BaselineRecorded bool `json:"baseline_recorded"`
+}
+
+type EventTraffic struct {
+	StartsAt             time.Time                       `json:"starts_at"`
+	EndsAt               time.Time                       `json:"ends_at"`
+	Departures           int                             `json:"departures"`
+	Arrivals             int                             `json:"arrivals"`
+	UniqueCallsigns      int                             `json:"unique_callsigns"`
+	PeakHourlyDepartures int                             `json:"peak_hourly_departures"`
+	PeakHourlyArrivals   int                             `json:"peak_hourly_arrivals"`
+	PeakHourlyMovements  int                             `json:"peak_hourly_movements"`
+	Airports             map[string]*EventAirportTraffic `json:"airports"`
+}
+
+type EventAirportTraffic struct {
+	Departures      int `json:"departures"`
+	Arrivals        int `json:"arrivals"`
+	UniqueCallsigns int `json:"unique_callsigns"`
+}
+
+// SharedEventResponse is an event as co-hosting facilities see it through the shared roster
+type SharedEventResponse struct {
+	ID           uint      `json:"id"`
+	Title        string    `json:"title"`
+	Description  string    `json:"description"`
+	Banner       string    `json:"banner"`
+	StartDate    time.Time `json:"start_date"`
+	EndDate      time.Time `json:"end_date"`
+	HostFacility string    `json:"host_facility"`
+	// Co-hosting facilities, without the host
+	Facilities []string                       `json:"facilities"`
+	Positions  []*SharedEventPositionResponse `json:"positions"`
+}
+
+type SharedEventPositionResponse struct {
+	ID       uint   `json:"id"`
+	Position string `json:"position"`
+	Facility string `json:"facility"`
+	// Controller assigned to the position, whether from the host or a partner roster
+	CID    *uint                       `json:"cid"`
+	Name   string                      `json:"name"`
+	Shifts []*SharedEventShiftResponse `json:"shifts"`
+}
+
+type SharedEventShiftResponse struct {
+	ID       uint      `json:"id"`
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+	CID      *uint     `json:"cid"`
+	Name     string    `json:"name"`
+}
+
+// SharedAssignmentRequest assigns a partner controller to a position, or one of its shifts, tagged with the
+// partner's facility. A CID of 0 clears the assignment.
+type SharedAssignmentRequest struct {
+	PositionID uint   `json:"position_id"`
+	ShiftID    uint   `json:"shift_id"`
+	CID        uint   `json:"cid"`
+	Name       string `json:"name"`
 }
 
 type EventSignupResponse struct {
@@ -70,6 +301,11 @@ type EventSignupResponse struct {
 	Notes   string        `json:"notes"`
 	UserID  *uint         `json:"cid"`
 	User    *UserResponse `json:"user"`
+
+	AvailableFrom  *time.Time `json:"available_from"`
+	AvailableUntil *time.Time `json:"available_until"`
+	// Waitlisted signups are not considered for assignments until promoted
+	Waitlisted bool `json:"waitlisted"`
 }
 
 func PatchEventRequest(base *models.Event, patch EventRequest) *models.Event {
@@ -88,6 +324,15 @@ func PatchEventRequest(base *models.Event, patch EventRequest) *models.Event {
 	if patch.EndDate != nil {
 		base.EndDate = *patch.EndDate
 	}
+	if patch.SignupsCloseAt != nil {
+		base.SignupsCloseAt = patch.SignupsCloseAt
+		if patch.SignupsCloseAt.IsZero() {
+			base.SignupsCloseAt = nil
+		}
+	}
+	if patch.SignupCap != nil {
+		base.SignupCap = *patch.SignupCap
+	}
 	return base
 }
 
@@ -110,11 +355,27 @@ func ConvEventToEventsResponse(event *models.Event) *EventsResponse {
 		EndDate:     event.EndDate,
 		Positions:   ConvEventPositionsToEventPositionResponse(event.Positions),
 		Signups:     ConvEventSignupsToEventSignupResponse(event.Signups),
-		CreatedAt:   event.CreatedAt,
-		UpdatedAt:   event.UpdatedAt,
+		Facilities:  ConvEventFacilitiesToStrings(event.Facilities),
+
+		AssignmentDraftAt:      event.AssignmentDraftAt,
+		AssignmentsPublishedAt: event.AssignmentsPublishedAt,
+		AnnouncedAt:            event.AnnouncedAt,
+		TemplateID:             event.TemplateID,
+		SignupsCloseAt:         event.SignupsCloseAt,
+		SignupCap:              event.SignupCap,
+		CreatedAt:              event.CreatedAt,
+		UpdatedAt:              event.UpdatedAt,
 	}
 }
 
+func ConvEventFacilitiesToStrings(facilities []*models.EventFacility) []string {
+	res := []string{}
+	for _, facility := range facilities {
+		res = append(res, facility.Facility)
+	}
+	return res
+}
+
 func ConvEventPositionsToEventPositionResponse(positions []*models.EventPosition) []*EventPositionResponse {
 	res := []*EventPositionResponse{}
 	for _, position := range positions {
@@ -128,6 +389,33 @@ func ConvEventPositionToEventPositionResponse(position *models.EventPosition) *E
 		ID:       position.ID,
 		Position: position.Position,
 		UserID:   position.UserID,
+		Locked:   position.Locked,
+		Facility: position.Facility,
+
+		ExternalCID:  position.ExternalCID,
+		ExternalName: position.ExternalName,
+
+		Certifications: []string{},
+		MinRatingID:    position.MinRatingID,
+		Shifts:         []*EventShiftResponse{},
+	}
+	for _, cert := range position.Certifications {
+		pos.Certifications = append(pos.Certifications, cert.Certification)
+	}
+	for _, shift := range position.Shifts {
+		s := &EventShiftResponse{
+			ID:       shift.ID,
+			StartsAt: shift.StartsAt,
+			EndsAt:   shift.EndsAt,
+			UserID:   shift.UserID,
+
+			ExternalCID:  shift.ExternalCID,
+			ExternalName: shift.ExternalName,
+		}
+		if shift.User != nil {
+			s.User = ConvUserToUserResponse(shift.User)
+		}
+		pos.Shifts = append(pos.Shifts, s)
 	}
 	if position.User != nil {
 		pos.User = ConvUserToUserResponse(position.User)
@@ -152,6 +440,10 @@ func ConvEventSignupToEventSignupResponse(signup *models.EventSignup) *EventSign
 		Choice3: signup.Choice3,
 		Notes:   signup.Notes,
 		UserID:  signup.UserID,
+
+		AvailableFrom:  signup.AvailableFrom,
+		AvailableUntil: signup.AvailableUntil,
+		Waitlisted:     signup.Waitlisted,
 	}
 
 	if signup.User != nil {
@@ -160,3 +452,50 @@ func ConvEventSignupToEventSignupResponse(signup *models.EventSignup) *EventSign
 
 	return sup
 }
+
+func ConvEventToSharedEventResponse(event *models.Event, host string) *SharedEventResponse {
+	res := &SharedEventResponse{
+		ID:           event.ID,
+		Title:        event.Title,
+		Description:  event.Description,
+		Banner:       event.Banner,
+		StartDate:    event.StartDate,
+		EndDate:      event.EndDate,
+		HostFacility: host,
+		Facilities:   ConvEventFacilitiesToStrings(event.Facilities),
+		Positions:    []*SharedEventPositionResponse{},
+	}
+
+	for _, position := range event.Positions {
+		pos := &SharedEventPositionResponse{
+			ID:       position.ID,
+			Position: position.Position,
+			Facility: position.Facility,
+			Shifts:   []*SharedEventShiftResponse{},
+		}
+		if pos.Facility == "" {
+			pos.Facility = host
+		}
+		pos.CID, pos.Name = sharedAssignee(position.User, position.ExternalCID, position.ExternalName)
+		for _, shift := range position.Shifts {
+			s := &SharedEventShiftResponse{
+				ID:       shift.ID,
+				StartsAt: shift.StartsAt,
+				EndsAt:   shift.EndsAt,
+			}
+			s.CID, s.Name = sharedAssignee(shift.User, shift.ExternalCID, shift.ExternalName)
+			pos.Shifts = append(pos.Shifts, s)
+		}
+		res.Positions = append(res.Positions, pos)
+	}
+
+	return res
+}
+
+func sharedAssignee(user *models.User, externalCID *uint, externalName string) (*uint, string) {
+	if user != nil {
+		return &user.CID, user.FirstName + " " + user.LastName
+	}
+
+	return externalCID, externalName
+}
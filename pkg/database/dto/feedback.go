@@ -18,6 +18,7 @@ package dto
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/adh-partnership/api/pkg/database"
@@ -48,9 +49,80 @@ type FeedbackResponse struct {
 	Comments     string        `json:"comments"`
 	ContactEmail string        `json:"contact_email"`
 	CreatedAt    *time.Time    `json:"created_at"`
+	// Only included for moderators
+	Verification *FeedbackVerificationResponse `json:"verification,omitempty"`
 }
 
-func ConvertFeedbacktoResponse(feedback []*models.Feedback, includeEmail bool) []FeedbackResponse {
+type FeedbackVerificationResponse struct {
+	// verified, partial or unverified, empty if the feedback was never checked
+	Result     string     `json:"result"`
+	Confidence float64    `json:"confidence"`
+	Notes      []string   `json:"notes"`
+	VerifiedAt *time.Time `json:"verified_at"`
+}
+
+type FeedbackAnalyticsResponse struct {
+	From        time.Time                    `json:"from"`
+	To          time.Time                    `json:"to"`
+	Facility    FeedbackSummary              `json:"facility"`
+	Monthly     []*FeedbackMonthSummary      `json:"monthly"`
+	Positions   []*FeedbackPositionSummary   `json:"positions"`
+	Controllers []*FeedbackControllerSummary `json:"controllers"`
+	// Controllers with a recent spike in poor ratings
+	Outliers []*FeedbackOutlier `json:"outliers"`
+}
+
+type FeedbackSummaryResponse struct {
+	CID       uint                       `json:"cid"`
+	From      time.Time                  `json:"from"`
+	To        time.Time                  `json:"to"`
+	Summary   FeedbackSummary            `json:"summary"`
+	Monthly   []*FeedbackMonthSummary    `json:"monthly"`
+	Positions []*FeedbackPositionSummary `json:"positions"`
+}
+
+// FeedbackSummary totals a set of feedback. Rejected feedback counts towards the statuses but not the ratings.
+type FeedbackSummary struct {
+	Total   int            `json:"total"`
+	Ratings map[string]int `json:"ratings"`
+	// Average rating from 4 for excellent to 1 for poor, 0 without ratings
+	Score    float64 `json:"score"`
+	Pending  int     `json:"pending"`
+	Approved int     `json:"approved"`
+	Rejected int     `json:"rejected"`
+	// Share of reviewed feedback that was approved, 0 if none was reviewed
+	ApprovalRate float64 `json:"approval_rate"`
+}
+
+type FeedbackMonthSummary struct {
+	Month string `json:"month"`
+	FeedbackSummary
+}
+
+type FeedbackPositionSummary struct {
+	Prefix string `json:"prefix"`
+	FeedbackSummary
+}
+
+type FeedbackControllerSummary struct {
+	CID  uint   `json:"cid"`
+	Name string `json:"name"`
+	FeedbackSummary
+}
+
+// FeedbackOutlier is a controller whose share of poor ratings recently rose well above their usual share
+type FeedbackOutlier struct {
+	CID            uint    `json:"cid"`
+	Name           string  `json:"name"`
+	Recent         int     `json:"recent"`
+	RecentPoor     int     `json:"recent_poor"`
+	RecentPoorRate float64 `json:"recent_poor_rate"`
+	// Share of poor ratings before the recent window, 0 if the controller had none rated
+	BaselinePoorRate float64 `json:"baseline_poor_rate"`
+}
+
+// ConvertFeedbacktoResponse converts the feedback, including the contact email and verification for moderators
+func ConvertFeedbacktoResponse(feedback []*models.Feedback, moderator bool) []FeedbackResponse {
 	ret := []FeedbackResponse{}
 
 	for _, f := range feedback {
@@ -68,8 +140,9 @@ func ConvertFeedbacktoResponse(feedback []*models.Feedback, includeEmail bool) [
 			CreatedAt:  f.CreatedAt,
 		}
 
-		if includeEmail {
+		if moderator {
 			fdbk.ContactEmail = f.ContactEmail
+			fdbk.Verification = convertFeedbackVerification(f)
 		}
 
 		ret = append(ret, *fdbk)
@@ -78,7 +151,7 @@ func ConvertFeedbacktoResponse(feedback []*models.Feedback, includeEmail bool) [
 	return ret
 }
 
-func ConvertSingleFeedbacktoResponse(feedback *models.Feedback, includeEmail bool) *FeedbackResponse {
+func ConvertSingleFeedbacktoResponse(feedback *models.Feedback, moderator bool) *FeedbackResponse {
 	controller, _ := database.FindUserByCID(fmt.Sprint(feedback.Controller.CID))
 	submitter, _ := database.FindUserByCID(fmt.Sprint(feedback.Submitter.CID))
 	fdbk := &FeedbackResponse{
@@ -93,9 +166,24 @@ func ConvertSingleFeedbacktoResponse(feedback *models.Feedback, includeEmail boo
 		CreatedAt:  feedback.CreatedAt,
 	}
 
-	if includeEmail {
+	if moderator {
 		fdbk.ContactEmail = feedback.ContactEmail
+		fdbk.Verification = convertFeedbackVerification(feedback)
 	}
 
 	return fdbk
 }
+
+func convertFeedbackVerification(feedback *models.Feedback) *FeedbackVerificationResponse {
+	ret := &FeedbackVerificationResponse{
+		Result:     feedback.VerificationResult,
+		Confidence: feedback.VerificationConfidence,
+		Notes:      []string{},
+		VerifiedAt: feedback.VerifiedAt,
+	}
+	if feedback.VerificationNotes != "" {
+		ret.Notes = strings.Split(feedback.VerificationNotes, "\n")
+	}
+
+	return ret
+}
@@ -0,0 +1,69 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package dto
+
+import (
+	"time"
+
+	"github.com/adh-partnership/api/pkg/database/models"
+)
+
+type SoloEndorsementRequest struct {
+	CID           uint       `json:"cid"`
+	Certification string     `json:"certification"`
+	Position      string     `json:"position"`
+	Reason        string     `json:"reason"`
+	StartsAt      *time.Time `json:"starts_at"`
+	ExpiresAt     time.Time  `json:"expires_at"`
+}
+
+type SoloEndorsementRevokeRequest struct {
+	Reason string `json:"reason"`
+}
+
+// PublicSoloEndorsement is the view of an active solo endorsement shared with pilots and other facilities
+type PublicSoloEndorsement struct {
+	CID           uint      `json:"cid"`
+	FirstName     string    `json:"first_name"`
+	LastName      string    `json:"last_name"`
+	Rating        string    `json:"rating"`
+	Certification string    `json:"certification"`
+	Position      string    `json:"position"`
+	StartsAt      time.Time `json:"starts_at"`
+	ExpiresAt     time.Time `json:"expires_at"`
+}
+
+func ConvertSoloEndorsementsToPublicDTO(endorsements []*models.SoloEndorsement) []*PublicSoloEndorsement {
+	ret := []*PublicSoloEndorsement{}
+	for _, e := range endorsements {
+		p := &PublicSoloEndorsement{
+			CID:           e.StudentID,
+			Certification: e.Certification,
+			Position:      e.Position,
+			StartsAt:      e.StartsAt,
+			ExpiresAt:     e.ExpiresAt,
+		}
+		if e.Student != nil {
+			p.FirstName = e.Student.FirstName
+			p.LastName = e.Student.LastName
+			p.Rating = e.Student.Rating.Short
+		}
+		ret = append(ret, p)
+	}
+
+	return ret
+}
@@ -16,6 +16,12 @@
 
 package dto
 
+import (
+	"time"
+
+	"github.com/adh-partnership/api/pkg/database/models"
+)
+
 type StaffingRequest struct {
 	DepartureAirport string `json:"departureAirport" binding:"required"`
 	ArrivalAirport   string `json:"arrivalAirport" binding:"required"`
@@ -27,3 +33,73 @@ type StaffingRequest struct {
 	BannerURL        string `json:"bannerUrl"`
 	Comments         string `json:"comments"`
 }
+
+type StaffingRequestUpdate struct {
+	// One of new, accepted or declined, nil leaves it unchanged
+	Status     *string `json:"status"`
+	StaffNotes *string `json:"staff_notes"`
+}
+
+type StaffingConversionRequest struct {
+	// Defaults are built from the staffing request when left out
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	// Required unless the dates of the staffing request are RFC 3339 times
+	StartDate *time.Time `json:"start_date"`
+	EndDate   *time.Time `json:"end_date"`
+}
+
+// StaffingRequestResponse is a staffing request with the requester and the staff member that handled it limited to
+// their public details
+type StaffingRequestResponse struct {
+	ID               uint          `json:"id"`
+	CID              uint          `json:"cid"`
+	User             *UserResponse `json:"user"`
+	DepartureAirport string        `json:"departure_airport"`
+	ArrivalAirport   string        `json:"arrival_airport"`
+	StartDate        string        `json:"start_date"`
+	EndDate          string        `json:"end_date"`
+	Pilots           int           `json:"pilots"`
+	ContactInfo      string        `json:"contact_info"`
+	Organization     string        `json:"organization"`
+	BannerURL        string        `json:"banner_url"`
+	Comments         string        `json:"comments"`
+	Status           string        `json:"status"`
+	StaffNotes       string        `json:"staff_notes"`
+	HandledBy        *UserResponse `json:"handled_by"`
+	EventID          *uint         `json:"event_id"`
+	CreatedAt        time.Time     `json:"created_at"`
+	UpdatedAt        time.Time     `json:"updated_at"`
+}
+
+func ConvStaffingRequestToResponse(request *models.StaffingRequest) *StaffingRequestResponse {
+	return &StaffingRequestResponse{
+		ID:               request.ID,
+		CID:              request.UserID,
+		User:             ConvUserToUserResponse(request.User),
+		DepartureAirport: request.DepartureAirport,
+		ArrivalAirport:   request.ArrivalAirport,
+		StartDate:        request.StartDate,
+		EndDate:          request.EndDate,
+		Pilots:           request.Pilots,
+		ContactInfo:      request.ContactInfo,
+		Organization:     request.Organization,
+		BannerURL:        request.BannerURL,
+		Comments:         request.Comments,
+		Status:           request.Status,
+		StaffNotes:       request.StaffNotes,
+		HandledBy:        ConvUserToUserResponse(request.HandledBy),
+		EventID:          request.EventID,
+		CreatedAt:        request.CreatedAt,
+		UpdatedAt:        request.UpdatedAt,
+	}
+}
+
+func ConvStaffingRequestsToResponse(requests []*models.StaffingRequest) []*StaffingRequestResponse {
+	ret := []*StaffingRequestResponse{}
+	for _, request := range requests {
+		ret = append(ret, ConvStaffingRequestToResponse(request))
+	}
+
+	return ret
+}
@@ -23,11 +23,25 @@ import (
 )
 
 type TrainingNoteRequest struct {
-	Position    string    `json:"position"`
-	Type        string    `json:"type"`
-	Comments    string    `json:"comments"`
-	Duration    string    `json:"duration"`
-	SessionDate time.Time `json:"session_date"`
+	Position    string                         `json:"position"`
+	Type        string                         `json:"type"`
+	Comments    string                         `json:"comments"`
+	Duration    string                         `json:"duration"`
+	SessionDate time.Time                      `json:"session_date"`
+	Progress    []*TrainingNoteProgressRequest `json:"progress"`
+}
+
+type TrainingNoteProgressRequest struct {
+	ItemID   uint   `json:"item_id"`
+	Status   string `json:"status"`
+	Comments string `json:"comments"`
+}
+
+type SyllabusItemRequest struct {
+	Certification string `json:"certification"`
+	Order         uint   `json:"order"`
+	Title         string `json:"title"`
+	Description   string `json:"description"`
 }
 
 type TrainingRequestCreateRequest struct {
@@ -46,6 +60,16 @@ type TrainingRequestEditRequest struct {
 	End             string `json:"end"`
 }
 
+type TrainingAvailabilityRequest struct {
+	Type      string     `json:"type"`
+	Weekday   int        `json:"weekday"`
+	StartTime string     `json:"start_time"`
+	EndTime   string     `json:"end_time"`
+	Start     *time.Time `json:"start"`
+	End       *time.Time `json:"end"`
+	Notes     string     `json:"notes"`
+}
+
 type TrainingRequest struct {
 	ID              string                 `json:"id"`
 	Student         *UserResponse          `json:"student"`
@@ -67,6 +91,65 @@ type TrainingRequestSlot struct {
 	End   *time.Time `json:"end"`
 }
 
+type TrainingAvailability struct {
+	ID         uint          `json:"id"`
+	Instructor *UserResponse `json:"instructor"`
+	Type       string        `json:"type"`
+	Weekday    int           `json:"weekday"`
+	StartTime  string        `json:"start_time"`
+	EndTime    string        `json:"end_time"`
+	Start      *time.Time    `json:"start"`
+	End        *time.Time    `json:"end"`
+	Notes      string        `json:"notes"`
+}
+
+type TrainingMatch struct {
+	Instructor    *UserResponse `json:"instructor"`
+	Certification string        `json:"certification"`
+	Workload      int64         `json:"workload"`
+	Start         time.Time     `json:"start"`
+	End           time.Time     `json:"end"`
+}
+
+type TrainingDemand struct {
+	Position       string             `json:"position"`
+	Open           int                `json:"open"`
+	Unmatched      int                `json:"unmatched"`
+	RequestedHours float64            `json:"requested_hours"`
+	Requests       []*TrainingRequest `json:"unmatched_requests"`
+}
+
+type TrainingProgress struct {
+	Student           *UserResponse            `json:"student"`
+	NextCertification *CertificationProgress   `json:"next_certification"`
+	Certifications    []*CertificationProgress `json:"certifications"`
+	LastSession       *time.Time               `json:"last_session"`
+	Stalled           bool                     `json:"stalled"`
+}
+
+type CertificationProgress struct {
+	Certification string          `json:"certification"`
+	DisplayName   string          `json:"display_name"`
+	Value         string          `json:"value"`
+	Satisfactory  int             `json:"satisfactory"`
+	Total         int             `json:"total"`
+	Items         []*ItemProgress `json:"items"`
+}
+
+type ItemProgress struct {
+	Item       *models.SyllabusItem `json:"item"`
+	Status     string               `json:"status"`
+	Comments   string               `json:"comments"`
+	LastMarked *time.Time           `json:"last_marked"`
+}
+
+type StalledStudent struct {
+	Student          *UserResponse `json:"student"`
+	LastSession      *time.Time    `json:"last_session"`
+	DaysSinceSession int           `json:"days_since_session"`
+	InTraining       []string      `json:"in_training"`
+}
+
 func ConvertTrainingRequestToDTO(t *models.TrainingRequest) *TrainingRequest {
 	ret := &TrainingRequest{
 		ID:        t.ID.String(),
@@ -103,3 +186,130 @@ func ConvertTrainingRequestsToDTO(t []*models.TrainingRequest) []*TrainingReques
 	}
 	return res
 }
+
+func ConvertTrainingAvailabilityToDTO(a *models.TrainingAvailability) *TrainingAvailability {
+	return &TrainingAvailability{
+		ID:         a.ID,
+		Instructor: ConvUserToUserResponse(a.Instructor),
+		Type:       a.Type,
+		Weekday:    a.Weekday,
+		StartTime:  a.StartTime,
+		EndTime:    a.EndTime,
+		Start:      a.Start,
+		End:        a.End,
+		Notes:      a.Notes,
+	}
+}
+
+func ConvertTrainingAvailabilitiesToDTO(a []*models.TrainingAvailability) []*TrainingAvailability {
+	res := []*TrainingAvailability{}
+	for _, v := range a {
+		res = append(res, ConvertTrainingAvailabilityToDTO(v))
+	}
+	return res
+}
+
+// TrainingNoteSync is a training note with its VATUSA sync state, for training staff
+type TrainingNoteSync struct {
+	*models.TrainingNote
+	SyncStatus   string     `json:"sync_status"`
+	SyncAction   string     `json:"sync_action"`
+	SyncAttempts int        `json:"sync_attempts"`
+	SyncError    string     `json:"sync_error"`
+	NextSyncAt   *time.Time `json:"next_sync_at"`
+	SyncedAt     *time.Time `json:"synced_at"`
+}
+
+func ConvertTrainingNoteToSync(note *models.TrainingNote) *TrainingNoteSync {
+	return &TrainingNoteSync{
+		TrainingNote: note,
+		SyncStatus:   note.SyncStatus,
+		SyncAction:   note.SyncAction,
+		SyncAttempts: note.SyncAttempts,
+		SyncError:    note.SyncError,
+		NextSyncAt:   note.NextSyncAt,
+		SyncedAt:     note.SyncedAt,
+	}
+}
+
+func ConvertTrainingNotesToSync(notes []*models.TrainingNote) []*TrainingNoteSync {
+	res := []*TrainingNoteSync{}
+	for _, note := range notes {
+		res = append(res, ConvertTrainingNoteToSync(note))
+	}
+	return res
+}
+
+type OTSRubricRequest struct {
+	Name          string                      `json:"name"`
+	Position      string                      `json:"position"`
+	Certification string                      `json:"certification"`
+	AutoCertify   bool                        `json:"auto_certify"`
+	Categories    []*OTSRubricCategoryRequest `json:"categories"`
+}
+
+type OTSRubricCategoryRequest struct {
+	// ID of an existing category when updating a rubric, categories left out are removed
+	ID          uint   `json:"id"`
+	Order       uint   `json:"order"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Critical    bool   `json:"critical"`
+}
+
+type OTSEvaluationRequest struct {
+	TrainingNoteID uint                         `json:"training_note_id"`
+	RubricID       uint                         `json:"rubric_id"`
+	Result         string                       `json:"result"`
+	Comments       string                       `json:"comments"`
+	Scores         []*OTSEvaluationScoreRequest `json:"scores"`
+}
+
+type OTSEvaluationScoreRequest struct {
+	CategoryID uint   `json:"category_id"`
+	Score      string `json:"score"`
+	Comments   string `json:"comments"`
+}
+
+type PromotionRequestCreateRequest struct {
+	CID              uint   `json:"cid"`
+	Rating           string `json:"rating"`
+	Position         string `json:"position"`
+	Notes            string `json:"notes"`
+	TrainingNoteIDs  []uint `json:"training_note_ids"`
+	OTSEvaluationIDs []uint `json:"ots_evaluation_ids"`
+}
+
+type PromotionRequestReviewRequest struct {
+	Status      string `json:"status"`
+	ReviewNotes string `json:"review_notes"`
+}
+
+type MentorAssignmentRequest struct {
+	CID       uint   `json:"cid"`
+	MentorCID uint   `json:"mentor_cid"`
+	Notes     string `json:"notes"`
+}
+
+type MentorStudent struct {
+	AssignmentID         uint                   `json:"assignment_id"`
+	Student              *UserResponse          `json:"student"`
+	AssignedAt           time.Time              `json:"assigned_at"`
+	Notes                string                 `json:"notes"`
+	LatestNote           *models.TrainingNote   `json:"latest_note"`
+	CurrentCertification *CertificationProgress `json:"current_certification"`
+	OpenRequests         []*TrainingRequest     `json:"open_requests"`
+}
+
+type MentorCaseload struct {
+	Mentor     *UserResponse   `json:"mentor"`
+	Students   []*UserResponse `json:"students"`
+	Count      int             `json:"count"`
+	Overloaded bool            `json:"overloaded"`
+}
+
+type Caseload struct {
+	MaxStudentsPerMentor int               `json:"max_students_per_mentor"`
+	Mentors              []*MentorCaseload `json:"mentors"`
+	Unassigned           []*UserResponse   `json:"unassigned"`
+}
@@ -17,8 +17,13 @@
 package dto
 
 import (
+	"sort"
+
+	"gorm.io/gorm"
+
 	"github.com/adh-partnership/api/pkg/database"
 	"github.com/adh-partnership/api/pkg/database/models"
+	"github.com/adh-partnership/api/pkg/database/models/constants"
 )
 
 type UserResponse struct {
@@ -42,24 +47,26 @@ type UserResponse struct {
 }
 
 type UserResponseAdmin struct {
-	CID                  uint                                   `json:"cid" yaml:"cid" xml:"cid"`
-	FirstName            string                                 `json:"first_name" yaml:"first_name" xml:"first_name"`
-	LastName             string                                 `json:"last_name" yaml:"last_name" xml:"last_name"`
-	OperatingInitials    string                                 `json:"operating_initials" yaml:"operating_initials" xml:"operating_initials"`
-	ControllerType       string                                 `json:"controller_type" yaml:"controller_type" xml:"controller_type"`
-	Certifications       map[string]*UserResponseCertifications `json:"certifications" yaml:"certifications" xml:"certifications"`
-	RemovalReason        string                                 `json:"removal_reason" yaml:"removal_reason" xml:"removal_reason"`
-	Rating               string                                 `json:"rating" yaml:"rating" xml:"rating"`
-	Status               string                                 `json:"status" yaml:"status" xml:"status"`
-	Roles                []string                               `json:"roles" yaml:"roles" xml:"roles"`
-	Region               string                                 `json:"region" yaml:"region" xml:"region"`
-	Division             string                                 `json:"division" yaml:"division" xml:"division"`
-	Subdivision          string                                 `json:"subdivision" yaml:"subdivision" xml:"subdivision"`
-	DiscordID            string                                 `json:"discord_id" yaml:"discord_id" xml:"discord_id"`
-	ExemptedFromActivity *bool                                  `json:"exempted_from_activity" yaml:"exempted_from_activity" xml:"exempted_from_activity"`
-	RosterJoinDate       string                                 `json:"roster_join_date" yaml:"roster_join_date" xml:"roster_join_date"`
-	CreatedAt            string                                 `json:"created_at" yaml:"created_at" xml:"created_at"`
-	UpdatedAt            string                                 `json:"updated_at" yaml:"updated_at" xml:"updated_at"`
+	CID                   uint                                   `json:"cid" yaml:"cid" xml:"cid"`
+	FirstName             string                                 `json:"first_name" yaml:"first_name" xml:"first_name"`
+	LastName              string                                 `json:"last_name" yaml:"last_name" xml:"last_name"`
+	OperatingInitials     string                                 `json:"operating_initials" yaml:"operating_initials" xml:"operating_initials"`
+	ControllerType        string                                 `json:"controller_type" yaml:"controller_type" xml:"controller_type"`
+	Certifications        map[string]*UserResponseCertifications `json:"certifications" yaml:"certifications" xml:"certifications"`
+	CertificationReason   string                                 `json:"certification_reason" yaml:"certification_reason" xml:"certification_reason"`
+	CertificationOverride bool                                   `json:"certification_override" yaml:"certification_override" xml:"certification_override"`
+	RemovalReason         string                                 `json:"removal_reason" yaml:"removal_reason" xml:"removal_reason"`
+	Rating                string                                 `json:"rating" yaml:"rating" xml:"rating"`
+	Status                string                                 `json:"status" yaml:"status" xml:"status"`
+	Roles                 []string                               `json:"roles" yaml:"roles" xml:"roles"`
+	Region                string                                 `json:"region" yaml:"region" xml:"region"`
+	Division              string                                 `json:"division" yaml:"division" xml:"division"`
+	Subdivision           string                                 `json:"subdivision" yaml:"subdivision" xml:"subdivision"`
+	DiscordID             string                                 `json:"discord_id" yaml:"discord_id" xml:"discord_id"`
+	ExemptedFromActivity  *bool                                  `json:"exempted_from_activity" yaml:"exempted_from_activity" xml:"exempted_from_activity"`
+	RosterJoinDate        string                                 `json:"roster_join_date" yaml:"roster_join_date" xml:"roster_join_date"`
+	CreatedAt             string                                 `json:"created_at" yaml:"created_at" xml:"created_at"`
+	UpdatedAt             string                                 `json:"updated_at" yaml:"updated_at" xml:"updated_at"`
 }
 
 type UserResponseCertifications struct {
@@ -88,7 +95,7 @@ type FacilityStaffResponse struct {
 	Web              []*UserResponse `json:"web" yaml:"web" xml:"web"`
 	Instructor       []*UserResponse `json:"instructor" yaml:"instructor" xml:"instructor"`
 	Mentor           []*UserResponse `json:"mentor" yaml:"mentor" xml:"mentor"`
-	MentorInTraining []*UserResponse `json:"mit" yaml:"mit" xml: "mit"`
+	MentorInTraining []*UserResponse `json:"mit" yaml:"mit" xml:"mit"`
 }
 
 func ConvUserToUserResponse(user *models.User) *UserResponse {
@@ -177,7 +184,10 @@ const (
 	ErrInvalidStatus            = "invalid status"
 )
 
-func PatchUserFromUserResponse(user *models.User, userResponse UserResponseAdmin) []string {
+// PatchUserFromUserResponse applies the changes in userResponse to user. Certification changes are saved, in
+// certification order and recorded against actor, once everything else is valid; if any of them is rejected none are
+// saved. The rest of the changes are left for the caller to save.
+func PatchUserFromUserResponse(user *models.User, userResponse UserResponseAdmin, actor *uint) []string {
 	var errs []string
 
 	if len(userResponse.OperatingInitials) != 2 && userResponse.OperatingInitials != "" {
@@ -194,44 +204,27 @@ func PatchUserFromUserResponse(user *models.User, userResponse UserResponseAdmin
 		}
 	}
 
-	if userResponse.Certifications != nil {
-		userCerts, err := database.FindUserCertifications(user)
-		if err != nil {
-			errs = append(errs, err.Error())
-		} else {
-			for certName, certValue := range userResponse.Certifications {
-				if !database.ValidCertification(certName) {
-					errs = append(errs, ErrInvalidCertification)
-					continue
-				}
-
-				found := false
-				if _, ok := models.CertificationOptions[certValue.Value]; !ok {
-					errs = append(errs, ErrInvalidCertification)
-					continue
-				}
-
-				for _, cert := range userCerts {
-					if cert.Name == certName {
-						cert.Value = certValue.Value
-						if err := database.DB.Save(cert).Error; err != nil {
-							errs = append(errs, err.Error())
-						}
-						found = true
-					}
-				}
+	var changes []*models.CertificationChange
+	for certName, certValue := range userResponse.Certifications {
+		if !database.ValidCertification(certName) {
+			errs = append(errs, ErrInvalidCertification)
+			continue
+		}
 
-				if !found {
-					if err := database.DB.Create(&models.UserCertification{
-						CID:   user.CID,
-						Name:  certName,
-						Value: certValue.Value,
-					}).Error; err != nil {
-						errs = append(errs, err.Error())
-					}
-				}
-			}
+		if _, ok := models.CertificationOptions[certValue.Value]; !ok {
+			errs = append(errs, ErrInvalidCertification)
+			continue
 		}
+
+		changes = append(changes, &models.CertificationChange{
+			CID:           user.CID,
+			Certification: certName,
+			NewValue:      certValue.Value,
+			Source:        constants.CertificationChangeSourceRoster,
+			ActorID:       actor,
+			Reason:        userResponse.CertificationReason,
+			Override:      userResponse.CertificationOverride,
+		})
 	}
 
 	if userResponse.DiscordID != "" {
@@ -255,9 +248,38 @@ func PatchUserFromUserResponse(user *models.User, userResponse UserResponseAdmin
 		}
 	}
 
+	if len(errs) == 0 && len(changes) > 0 {
+		sortCertificationChanges(changes)
+		err := database.DB.Transaction(func(tx *gorm.DB) error {
+			for _, change := range changes {
+				if err := database.ChangeUserCertification(tx, change); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
 	return errs
 }
 
+// sortCertificationChanges orders changes by certification order, so a certification's prerequisites are set before it
+func sortCertificationChanges(changes []*models.CertificationChange) {
+	order := map[string]uint{}
+	for _, c := range database.GetCertifications() {
+		order[c.Name] = c.Order
+	}
+	sort.SliceStable(changes, func(i, j int) bool {
+		if order[changes[i].Certification] != order[changes[j].Certification] {
+			return order[changes[i].Certification] < order[changes[j].Certification]
+		}
+		return changes[i].Certification < changes[j].Certification
+	})
+}
+
 func GetUsersByRole(role string) ([]*UserResponse, error) {
 	users := []*UserResponse{}
 
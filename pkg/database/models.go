@@ -17,6 +17,7 @@
 package database
 
 import (
+	"fmt"
 	"strconv"
 	"time"
 
@@ -24,6 +25,7 @@ import (
 	"gorm.io/gorm/clause"
 
 	"github.com/adh-partnership/api/pkg/database/models"
+	"github.com/adh-partnership/api/pkg/database/models/constants"
 	"github.com/adh-partnership/api/pkg/logger"
 )
 
@@ -57,7 +59,8 @@ func RemoveRoleFromUser(user *models.User, role *models.Role) error {
 func GetEvents(limit int) ([]*models.Event, error) {
 	var events []*models.Event
 
-	c := DB.Preload(clause.Associations).Where("end_date > ?", time.Now()).Order("start_date asc")
+	c := DB.Preload(clause.Associations).Preload("Positions.Certifications").Preload("Positions.Shifts.User").
+		Where("end_date > ?", time.Now()).Order("start_date asc")
 	if limit > 0 {
 		c = c.Limit(limit)
 	}
@@ -76,9 +79,19 @@ func GetEvent(id string) (*models.Event, error) {
 		Preload("Signups.User.Rating").
 		Preload("Positions.User").
 		Preload("Positions.User.Rating").
+		Preload("Positions.ProposedUser.Rating").
+		Preload("Positions.Certifications").
+		Preload("Positions.Shifts", func(db *gorm.DB) *gorm.DB {
+			return db.Order("starts_at asc")
+		}).
+		Preload("Positions.Shifts.User.Rating").
+		Preload("Positions.Shifts.ProposedUser.Rating").
 		Preload(clause.Associations).
 		Where(models.Event{ID: atou(id)}).
 		First(event).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
 		return nil, err
 	}
 
@@ -326,6 +339,874 @@ func FindTrainingSessionRequestWithFilter(f *TrainingSessionRequestFilter) ([]*m
 	return requests, nil
 }
 
+// CountActiveTrainingSessionRequests returns the number of open or accepted training requests for a student
+func CountActiveTrainingSessionRequests(tx *gorm.DB, cid uint) (int64, error) {
+	var count int64
+	if err := tx.Model(&models.TrainingRequest{}).Where("student_id = ? AND status IN ?", cid, []string{
+		constants.TrainingSessionStatusOpen,
+		constants.TrainingSessionStatusAccepted,
+	}).Count(&count).Error; err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func FindTrainingAvailabilities(cid string) ([]*models.TrainingAvailability, error) {
+	var availability []*models.TrainingAvailability
+	tx := DB.Preload("Instructor.Rating").Preload(clause.Associations)
+	if cid != "" {
+		tx = tx.Where("instructor_id = ?", cid)
+	}
+	if err := tx.Find(&availability).Error; err != nil {
+		return nil, err
+	}
+
+	return availability, nil
+}
+
+func FindTrainingAvailabilityByID(id string) (*models.TrainingAvailability, error) {
+	availability := &models.TrainingAvailability{}
+	if err := DB.Preload(clause.Associations).First(availability, atou(id)).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return availability, nil
+}
+
+// CountScheduledTrainingSessions returns the number of upcoming accepted training sessions per instructor
+func CountScheduledTrainingSessions() (map[uint]int64, error) {
+	type result struct {
+		InstructorID uint
+		Total        int64
+	}
+	var results []result
+	if err := DB.Model(&models.TrainingRequest{}).
+		Select("instructor_id, COUNT(*) AS total").
+		Where("status = ? AND instructor_id IS NOT NULL AND `end` >= ?", constants.TrainingSessionStatusAccepted, time.Now()).
+		Group("instructor_id").
+		Scan(&results).Error; err != nil {
+		return nil, err
+	}
+
+	ret := map[uint]int64{}
+	for _, r := range results {
+		ret[r.InstructorID] = r.Total
+	}
+
+	return ret, nil
+}
+
+func FindSyllabusItems(certification string) ([]*models.SyllabusItem, error) {
+	var items []*models.SyllabusItem
+	tx := DB.Order("certification asc, `order` asc")
+	if certification != "" {
+		tx = tx.Where(models.SyllabusItem{Certification: certification})
+	}
+	if err := tx.Find(&items).Error; err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func FindSyllabusItemByID(id string) (*models.SyllabusItem, error) {
+	item := &models.SyllabusItem{}
+	if err := DB.First(item, atou(id)).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return item, nil
+}
+
+func FindTrainingNoteProgress(cid uint) ([]*models.TrainingNoteProgress, error) {
+	var progress []*models.TrainingNoteProgress
+	if err := DB.Where(models.TrainingNoteProgress{ControllerID: cid}).
+		Where("training_note_id IN (?)", DB.Model(&models.TrainingNote{}).Select("id")).
+		Order("created_at asc").Find(&progress).Error; err != nil {
+		return nil, err
+	}
+
+	return progress, nil
+}
+
+// FindStudentsInTraining returns users that are in training or solo on a certification or have an active training request
+func FindStudentsInTraining() ([]*models.User, error) {
+	var users []*models.User
+	if err := DB.Preload(clause.Associations).
+		Where("cid IN (?)", DB.Model(&models.UserCertification{}).Select("cid").Where("value IN ?", []string{
+			constants.CertificationTraining,
+			constants.CertificationSolo,
+		})).
+		Or("cid IN (?)", DB.Model(&models.TrainingRequest{}).Select("student_id").Where("status IN ?", []string{
+			constants.TrainingSessionStatusOpen,
+			constants.TrainingSessionStatusAccepted,
+		})).
+		Find(&users).Error; err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// FindLastTrainingSessions returns the date of the most recent attended training session per student
+func FindLastTrainingSessions() (map[uint]time.Time, error) {
+	type result struct {
+		ControllerID uint
+		Last         time.Time
+	}
+	var results []result
+	if err := DB.Model(&models.TrainingNote{}).
+		Select("controller_id, MAX(session_date) AS last").
+		Where("type != ? AND session_date IS NOT NULL", "no-show").
+		Group("controller_id").
+		Scan(&results).Error; err != nil {
+		return nil, err
+	}
+
+	ret := map[uint]time.Time{}
+	for _, r := range results {
+		ret[r.ControllerID] = r.Last
+	}
+
+	return ret, nil
+}
+
+// FindTrainingNotesToSync returns the pending training notes, including deleted ones, due to be pushed to VATUSA
+func FindTrainingNotesToSync(now time.Time) ([]*models.TrainingNote, error) {
+	var notes []*models.TrainingNote
+	if err := DB.Unscoped().Preload("Controller").Preload("Instructor").
+		Where("sync_status = ? AND (next_sync_at IS NULL OR next_sync_at <= ?)", constants.TrainingNoteSyncPending, now).
+		Order("next_sync_at asc").Find(&notes).Error; err != nil {
+		return nil, err
+	}
+
+	return notes, nil
+}
+
+// FindTrainingNotesBySyncStatus returns the training notes, including deleted ones, with the given sync status
+func FindTrainingNotesBySyncStatus(status string) ([]*models.TrainingNote, error) {
+	var notes []*models.TrainingNote
+	if err := DB.Unscoped().Preload("Controller.Rating").Preload("Instructor.Rating").
+		Where(models.TrainingNote{SyncStatus: status}).Order("updated_at desc").Find(&notes).Error; err != nil {
+		return nil, err
+	}
+
+	return notes, nil
+}
+
+// FindTrainingNoteForSync returns a training note by ID, including deleted ones
+func FindTrainingNoteForSync(id string) (*models.TrainingNote, error) {
+	note := &models.TrainingNote{}
+	if err := DB.Unscoped().Preload("Controller.Rating").Preload("Instructor.Rating").First(note, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return note, nil
+}
+
+// FindActiveSoloEndorsements returns the solo endorsements in effect at now
+func FindActiveSoloEndorsements(now time.Time) ([]*models.SoloEndorsement, error) {
+	var endorsements []*models.SoloEndorsement
+	if err := DB.Preload("Student.Rating").Preload("Instructor.Rating").
+		Where("ended_at IS NULL AND starts_at <= ? AND expires_at > ?", now, now).
+		Order("expires_at asc").Find(&endorsements).Error; err != nil {
+		return nil, err
+	}
+
+	return endorsements, nil
+}
+
+// FindOpenSoloEndorsements returns the solo endorsements that have not been revoked or lapsed yet
+func FindOpenSoloEndorsements() ([]*models.SoloEndorsement, error) {
+	var endorsements []*models.SoloEndorsement
+	if err := DB.Preload("Student").Preload("Instructor").Where("ended_at IS NULL").Find(&endorsements).Error; err != nil {
+		return nil, err
+	}
+
+	return endorsements, nil
+}
+
+func FindSoloEndorsements(cid string) ([]*models.SoloEndorsement, error) {
+	var endorsements []*models.SoloEndorsement
+	tx := DB.Preload("Student.Rating").Preload("Instructor.Rating").Order("starts_at desc")
+	if cid != "" {
+		tx = tx.Where("student_id = ?", cid)
+	}
+	if err := tx.Find(&endorsements).Error; err != nil {
+		return nil, err
+	}
+
+	return endorsements, nil
+}
+
+func FindSoloEndorsementByID(id string) (*models.SoloEndorsement, error) {
+	endorsement := &models.SoloEndorsement{}
+	if err := DB.Preload("Student.Rating").Preload("Instructor.Rating").First(endorsement, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return endorsement, nil
+}
+
+// EndSoloEndorsement marks the endorsement as ended and restores the certification value it replaced, if the
+// student is still solo on it. actor is recorded as the issuer of the restored value and is nil for automatic lapses.
+func EndSoloEndorsement(e *models.SoloEndorsement, reason, restore string, actor *uint, now time.Time) error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		e.EndedAt = &now
+		e.EndReason = reason
+		if err := tx.Omit(clause.Associations).Save(e).Error; err != nil {
+			return err
+		}
+
+		if restore == "" {
+			return nil
+		}
+
+		var current []*models.UserCertification
+		if err := tx.Where(models.UserCertification{CID: e.StudentID, Name: e.Certification}).Limit(1).Find(&current).Error; err != nil {
+			return err
+		}
+		if len(current) == 0 || current[0].Value != constants.CertificationSolo {
+			return nil
+		}
+
+		return ChangeUserCertification(tx, &models.CertificationChange{
+			CID:               e.StudentID,
+			Certification:     e.Certification,
+			NewValue:          restore,
+			Source:            constants.CertificationChangeSourceSolo,
+			ActorID:           actor,
+			Reason:            fmt.Sprintf("Solo endorsement %d %s", e.ID, reason),
+			SoloEndorsementID: &e.ID,
+		})
+	})
+}
+
+// StartSoloEndorsement makes the student solo for an endorsement that was issued for a later start, recording the
+// value they held when it started so it can be restored
+func StartSoloEndorsement(e *models.SoloEndorsement, current string) error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		e.PreviousValue = current
+		e.Pending = false
+		if err := tx.Model(&models.SoloEndorsement{}).Where("id = ?", e.ID).Updates(map[string]interface{}{
+			"previous_value": e.PreviousValue,
+			"pending":        false,
+		}).Error; err != nil {
+			return err
+		}
+
+		return ChangeUserCertification(tx, &models.CertificationChange{
+			CID:               e.StudentID,
+			Certification:     e.Certification,
+			NewValue:          constants.CertificationSolo,
+			Source:            constants.CertificationChangeSourceSolo,
+			ActorID:           &e.InstructorID,
+			Reason:            fmt.Sprintf("Solo endorsement %d on %s: %s", e.ID, e.Position, e.Reason),
+			SoloEndorsementID: &e.ID,
+		})
+	})
+}
+
+// FindTrainingNotesInRange returns the training notes with a session date between from and to, either may be nil
+func FindTrainingNotesInRange(from, to *time.Time) ([]*models.TrainingNote, error) {
+	var notes []*models.TrainingNote
+	tx := DB.Preload("Controller").Preload("Instructor").Where("session_date IS NOT NULL")
+	if from != nil {
+		tx = tx.Where("session_date >= ?", from)
+	}
+	if to != nil {
+		tx = tx.Where("session_date < ?", to)
+	}
+	if err := tx.Order("session_date asc").Find(&notes).Error; err != nil {
+		return nil, err
+	}
+
+	return notes, nil
+}
+
+// FindCertificationDates returns when each user was certified on each certification they hold, by CID and
+// certification name, from the latest change that certified them. Certifications granted before changes were
+// recorded are left out, as there is no date for them.
+func FindCertificationDates() (map[uint]map[string]time.Time, error) {
+	holders, err := FindCertificationHolders(DB, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := map[uint]map[string]time.Time{}
+	for cid, holder := range holders {
+		for name, value := range holder.Values {
+			since, ok := holder.CertifiedSince[name]
+			if !IsCertifiedValue(value) || !ok {
+				continue
+			}
+			if _, ok := ret[cid]; !ok {
+				ret[cid] = map[string]time.Time{}
+			}
+			ret[cid][name] = since
+		}
+	}
+
+	return ret, nil
+}
+
+func FindOTSRubrics(position string) ([]*models.OTSRubric, error) {
+	var rubrics []*models.OTSRubric
+	tx := DB.Preload("Categories", func(db *gorm.DB) *gorm.DB {
+		return db.Order("`order` asc")
+	}).Order("position asc, name asc")
+	if position != "" {
+		tx = tx.Where(models.OTSRubric{Position: position})
+	}
+	if err := tx.Find(&rubrics).Error; err != nil {
+		return nil, err
+	}
+
+	return rubrics, nil
+}
+
+func FindOTSRubricByID(id string) (*models.OTSRubric, error) {
+	rubric := &models.OTSRubric{}
+	if err := DB.Preload("Categories", func(db *gorm.DB) *gorm.DB {
+		return db.Order("`order` asc")
+	}).First(rubric, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return rubric, nil
+}
+
+func FindOTSEvaluations(cid uint) ([]*models.OTSEvaluation, error) {
+	var evaluations []*models.OTSEvaluation
+	if err := DB.Preload("Student.Rating").Preload("Instructor.Rating").Preload("Scores").Preload("CertificationChange").
+		Where(models.OTSEvaluation{StudentID: cid}).Order("created_at desc").Find(&evaluations).Error; err != nil {
+		return nil, err
+	}
+
+	return evaluations, nil
+}
+
+func FindOTSEvaluationByID(id string) (*models.OTSEvaluation, error) {
+	evaluation := &models.OTSEvaluation{}
+	if err := DB.Preload("Student.Rating").Preload("Instructor.Rating").Preload("Scores").Preload("CertificationChange").
+		First(evaluation, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return evaluation, nil
+}
+
+func promotionRequestPreloads() *gorm.DB {
+	return DB.Preload("Student.Rating").Preload("RecommendedBy.Rating").Preload("ReviewedBy.Rating").
+		Preload("FromRating").Preload("ToRating").Preload("TrainingNotes").Preload("OTSEvaluations")
+}
+
+func FindPromotionRequests(status, cid string) ([]*models.PromotionRequest, error) {
+	var requests []*models.PromotionRequest
+	tx := promotionRequestPreloads().Order("created_at desc")
+	if status != "" {
+		tx = tx.Where(models.PromotionRequest{Status: status})
+	}
+	if cid != "" {
+		tx = tx.Where("student_id = ?", cid)
+	}
+	if err := tx.Find(&requests).Error; err != nil {
+		return nil, err
+	}
+
+	return requests, nil
+}
+
+func FindPromotionRequestByID(id string) (*models.PromotionRequest, error) {
+	request := &models.PromotionRequest{}
+	if err := promotionRequestPreloads().First(request, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return request, nil
+}
+
+func mentorAssignmentPreloads() *gorm.DB {
+	return DB.Preload("Student.Rating").Preload("Mentor.Rating").Preload("AssignedBy.Rating")
+}
+
+// FindActiveMentorAssignments returns the mentor assignments that have not ended. If mentor is not 0, only that
+// mentor's assignments are returned.
+func FindActiveMentorAssignments(mentor uint) ([]*models.MentorAssignment, error) {
+	var assignments []*models.MentorAssignment
+	tx := mentorAssignmentPreloads().Where("ended_at IS NULL").Order("starts_at asc")
+	if mentor != 0 {
+		tx = tx.Where(models.MentorAssignment{MentorID: mentor})
+	}
+	if err := tx.Find(&assignments).Error; err != nil {
+		return nil, err
+	}
+
+	return assignments, nil
+}
+
+// FindMentorAssignments returns a student's mentor assignment history, newest first
+func FindMentorAssignments(cid uint) ([]*models.MentorAssignment, error) {
+	var assignments []*models.MentorAssignment
+	if err := mentorAssignmentPreloads().Where(models.MentorAssignment{StudentID: cid}).
+		Order("starts_at desc").Find(&assignments).Error; err != nil {
+		return nil, err
+	}
+
+	return assignments, nil
+}
+
+func FindMentorAssignmentByID(id string) (*models.MentorAssignment, error) {
+	assignment := &models.MentorAssignment{}
+	if err := mentorAssignmentPreloads().First(assignment, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return assignment, nil
+}
+
+// AssignMentor creates the assignment, ending the student's current assignment as reassigned. The ended
+// assignments are returned.
+func AssignMentor(a *models.MentorAssignment) ([]*models.MentorAssignment, error) {
+	var ended []*models.MentorAssignment
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("student_id = ? AND ended_at IS NULL", a.StudentID).Find(&ended).Error; err != nil {
+			return err
+		}
+		for _, e := range ended {
+			e.EndedAt = &a.StartsAt
+			e.EndReason = constants.MentorEndedReassigned
+			if err := tx.Omit(clause.Associations).Save(e).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Omit(clause.Associations).Create(a).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ended, nil
+}
+
+// FindUsersWithRoles returns the users holding any of the roles
+func FindUsersWithRoles(roles []string) ([]*models.User, error) {
+	var users []*models.User
+	if err := DB.Preload(clause.Associations).
+		Where("cid IN (?)", DB.Table("user_roles").Select("user_c_id").
+			Joins("JOIN roles ON roles.id = user_roles.role_id").Where("roles.name IN ?", roles)).
+		Find(&users).Error; err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// FindTrainingNotesForStudents returns the training notes of the students, grouped by student
+func FindTrainingNotesForStudents(cids []uint) (map[uint][]*models.TrainingNote, error) {
+	ret := map[uint][]*models.TrainingNote{}
+	if len(cids) == 0 {
+		return ret, nil
+	}
+
+	var notes []*models.TrainingNote
+	if err := DB.Preload("Instructor.Rating").Where("controller_id IN ?", cids).Find(&notes).Error; err != nil {
+		return nil, err
+	}
+	for _, n := range notes {
+		ret[n.ControllerID] = append(ret[n.ControllerID], n)
+	}
+
+	return ret, nil
+}
+
+// FindTrainingNoteProgressForStudents returns the syllabus progress recorded for each of the students, by CID, oldest
+// first
+func FindTrainingNoteProgressForStudents(cids []uint) (map[uint][]*models.TrainingNoteProgress, error) {
+	ret := map[uint][]*models.TrainingNoteProgress{}
+	if len(cids) == 0 {
+		return ret, nil
+	}
+
+	var progress []*models.TrainingNoteProgress
+	if err := DB.Where("controller_id IN ?", cids).
+		Where("training_note_id IN (?)", DB.Model(&models.TrainingNote{}).Select("id")).
+		Order("created_at asc").Find(&progress).Error; err != nil {
+		return nil, err
+	}
+	for _, p := range progress {
+		ret[p.ControllerID] = append(ret[p.ControllerID], p)
+	}
+
+	return ret, nil
+}
+
+// FindActiveTrainingSessionRequestsForStudents returns the open and accepted training requests of each of the
+// students, by CID
+func FindActiveTrainingSessionRequestsForStudents(cids []uint) (map[uint][]*models.TrainingRequest, error) {
+	ret := map[uint][]*models.TrainingRequest{}
+	if len(cids) == 0 {
+		return ret, nil
+	}
+
+	var requests []*models.TrainingRequest
+	if err := DB.Preload("Student.Rating").Preload("Instructor.Rating").Preload(clause.Associations).
+		Where("student_id IN ? AND status IN ?", cids, []string{
+			constants.TrainingSessionStatusOpen,
+			constants.TrainingSessionStatusAccepted,
+		}).Find(&requests).Error; err != nil {
+		return nil, err
+	}
+	for _, r := range requests {
+		ret[r.StudentID] = append(ret[r.StudentID], r)
+	}
+
+	return ret, nil
+}
+
+func FindUserByCalendarToken(token string) (*models.User, error) {
+	if token == "" {
+		return nil, nil
+	}
+
+	user := &models.User{}
+	if err := DB.Where(models.User{CalendarToken: token}).First(user).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// FindCalendarEvents returns the events ending after since, including those deleted after since so feeds can show
+// them as cancelled
+func FindCalendarEvents(since time.Time) ([]*models.Event, error) {
+	var events []*models.Event
+	if err := DB.Unscoped().
+		Where("end_date > ? AND (deleted_at IS NULL OR deleted_at > ?)", since, since).
+		Order("start_date asc").
+		Find(&events).Error; err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// FindUserEventPositions returns the positions the controller is assigned to, not split into shifts, in events
+// ending after since, including events deleted after since
+func FindUserEventPositions(cid uint, since time.Time) ([]*models.EventPosition, error) {
+	var positions []*models.EventPosition
+	if err := DB.Preload("Event", func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped()
+	}).
+		Joins("JOIN events ON events.id = event_positions.event_id").
+		Where("event_positions.user_id = ? AND events.end_date > ?", cid, since).
+		Where("events.deleted_at IS NULL OR events.deleted_at > ?", since).
+		Where("NOT EXISTS (?)", DB.Model(&models.EventShift{}).Select("1").Where("event_shifts.event_position_id = event_positions.id")).
+		Find(&positions).Error; err != nil {
+		return nil, err
+	}
+
+	return positions, nil
+}
+
+// FindUserEventShifts returns the shifts the controller is assigned to in events ending after since, including events
+// deleted after since
+func FindUserEventShifts(cid uint, since time.Time) ([]*models.EventShift, error) {
+	var shifts []*models.EventShift
+	if err := DB.Preload("EventPosition").Preload("EventPosition.Event", func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped()
+	}).
+		Joins("JOIN event_positions ON event_positions.id = event_shifts.event_position_id").
+		Joins("JOIN events ON events.id = event_positions.event_id").
+		Where("event_shifts.user_id = ? AND events.end_date > ?", cid, since).
+		Where("events.deleted_at IS NULL OR events.deleted_at > ?", since).
+		Find(&shifts).Error; err != nil {
+		return nil, err
+	}
+
+	return shifts, nil
+}
+
+// FindUserTrainingSessions returns the scheduled training sessions ending after since that the controller is the
+// student or instructor of, including cancelled ones
+func FindUserTrainingSessions(cid uint, since time.Time) ([]*models.TrainingRequest, error) {
+	var sessions []*models.TrainingRequest
+	if err := DB.Preload("Student").Preload("Instructor").
+		Where("(student_id = ? OR instructor_id = ?) AND `start` IS NOT NULL AND `end` > ?", cid, cid, since).
+		Where("status IN ?", []string{
+			constants.TrainingSessionStatusAccepted,
+			constants.TrainingSessionStatusCompleted,
+			constants.TrainingSessionStatusCancelled,
+		}).
+		Order("`start` asc").
+		Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// FindEventsStartingBefore returns the events that have not started yet and start before until, with their assigned
+// controllers
+func FindEventsStartingBefore(until time.Time) ([]*models.Event, error) {
+	var events []*models.Event
+	if err := DB.Preload("Positions.User").Preload("Positions.Shifts.User").
+		Where("start_date > ? AND start_date < ?", time.Now(), until).
+		Find(&events).Error; err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// FindNotifiedControllers returns the controllers that were told about their assignment to the event, and not told
+// since that they are no longer assigned
+func FindNotifiedControllers(eventID uint) ([]*models.User, error) {
+	removed := DB.Table("event_notifications r").Select("1").
+		Where("r.event_id = n.event_id AND r.cid = n.cid AND r.kind = ? AND r.id > n.id", constants.EventNotificationRemoved)
+	notified := DB.Table("event_notifications n").Select("n.cid").
+		Where("n.event_id = ? AND n.kind = ?", eventID, constants.EventNotificationAssignment).
+		Where("NOT EXISTS (?)", removed)
+
+	var users []*models.User
+	if err := DB.Where("cid IN (?)", notified).Find(&users).Error; err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// FindEventReminderHours returns the reminders, in hours before the start, already sent for the event
+func FindEventReminderHours(eventID uint) ([]int, error) {
+	var hours []int
+	if err := DB.Model(&models.EventNotification{}).Distinct("hours").Where(models.EventNotification{
+		EventID: eventID,
+		Kind:    constants.EventNotificationReminder,
+	}).Pluck("hours", &hours).Error; err != nil {
+		return nil, err
+	}
+
+	return hours, nil
+}
+
+// FindControllerStatsBetween returns the finished sessions of the controllers that were online at any point from
+// start to end. Sessions are only looked for up to a day before start.
+func FindControllerStatsBetween(cids []uint, start, end time.Time) ([]*models.ControllerStat, error) {
+	var stats []*models.ControllerStat
+	if err := DB.Where("user_id IN ? AND logon_time < ? AND logon_time > ?", cids, end, start.Add(-24*time.Hour)).
+		Order("logon_time asc").Find(&stats).Error; err != nil {
+		return nil, err
+	}
+
+	var ret []*models.ControllerStat
+	for _, stat := range stats {
+		if stat.LogonTime.Add(time.Duration(stat.Duration) * time.Second).After(start) {
+			ret = append(ret, stat)
+		}
+	}
+
+	return ret, nil
+}
+
+// FindOnlineControllersBefore returns the current sessions of the controllers that logged on before end
+func FindOnlineControllersBefore(cids []uint, end time.Time) ([]*models.OnlineController, error) {
+	var controllers []*models.OnlineController
+	if err := DB.Where("user_id IN ? AND logon_time < ?", cids, end).Find(&controllers).Error; err != nil {
+		return nil, err
+	}
+
+	return controllers, nil
+}
+
+// FindEventNoShows returns the no shows recorded for the event
+func FindEventNoShows(eventID uint) ([]*models.EventNoShow, error) {
+	var noShows []*models.EventNoShow
+	if err := DB.Preload("Reporter").Where(models.EventNoShow{EventID: eventID}).Find(&noShows).Error; err != nil {
+		return nil, err
+	}
+
+	return noShows, nil
+}
+
+// FindUserNoShows returns the no shows recorded for the controller, newest first, including those of deleted events
+func FindUserNoShows(cid uint) ([]*models.EventNoShow, error) {
+	var noShows []*models.EventNoShow
+	if err := DB.Preload("Event", func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped()
+	}).Preload("Reporter").Where(models.EventNoShow{CID: cid}).Order("created_at desc").Find(&noShows).Error; err != nil {
+		return nil, err
+	}
+
+	return noShows, nil
+}
+
+// FindEventTemplates returns every event template with its positions
+func FindEventTemplates() ([]*models.EventTemplate, error) {
+	var templates []*models.EventTemplate
+	if err := DB.Preload("Positions").Order("title asc").Find(&templates).Error; err != nil {
+		return nil, err
+	}
+
+	return templates, nil
+}
+
+// FindEventTemplate returns the event template with its positions, or nil if it does not exist
+func FindEventTemplate(id string) (*models.EventTemplate, error) {
+	template := &models.EventTemplate{}
+	if err := DB.Preload("Positions").Where("id = ?", id).First(template).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return template, nil
+}
+
+// FindRecurringEventTemplates returns the event templates that have a recurrence rule
+func FindRecurringEventTemplates() ([]*models.EventTemplate, error) {
+	var templates []*models.EventTemplate
+	if err := DB.Preload("Positions").Where("frequency != ?", "").Find(&templates).Error; err != nil {
+		return nil, err
+	}
+
+	return templates, nil
+}
+
+// TemplateEventExists returns true if an event of the template starting at start exists or was deleted
+func TemplateEventExists(templateID uint, start time.Time) (bool, error) {
+	var count int64
+	if err := DB.Unscoped().Model(&models.Event{}).Where("template_id = ? AND start_date = ?", templateID, start).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// FindStaffingRequests returns the staffing requests, newest first, optionally only those with the status
+func FindStaffingRequests(status string) ([]*models.StaffingRequest, error) {
+	var requests []*models.StaffingRequest
+	query := DB.Preload("User.Rating").Preload("HandledBy.Rating").Order("created_at desc")
+	if status != "" {
+		query = query.Where(models.StaffingRequest{Status: status})
+	}
+	if err := query.Find(&requests).Error; err != nil {
+		return nil, err
+	}
+
+	return requests, nil
+}
+
+// FindUserStaffingRequests returns the staffing requests the user submitted, newest first
+func FindUserStaffingRequests(cid uint) ([]*models.StaffingRequest, error) {
+	var requests []*models.StaffingRequest
+	if err := DB.Preload("User.Rating").Preload("HandledBy.Rating").Where(models.StaffingRequest{UserID: cid}).
+		Order("created_at desc").Find(&requests).Error; err != nil {
+		return nil, err
+	}
+
+	return requests, nil
+}
+
+// FindStaffingRequest returns the staffing request, or nil if it does not exist
+func FindStaffingRequest(id string) (*models.StaffingRequest, error) {
+	request := &models.StaffingRequest{}
+	if err := DB.Preload("User.Rating").Preload("HandledBy.Rating").Where("id = ?", id).First(request).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return request, nil
+}
+
+// FindTrackedEvents returns the events, with their positions, that are running at now or whose baseline window,
+// offset before the event, is running at now
+func FindTrackedEvents(now time.Time, offset time.Duration) ([]*models.Event, error) {
+	var events []*models.Event
+	baseline := now.Add(offset)
+	if err := DB.Preload("Positions").
+		Where("(start_date <= ? AND end_date > ?) OR (start_date <= ? AND end_date > ?)", now, now, baseline, baseline).
+		Find(&events).Error; err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// FindFlightMovements returns the movements at the airports from start up to end
+func FindFlightMovements(airports []string, start, end time.Time) ([]*models.FlightMovement, error) {
+	var movements []*models.FlightMovement
+	if err := DB.Where("airport IN ? AND moved_at >= ? AND moved_at < ?", airports, start, end).Order("moved_at asc").Find(&movements).Error; err != nil {
+		return nil, err
+	}
+
+	return movements, nil
+}
+
+// FindFeedbackInRange returns the feedback of a controller, or of every controller if cid is 0, created in the
+// range, oldest first
+func FindFeedbackInRange(cid uint, from, to *time.Time) ([]*models.Feedback, error) {
+	var feedback []*models.Feedback
+	tx := DB.Preload("Controller")
+	if cid != 0 {
+		tx = tx.Where("controller_id = ?", cid)
+	}
+	if from != nil {
+		tx = tx.Where("created_at >= ?", from)
+	}
+	if to != nil {
+		tx = tx.Where("created_at < ?", to)
+	}
+	if err := tx.Order("created_at asc").Find(&feedback).Error; err != nil {
+		return nil, err
+	}
+
+	return feedback, nil
+}
+
+// FindFlightLogs returns the flights within the facility of the pilot or of the callsign that overlap the range
+func FindFlightLogs(cid uint, callsign string, start, end time.Time) ([]*models.FlightLog, error) {
+	var logs []*models.FlightLog
+	if err := DB.Where("(cid = ? OR callsign = ?) AND first_seen < ? AND last_seen > ?", cid, callsign, end, start).
+		Order("first_seen asc").Find(&logs).Error; err != nil {
+		return nil, err
+	}
+
+	return logs, nil
+}
+
 func FindAPIKey(key string) (*models.APIKeys, error) {
 	apikey := &models.APIKeys{}
 	if err := DB.Where(models.APIKeys{Key: key}).First(apikey).Error; err != nil {
@@ -19,9 +19,11 @@ package models
 import "time"
 
 type APIKeys struct {
-	ID        uint      `json:"id" example:"1"`
-	Key       string    `json:"key" example:"1234567890"`
-	Roles     string    `json:"role" example:"[\"atm\"]"`
+	ID    uint   `json:"id" example:"1"`
+	Key   string `json:"key" example:"1234567890"`
+	Roles string `json:"role" example:"[\"atm\"]"`
+	// Facility of the partner the key was issued to, empty for keys not issued to a partner
+	Facility  string    `json:"facility" example:"ZLC" gorm:"type:varchar(4)"`
 	CreatedAt time.Time `json:"created_at" example:"2020-01-01T00:00:00Z"`
 	UpdatedAt time.Time `json:"updated_at" example:"2020-01-01T00:00:00Z"`
 }
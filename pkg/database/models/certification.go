@@ -24,6 +24,20 @@ type Certification struct {
 	DisplayName string `json:"display_name"`
 	Order       uint   `json:"order"`
 	Hidden      bool   `json:"hidden"`
+	// Minimum rating ID needed to be solo or certified, 0 for none
+	MinRatingID   int                          `json:"min_rating_id"`
+	Prerequisites []*CertificationPrerequisite `json:"prerequisites" gorm:"-"`
+}
+
+// CertificationPrerequisite requires Requires to be held, for at least MinDays, before Certification can be
+// held solo or certified
+type CertificationPrerequisite struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	Certification string    `json:"certification" gorm:"type:varchar(128);index"`
+	Requires      string    `json:"requires" gorm:"type:varchar(128)"`
+	MinDays       int       `json:"min_days"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
 }
 
 type UserCertification struct {
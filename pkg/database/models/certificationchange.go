@@ -0,0 +1,37 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package models
+
+import "time"
+
+// CertificationChange is an append-only record of a change to a user's certification
+type CertificationChange struct {
+	ID                uint      `json:"id" gorm:"primaryKey"`
+	CID               uint      `json:"cid" gorm:"index"`
+	Certification     string    `json:"certification" gorm:"type:varchar(128)"`
+	OldValue          string    `json:"old_value" gorm:"type:varchar(20)"`
+	NewValue          string    `json:"new_value" gorm:"type:varchar(20)"`
+	Source            string    `json:"source" gorm:"type:varchar(20);index"`
+	ActorID           *uint     `json:"actor_id"`
+	Actor             *User     `json:"actor" gorm:"foreignKey:ActorID"`
+	Reason            string    `json:"reason" gorm:"type:text"`
+	Override          bool      `json:"override"`
+	TrainingNoteID    *uint     `json:"training_note_id"`
+	SoloEndorsementID *uint     `json:"solo_endorsement_id"`
+	RosterImportID    *uint     `json:"roster_import_id"`
+	CreatedAt         time.Time `json:"created_at"`
+}
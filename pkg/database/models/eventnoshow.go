@@ -0,0 +1,33 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package models
+
+import "time"
+
+// EventNoShow records that a controller did not work a position they were assigned during an event
+type EventNoShow struct {
+	ID         uint      `json:"id"`
+	EventID    uint      `json:"event_id" gorm:"index"`
+	Event      *Event    `json:"event,omitempty"`
+	CID        uint      `json:"cid" gorm:"index"`
+	Position   string    `json:"position" gorm:"type:varchar(25)"`
+	ShiftID    *uint     `json:"shift_id"`
+	ReporterID uint      `json:"reporter_id"`
+	Reporter   *User     `json:"reporter" gorm:"foreignKey:ReporterID"`
+	Notes      string    `json:"notes" gorm:"type:text"`
+	CreatedAt  time.Time `json:"created_at"`
+}
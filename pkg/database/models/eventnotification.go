@@ -0,0 +1,30 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package models
+
+import "time"
+
+// EventNotification records a notice sent to a controller about an event they work
+type EventNotification struct {
+	ID      uint   `json:"id"`
+	EventID uint   `json:"event_id" gorm:"index"`
+	CID     uint   `json:"cid" gorm:"index"`
+	Kind    string `json:"kind" gorm:"type:varchar(20)"`
+	// For reminders, how many hours before the start of the event it was sent
+	Hours     int       `json:"hours"`
+	CreatedAt time.Time `json:"created_at"`
+}
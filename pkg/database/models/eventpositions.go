@@ -19,12 +19,32 @@ package models
 import "time"
 
 type EventPosition struct {
-	ID        uint      `json:"id"`
-	EventID   uint      `json:"-" gorm:"index:event_position"`
-	Event     Event     `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
-	Position  string    `json:"position" gorm:"index:event_position"`
-	UserID    *uint     `json:"-"`
-	User      *User     `json:"user"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID       uint   `json:"id"`
+	EventID  uint   `json:"-" gorm:"index:event_position"`
+	Event    Event  `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Position string `json:"position" gorm:"index:event_position"`
+	// Facility staffing the position, empty for the host facility
+	Facility string `json:"facility" gorm:"type:varchar(4)"`
+	UserID   *uint  `json:"-"`
+	User     *User  `json:"user"`
+	// Controller of a partner facility assigned through the shared roster, who is not on our roster
+	ExternalCID    *uint     `json:"external_cid"`
+	ExternalName   string    `json:"external_name" gorm:"type:varchar(128)"`
+	Locked         bool      `json:"locked"`
+	MinRatingID    int       `json:"min_rating_id"`
+	ProposedUserID *uint     `json:"-"`
+	ProposedUser   *User     `json:"proposed_user" gorm:"foreignKey:ProposedUserID"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+
+	Certifications []*EventPositionCertification `json:"certifications" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Shifts         []*EventShift                 `json:"shifts"`
+}
+
+// EventPositionCertification is a certification a controller must hold to work an event position
+type EventPositionCertification struct {
+	ID              uint      `json:"-"`
+	EventPositionID uint      `json:"-" gorm:"index"`
+	Certification   string    `json:"certification" gorm:"type:varchar(128)"`
+	CreatedAt       time.Time `json:"created_at"`
 }
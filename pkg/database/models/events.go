@@ -18,17 +18,35 @@ package models
 
 import (
 	"time"
+
+	"gorm.io/gorm"
 )
 
 type Event struct {
-	ID          uint             `json:"id" gorm:"primaryKey"`
-	Title       string           `json:"title"`
-	Description string           `json:"description"`
-	Banner      string           `json:"banner"`
-	StartDate   time.Time        `json:"start_date"`
-	EndDate     time.Time        `json:"end_date"`
-	Positions   []*EventPosition `json:"positions"`
-	Signups     []*EventSignup   `json:"signups"`
-	CreatedAt   time.Time        `json:"created_at"`
-	UpdatedAt   time.Time        `json:"updated_at"`
+	ID                     uint             `json:"id" gorm:"primaryKey"`
+	Title                  string           `json:"title"`
+	Description            string           `json:"description"`
+	Banner                 string           `json:"banner"`
+	StartDate              time.Time        `json:"start_date"`
+	EndDate                time.Time        `json:"end_date"`
+	Positions              []*EventPosition `json:"positions"`
+	Signups                []*EventSignup   `json:"signups"`
+	Facilities             []*EventFacility `json:"facilities" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	AssignmentDraftAt      *time.Time       `json:"assignment_draft_at"`
+	AssignmentsPublishedAt *time.Time       `json:"assignments_published_at"`
+	AnnouncedAt            *time.Time       `json:"announced_at"`
+	TemplateID             *uint            `json:"template_id" gorm:"index"`
+	SignupsCloseAt         *time.Time       `json:"signups_close_at"`
+	SignupCap              int              `json:"signup_cap"`
+	CreatedAt              time.Time        `json:"created_at"`
+	UpdatedAt              time.Time        `json:"updated_at"`
+	DeletedAt              gorm.DeletedAt   `json:"-" gorm:"index"`
+}
+
+// EventFacility is a partner facility co-hosting an event, staffing the positions tagged with it
+type EventFacility struct {
+	ID        uint      `json:"-"`
+	EventID   uint      `json:"-" gorm:"index"`
+	Facility  string    `json:"facility" gorm:"type:varchar(4)"`
+	CreatedAt time.Time `json:"created_at"`
 }
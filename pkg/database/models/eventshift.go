@@ -0,0 +1,37 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package models
+
+import "time"
+
+// EventShift is a time-bounded part of an event position with its own controller
+type EventShift struct {
+	ID              uint          `json:"id"`
+	EventPositionID uint          `json:"-" gorm:"index"`
+	EventPosition   EventPosition `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	StartsAt        time.Time     `json:"starts_at"`
+	EndsAt          time.Time     `json:"ends_at"`
+	UserID          *uint         `json:"-"`
+	User            *User         `json:"user"`
+	// Controller of a partner facility assigned through the shared roster, who is not on our roster
+	ExternalCID    *uint     `json:"external_cid"`
+	ExternalName   string    `json:"external_name" gorm:"type:varchar(128)"`
+	ProposedUserID *uint     `json:"-"`
+	ProposedUser   *User     `json:"proposed_user" gorm:"foreignKey:ProposedUserID"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
@@ -0,0 +1,53 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package models
+
+import "time"
+
+// EventTemplate holds what the events of a series have in common, and the rule the series recurs by. Events are
+// copied from the template, so later changes to the template or to one of its events do not affect the others.
+type EventTemplate struct {
+	ID          uint                     `json:"id"`
+	Title       string                   `json:"title"`
+	Description string                   `json:"description"`
+	Banner      string                   `json:"banner"`
+	Positions   []*EventTemplatePosition `json:"positions" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	// Empty for templates that do not recur, otherwise weekly or monthly
+	Frequency string `json:"frequency" gorm:"type:varchar(10)"`
+	// Number of weeks or months between events
+	Every   int    `json:"every"`
+	Weekday string `json:"weekday" gorm:"type:varchar(10)"`
+	// Week of the month monthly events are on, 1 to 4 or -1 for the last
+	WeekOfMonth int `json:"week_of_month"`
+	// Zulu time of day events start at as HH:MM
+	StartTime       string     `json:"start_time" gorm:"type:varchar(5)"`
+	DurationMinutes int        `json:"duration_minutes"`
+	StartsOn        time.Time  `json:"starts_on"`
+	EndsOn          *time.Time `json:"ends_on"`
+	// Events of the series have been generated up to this time
+	GeneratedUntil *time.Time `json:"generated_until"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// EventTemplatePosition is a position every event of a template starts out with
+type EventTemplatePosition struct {
+	ID              uint   `json:"-"`
+	EventTemplateID uint   `json:"-" gorm:"index"`
+	Position        string `json:"position" gorm:"type:varchar(25)"`
+	MinRatingID     int    `json:"min_rating_id"`
+}
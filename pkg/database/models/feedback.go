@@ -23,19 +23,25 @@ import (
 )
 
 type Feedback struct {
-	ID           int        `json:"id" gorm:"primaryKey"`
-	SubmitterID  uint       `json:"-"`
-	Submitter    *User      `json:"submitter"`
-	ControllerID uint       `json:"-"`
-	Controller   *User      `json:"controller"`
-	Rating       string     `json:"rating" gorm:"type:varchar(20);not null"`
-	Status       string     `json:"status" gorm:"type:varchar(20);not null"`
-	Position     string     `json:"position" gorm:"type:varchar(20);not null"`
-	Callsign     string     `json:"callsign" gorm:"type:varchar(20);not null"`
-	Comments     string     `json:"comments" gorm:"type:text"`
-	ContactEmail string     `json:"contact_email" gorm:"type:varchar(255)"`
-	CreatedAt    *time.Time `json:"created_at"`
-	UpdatedAt    *time.Time `json:"updated_at"`
+	ID           int    `json:"id" gorm:"primaryKey"`
+	SubmitterID  uint   `json:"-"`
+	Submitter    *User  `json:"submitter"`
+	ControllerID uint   `json:"-"`
+	Controller   *User  `json:"controller"`
+	Rating       string `json:"rating" gorm:"type:varchar(20);not null"`
+	Status       string `json:"status" gorm:"type:varchar(20);not null"`
+	Position     string `json:"position" gorm:"type:varchar(20);not null"`
+	Callsign     string `json:"callsign" gorm:"type:varchar(20);not null"`
+	Comments     string `json:"comments" gorm:"type:text"`
+	ContactEmail string `json:"contact_email" gorm:"type:varchar(255)"`
+	// Result of checking the submission against the controller's sessions and the submitter's flights, with what was
+	// found one note per line
+	VerificationResult     string     `json:"verification_result" gorm:"type:varchar(20)"`
+	VerificationConfidence float64    `json:"verification_confidence"`
+	VerificationNotes      string     `json:"verification_notes" gorm:"type:text"`
+	VerifiedAt             *time.Time `json:"verified_at"`
+	CreatedAt              *time.Time `json:"created_at"`
+	UpdatedAt              *time.Time `json:"updated_at"`
 }
 
 func IsValidFeedbackRating(rating string) bool {
@@ -0,0 +1,30 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package models
+
+import "time"
+
+// FlightMovement is a departure or arrival recorded at an airport while an event there, or its baseline window, runs
+type FlightMovement struct {
+	ID        uint      `json:"id"`
+	Airport   string    `json:"airport" gorm:"type:varchar(4);index:airport_movement"`
+	Callsign  string    `json:"callsign" gorm:"type:varchar(10)"`
+	CID       int       `json:"cid"`
+	Kind      string    `json:"kind" gorm:"type:varchar(10)"`
+	MovedAt   time.Time `json:"moved_at" gorm:"index:airport_movement"`
+	CreatedAt time.Time `json:"created_at"`
+}
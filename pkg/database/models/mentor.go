@@ -0,0 +1,35 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package models
+
+import "time"
+
+type MentorAssignment struct {
+	ID           uint       `json:"id" gorm:"primaryKey"`
+	StudentID    uint       `json:"student_id" gorm:"index"`
+	Student      *User      `json:"student" gorm:"foreignKey:StudentID"`
+	MentorID     uint       `json:"mentor_id" gorm:"index"`
+	Mentor       *User      `json:"mentor" gorm:"foreignKey:MentorID"`
+	AssignedByID uint       `json:"assigned_by_id"`
+	AssignedBy   *User      `json:"assigned_by" gorm:"foreignKey:AssignedByID"`
+	Notes        string     `json:"notes" gorm:"type:text"`
+	StartsAt     time.Time  `json:"starts_at"`
+	EndedAt      *time.Time `json:"ended_at" gorm:"index"`
+	EndReason    string     `json:"end_reason" gorm:"type:varchar(10)"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,90 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/adh-partnership/api/pkg/database/models/constants"
+)
+
+type OTSRubric struct {
+	ID            uint                 `json:"id" gorm:"primaryKey"`
+	Name          string               `json:"name" gorm:"type:varchar(128)"`
+	Position      string               `json:"position" gorm:"type:varchar(20);index"`
+	Certification string               `json:"certification" gorm:"type:varchar(128)"`
+	AutoCertify   bool                 `json:"auto_certify"`
+	Categories    []*OTSRubricCategory `json:"categories" gorm:"foreignKey:RubricID"`
+	CreatedAt     time.Time            `json:"created_at"`
+	UpdatedAt     time.Time            `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt       `json:"-" gorm:"index"`
+}
+
+type OTSRubricCategory struct {
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	RubricID    uint           `json:"rubric_id" gorm:"index"`
+	Order       uint           `json:"order"`
+	Name        string         `json:"name" gorm:"type:varchar(128)"`
+	Description string         `json:"description" gorm:"type:text"`
+	Critical    bool           `json:"critical"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+type OTSEvaluation struct {
+	ID                    uint                  `json:"id" gorm:"primaryKey"`
+	RubricID              uint                  `json:"rubric_id"`
+	Rubric                *OTSRubric            `json:"rubric"`
+	TrainingNoteID        uint                  `json:"training_note_id" gorm:"index"`
+	StudentID             uint                  `json:"student_id" gorm:"index"`
+	Student               *User                 `json:"student" gorm:"foreignKey:StudentID"`
+	InstructorID          uint                  `json:"instructor_id"`
+	Instructor            *User                 `json:"instructor" gorm:"foreignKey:InstructorID"`
+	Position              string                `json:"position" gorm:"type:varchar(20)"`
+	Certification         string                `json:"certification" gorm:"type:varchar(128)"`
+	Result                string                `json:"result" gorm:"type:varchar(10)"`
+	Comments              string                `json:"comments" gorm:"type:text"`
+	Scores                []*OTSEvaluationScore `json:"scores" gorm:"foreignKey:EvaluationID"`
+	CertificationChangeID *uint                 `json:"certification_change_id"`
+	CertificationChange   *CertificationChange  `json:"certification_change"`
+	CreatedAt             time.Time             `json:"created_at"`
+	UpdatedAt             time.Time             `json:"updated_at"`
+}
+
+type OTSEvaluationScore struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	EvaluationID uint      `json:"evaluation_id" gorm:"index"`
+	CategoryID   uint      `json:"category_id"`
+	CategoryName string    `json:"category_name" gorm:"type:varchar(128)"`
+	Critical     bool      `json:"critical"`
+	Score        string    `json:"score" gorm:"type:varchar(20)"`
+	Comments     string    `json:"comments" gorm:"type:text"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+func IsValidOTSScore(s string) bool {
+	return s == constants.OTSScoreSatisfactory || s == constants.OTSScoreNeedsImprovement ||
+		s == constants.OTSScoreUnsatisfactory || s == constants.OTSScoreNotObserved
+}
+
+func IsValidOTSResult(s string) bool {
+	return s == constants.OTSResultPass || s == constants.OTSResultFail
+}
@@ -0,0 +1,52 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package models
+
+import (
+	"time"
+
+	"github.com/adh-partnership/api/pkg/database/models/constants"
+)
+
+type PromotionRequest struct {
+	ID              uint             `json:"id" gorm:"primaryKey"`
+	StudentID       uint             `json:"student_id" gorm:"index"`
+	Student         *User            `json:"student" gorm:"foreignKey:StudentID"`
+	FromRatingID    int              `json:"-"`
+	FromRating      *Rating          `json:"from_rating"`
+	ToRatingID      int              `json:"-"`
+	ToRating        *Rating          `json:"to_rating"`
+	Position        string           `json:"position" gorm:"type:varchar(20)"`
+	Status          string           `json:"status" gorm:"type:varchar(12);index"`
+	Notes           string           `json:"notes" gorm:"type:text"`
+	RecommendedByID uint             `json:"recommended_by_id"`
+	RecommendedBy   *User            `json:"recommended_by" gorm:"foreignKey:RecommendedByID"`
+	ReviewedByID    *uint            `json:"reviewed_by_id"`
+	ReviewedBy      *User            `json:"reviewed_by" gorm:"foreignKey:ReviewedByID"`
+	ReviewNotes     string           `json:"review_notes" gorm:"type:text"`
+	SubmissionError string           `json:"submission_error" gorm:"type:text"`
+	SubmittedAt     *time.Time       `json:"submitted_at"`
+	TrainingNotes   []*TrainingNote  `json:"training_notes" gorm:"many2many:promotion_request_training_notes"`
+	OTSEvaluations  []*OTSEvaluation `json:"ots_evaluations" gorm:"many2many:promotion_request_ots_evaluations"`
+	CreatedAt       time.Time        `json:"created_at"`
+	UpdatedAt       time.Time        `json:"updated_at"`
+}
+
+func IsValidPromotionStatus(s string) bool {
+	return s == constants.PromotionStatusRecommended || s == constants.PromotionStatusApproved ||
+		s == constants.PromotionStatusSubmitted || s == constants.PromotionStatusRejected
+}
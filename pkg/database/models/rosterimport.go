@@ -0,0 +1,31 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package models
+
+import "time"
+
+// RosterImport is the audit entry of a bulk certification import, the changes it made link back to it
+type RosterImport struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	ActorID   *uint     `json:"actor_id"`
+	Actor     *User     `json:"actor" gorm:"foreignKey:ActorID"`
+	Format    string    `json:"format" gorm:"type:varchar(10)"`
+	Reason    string    `json:"reason" gorm:"type:text"`
+	Override  bool      `json:"override"`
+	Changes   int       `json:"changes"`
+	CreatedAt time.Time `json:"created_at"`
+}
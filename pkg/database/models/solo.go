@@ -0,0 +1,40 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package models
+
+import "time"
+
+type SoloEndorsement struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	StudentID     uint      `json:"student_id" gorm:"index"`
+	Student       *User     `json:"student" gorm:"foreignKey:StudentID"`
+	InstructorID  uint      `json:"instructor_id"`
+	Instructor    *User     `json:"instructor" gorm:"foreignKey:InstructorID"`
+	Certification string    `json:"certification" gorm:"type:varchar(128)"`
+	Position      string    `json:"position" gorm:"type:varchar(20)"`
+	Reason        string    `json:"reason" gorm:"type:text"`
+	PreviousValue string    `json:"previous_value" gorm:"type:varchar(20)"`
+	StartsAt      time.Time `json:"starts_at"`
+	ExpiresAt     time.Time `json:"expires_at" gorm:"index"`
+	// Pending endorsements start in the future and have not made the student solo yet
+	Pending   bool       `json:"pending"`
+	WarnedAt  *time.Time `json:"warned_at"`
+	EndedAt   *time.Time `json:"ended_at"`
+	EndReason string     `json:"end_reason" gorm:"type:varchar(10)"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
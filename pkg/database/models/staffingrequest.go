@@ -0,0 +1,44 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package models
+
+import "time"
+
+// StaffingRequest is a request from a group, such as a virtual airline, for controllers to staff their event
+type StaffingRequest struct {
+	ID               uint   `json:"id"`
+	UserID           uint   `json:"cid" gorm:"index"`
+	User             *User  `json:"user"`
+	DepartureAirport string `json:"departure_airport" gorm:"type:varchar(4)"`
+	ArrivalAirport   string `json:"arrival_airport" gorm:"type:varchar(4)"`
+	StartDate        string `json:"start_date" gorm:"type:varchar(64)"`
+	EndDate          string `json:"end_date" gorm:"type:varchar(64)"`
+	Pilots           int    `json:"pilots"`
+	ContactInfo      string `json:"contact_info"`
+	Organization     string `json:"organization"`
+	BannerURL        string `json:"banner_url"`
+	Comments         string `json:"comments" gorm:"type:text"`
+	// Must be one of: new, accepted, declined, converted
+	Status      string `json:"status" gorm:"type:varchar(10);index"`
+	StaffNotes  string `json:"staff_notes" gorm:"type:text"`
+	HandledByID *uint  `json:"-"`
+	HandledBy   *User  `json:"handled_by" gorm:"foreignKey:HandledByID"`
+	// Event the request was converted into
+	EventID   *uint     `json:"event_id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,52 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package models
+
+import (
+	"time"
+
+	"github.com/adh-partnership/api/pkg/database/models/constants"
+)
+
+// SyllabusItem is a competency a student must demonstrate before being certified on Certification
+type SyllabusItem struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	Certification string    `json:"certification" gorm:"type:varchar(128);index"`
+	Order         uint      `json:"order"`
+	Title         string    `json:"title" gorm:"type:varchar(255)"`
+	Description   string    `json:"description" gorm:"type:text"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// TrainingNoteProgress records how a student performed on a syllabus item during a training session
+type TrainingNoteProgress struct {
+	ID             uint          `json:"id" gorm:"primaryKey"`
+	TrainingNoteID uint          `json:"training_note_id" gorm:"index"`
+	ControllerID   uint          `json:"controller_id" gorm:"index"`
+	SyllabusItemID uint          `json:"syllabus_item_id"`
+	SyllabusItem   *SyllabusItem `json:"syllabus_item" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Status         string        `json:"status" gorm:"type:varchar(20)"`
+	Comments       string        `json:"comments" gorm:"type:text"`
+	CreatedAt      time.Time     `json:"created_at"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+}
+
+func IsValidSyllabusProgressStatus(s string) bool {
+	return s == constants.SyllabusProgressIntroduced || s == constants.SyllabusProgressProgressing ||
+		s == constants.SyllabusProgressSatisfactory
+}
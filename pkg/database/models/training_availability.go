@@ -0,0 +1,45 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package models
+
+import (
+	"time"
+
+	"github.com/adh-partnership/api/pkg/database/models/constants"
+)
+
+// TrainingAvailability is a window in which an instructor or mentor is available to train.
+// Recurring windows repeat weekly on Weekday between StartTime and EndTime (HH:MM, UTC), an
+// EndTime before StartTime means the window ends on the following day. One-off windows use Start and End.
+type TrainingAvailability struct {
+	ID           uint       `json:"id" gorm:"primaryKey"`
+	InstructorID uint       `json:"-" gorm:"index"`
+	Instructor   *User      `json:"instructor"`
+	Type         string     `json:"type" gorm:"type:varchar(10);not null"`
+	Weekday      int        `json:"weekday"`
+	StartTime    string     `json:"start_time" gorm:"type:varchar(5)"`
+	EndTime      string     `json:"end_time" gorm:"type:varchar(5)"`
+	Start        *time.Time `json:"start" gorm:"default:null"`
+	End          *time.Time `json:"end" gorm:"default:null"`
+	Notes        string     `json:"notes" gorm:"type:text"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+func IsValidTrainingAvailabilityType(t string) bool {
+	return t == constants.TrainingAvailabilityTypeRecurring || t == constants.TrainingAvailabilityTypeOnce
+}
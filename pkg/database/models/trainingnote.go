@@ -16,22 +16,35 @@
 
 package models
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type TrainingNote struct {
-	ID           uint       `json:"id" gorm:"primaryKey"`
-	ControllerID uint       `json:"controller_id"`
-	Controller   *User      `json:"controller"`
-	InstructorID uint       `json:"instructor_id"`
-	Instructor   *User      `json:"instructor"`
-	Position     string     `json:"position"`
-	Type         string     `json:"type"`
-	Comments     string     `json:"comments"`
-	SessionDate  *time.Time `json:"session_date"`
-	Duration     string     `json:"duration"`
-	VATUSAID     uint       `json:"vatusa_id"`
-	CreatedAt    time.Time  `json:"created_at"`
-	UpdatedAt    time.Time  `json:"updated_at"`
+	ID           uint                    `json:"id" gorm:"primaryKey"`
+	ControllerID uint                    `json:"controller_id"`
+	Controller   *User                   `json:"controller"`
+	InstructorID uint                    `json:"instructor_id"`
+	Instructor   *User                   `json:"instructor"`
+	Position     string                  `json:"position"`
+	Type         string                  `json:"type"`
+	Comments     string                  `json:"comments"`
+	SessionDate  *time.Time              `json:"session_date"`
+	Duration     string                  `json:"duration"`
+	VATUSAID     uint                    `json:"vatusa_id"`
+	Progress     []*TrainingNoteProgress `json:"progress"`
+	// VATUSA sync state, only shown to training staff through dto.TrainingNoteSync
+	SyncStatus   string         `json:"-" gorm:"type:varchar(10);default:synced;index"`
+	SyncAction   string         `json:"-" gorm:"type:varchar(10)"`
+	SyncAttempts int            `json:"-"`
+	SyncError    string         `json:"-" gorm:"type:text"`
+	NextSyncAt   *time.Time     `json:"-"`
+	SyncedAt     *time.Time     `json:"-"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `json:"-" gorm:"index"`
 }
 
 var TrainingNoteTypes = map[string]string{
@@ -39,6 +39,8 @@ type User struct {
 	Division  string  `json:"division" gorm:"type:varchar(10)" example:"USA"`
 	// This may be blank
 	Subdivision string `json:"subdivision" gorm:"type:varchar(10)" example:"ZDV"`
+	// Secret that authenticates the user's personal calendar feed, empty until one is requested
+	CalendarToken string `json:"-" gorm:"type:varchar(64);index"`
 	// Internally used identifier during scheduled updates for removals
 	UpdateID       string     `json:"updateid" gorm:"type:varchar(32)"`
 	RosterJoinDate *time.Time `json:"roster_join_date" example:"2020-01-01T00:00:00Z"`
@@ -0,0 +1,293 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package database
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/adh-partnership/api/pkg/database/models"
+	"github.com/adh-partnership/api/pkg/database/models/constants"
+)
+
+var ErrOverrideReasonRequired = errors.New("a reason is required to override certification prerequisites")
+
+// PrerequisiteError is returned when a certification change does not meet the certification's prerequisites
+type PrerequisiteError struct {
+	Certification string
+	Violations    []string
+}
+
+func (e *PrerequisiteError) Error() string {
+	return fmt.Sprintf("%s prerequisites not met: %s", e.Certification, strings.Join(e.Violations, "; "))
+}
+
+// CertificationHolder is what the prerequisite rules need to know about a user
+type CertificationHolder struct {
+	CID      uint
+	RatingID int
+	// Certification values by name
+	Values map[string]string
+	// When the user's current certified stint on each certification began, where the history records it
+	CertifiedSince map[string]time.Time
+}
+
+// IsCertifiedValue returns true if the value counts as holding the certification
+func IsCertifiedValue(value string) bool {
+	return value == constants.CertificationCertified || value == constants.CertificationCanTrain
+}
+
+// RequiresPrerequisites returns true if setting a certification to value is subject to its prerequisites
+func RequiresPrerequisites(value string) bool {
+	return value == constants.CertificationSolo || IsCertifiedValue(value)
+}
+
+// CheckPrerequisites returns the reasons holder cannot hold cert at value, if any. ratings maps rating IDs to their
+// short names. A prerequisite held since before the change history began is assumed to satisfy its minimum days.
+func CheckPrerequisites(
+	cert models.Certification,
+	prerequisites []*models.CertificationPrerequisite,
+	value string,
+	holder *CertificationHolder,
+	ratings map[int]string,
+	now time.Time,
+) []string {
+	if !RequiresPrerequisites(value) {
+		return nil
+	}
+
+	var violations []string
+	if cert.MinRatingID > 0 && holder.RatingID < cert.MinRatingID {
+		violations = append(violations, fmt.Sprintf("requires a rating of %s or above", ratingName(ratings, cert.MinRatingID)))
+	}
+
+	for _, p := range prerequisites {
+		if p.Certification != cert.Name {
+			continue
+		}
+		if !IsCertifiedValue(holder.Values[p.Requires]) {
+			violations = append(violations, fmt.Sprintf("requires %s certification", p.Requires))
+			continue
+		}
+		since, ok := holder.CertifiedSince[p.Requires]
+		if ok && p.MinDays > 0 && now.Sub(since) < time.Duration(p.MinDays)*24*time.Hour {
+			violations = append(violations, fmt.Sprintf("requires %s certification for at least %d days", p.Requires, p.MinDays))
+		}
+	}
+
+	return violations
+}
+
+// CertifiedSince returns when each certified stint in the change history began, dropping those that have since
+// ended
+func CertifiedSince(changes []*models.CertificationChange) map[string]time.Time {
+	sorted := make([]*models.CertificationChange, len(changes))
+	copy(sorted, changes)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].CreatedAt.Before(sorted[j].CreatedAt)
+	})
+
+	ret := map[string]time.Time{}
+	for _, c := range sorted {
+		switch {
+		case !IsCertifiedValue(c.NewValue):
+			delete(ret, c.Certification)
+		case !IsCertifiedValue(c.OldValue):
+			ret[c.Certification] = c.CreatedAt
+		}
+	}
+
+	return ret
+}
+
+// HasPrerequisiteCycle returns true if the prerequisites depend on each other in a loop, which would make the
+// certifications involved impossible to obtain
+func HasPrerequisiteCycle(prerequisites []*models.CertificationPrerequisite) bool {
+	graph := map[string][]string{}
+	for _, p := range prerequisites {
+		graph[p.Certification] = append(graph[p.Certification], p.Requires)
+	}
+
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := map[string]int{}
+	var visit func(string) bool
+	visit = func(name string) bool {
+		switch state[name] {
+		case visiting:
+			return true
+		case visited:
+			return false
+		}
+		state[name] = visiting
+		for _, next := range graph[name] {
+			if visit(next) {
+				return true
+			}
+		}
+		state[name] = visited
+		return false
+	}
+
+	for name := range graph {
+		if visit(name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func ratingName(ratings map[int]string, id int) string {
+	if name, ok := ratings[id]; ok {
+		return name
+	}
+
+	return fmt.Sprint(id)
+}
+
+// FindCertificationPrerequisites returns the prerequisite rules of a certification, or every rule if certification
+// is empty
+func FindCertificationPrerequisites(tx *gorm.DB, certification string) ([]*models.CertificationPrerequisite, error) {
+	var prerequisites []*models.CertificationPrerequisite
+	ptx := tx.Order("certification asc, requires asc")
+	if certification != "" {
+		ptx = ptx.Where(models.CertificationPrerequisite{Certification: certification})
+	}
+	if err := ptx.Find(&prerequisites).Error; err != nil {
+		return nil, err
+	}
+
+	return prerequisites, nil
+}
+
+// FindCertificationHolders returns the holders for the users, or for every user holding a certification if cids is nil
+func FindCertificationHolders(tx *gorm.DB, cids []uint) (map[uint]*CertificationHolder, error) {
+	var users []*models.User
+	utx := tx.Select("cid", "rating_id")
+	if cids != nil {
+		utx = utx.Where("cid IN ?", cids)
+	} else {
+		utx = utx.Where("cid IN (?)", tx.Model(&models.UserCertification{}).Select("cid"))
+	}
+	if err := utx.Find(&users).Error; err != nil {
+		return nil, err
+	}
+
+	ret := map[uint]*CertificationHolder{}
+	var found []uint
+	for _, u := range users {
+		ret[u.CID] = &CertificationHolder{
+			CID:            u.CID,
+			RatingID:       u.RatingID,
+			Values:         map[string]string{},
+			CertifiedSince: map[string]time.Time{},
+		}
+		found = append(found, u.CID)
+	}
+	if len(found) == 0 {
+		return ret, nil
+	}
+
+	var certs []*models.UserCertification
+	if err := tx.Where("cid IN ?", found).Find(&certs).Error; err != nil {
+		return nil, err
+	}
+	for _, c := range certs {
+		ret[c.CID].Values[c.Name] = c.Value
+	}
+
+	var changes []*models.CertificationChange
+	if err := tx.Where("cid IN ?", found).Find(&changes).Error; err != nil {
+		return nil, err
+	}
+	byUser := map[uint][]*models.CertificationChange{}
+	for _, c := range changes {
+		byUser[c.CID] = append(byUser[c.CID], c)
+	}
+	for cid, c := range byUser {
+		ret[cid].CertifiedSince = CertifiedSince(c)
+	}
+
+	return ret, nil
+}
+
+// FindRatingNames returns the short name of every rating by ID
+func FindRatingNames(tx *gorm.DB) (map[int]string, error) {
+	var ratings []*models.Rating
+	if err := tx.Find(&ratings).Error; err != nil {
+		return nil, err
+	}
+
+	ret := map[int]string{}
+	for _, r := range ratings {
+		ret[r.ID] = r.Short
+	}
+
+	return ret, nil
+}
+
+// checkCertificationChange returns a PrerequisiteError if the change does not meet the certification's prerequisites
+func checkCertificationChange(tx *gorm.DB, change *models.CertificationChange) error {
+	if !RequiresPrerequisites(change.NewValue) {
+		return nil
+	}
+
+	var certs []models.Certification
+	if err := tx.Where(models.Certification{Name: change.Certification}).Limit(1).Find(&certs).Error; err != nil {
+		return err
+	}
+	if len(certs) == 0 {
+		return nil
+	}
+	cert := certs[0]
+
+	prerequisites, err := FindCertificationPrerequisites(tx, change.Certification)
+	if err != nil {
+		return err
+	}
+	if len(prerequisites) == 0 && cert.MinRatingID == 0 {
+		return nil
+	}
+
+	holders, err := FindCertificationHolders(tx, []uint{change.CID})
+	if err != nil {
+		return err
+	}
+	holder, ok := holders[change.CID]
+	if !ok {
+		holder = &CertificationHolder{CID: change.CID, Values: map[string]string{}, CertifiedSince: map[string]time.Time{}}
+	}
+
+	ratings, err := FindRatingNames(tx)
+	if err != nil {
+		return err
+	}
+
+	if violations := CheckPrerequisites(cert, prerequisites, change.NewValue, holder, ratings, time.Now()); len(violations) > 0 {
+		return &PrerequisiteError{Certification: change.Certification, Violations: violations}
+	}
+
+	return nil
+}
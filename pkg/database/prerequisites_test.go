@@ -0,0 +1,119 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/adh-partnership/api/pkg/database/models"
+	"github.com/adh-partnership/api/pkg/database/models/constants"
+)
+
+func TestCheckPrerequisites(t *testing.T) {
+	now := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+	approach := models.Certification{Name: "approach", MinRatingID: 4}
+	prerequisites := []*models.CertificationPrerequisite{
+		{Certification: "approach", Requires: "tower", MinDays: 30},
+		{Certification: "approach", Requires: "ground"},
+		{Certification: "tower", Requires: "ground"},
+	}
+	ratings := map[int]string{3: "S2", 4: "S3"}
+
+	tests := []struct {
+		Name     string
+		Value    string
+		Holder   *CertificationHolder
+		Expected []string
+	}{
+		{
+			Name:  "Training is not restricted",
+			Value: constants.CertificationTraining,
+			Holder: &CertificationHolder{
+				RatingID: 3,
+				Values:   map[string]string{},
+			},
+		},
+		{
+			Name:  "Meets every rule",
+			Value: constants.CertificationCertified,
+			Holder: &CertificationHolder{
+				RatingID:       4,
+				Values:         map[string]string{"tower": constants.CertificationCertified, "ground": constants.CertificationCanTrain},
+				CertifiedSince: map[string]time.Time{"tower": now.Add(-31 * 24 * time.Hour)},
+			},
+		},
+		{
+			Name:  "Held before history began",
+			Value: constants.CertificationSolo,
+			Holder: &CertificationHolder{
+				RatingID: 4,
+				Values:   map[string]string{"tower": constants.CertificationCertified, "ground": constants.CertificationCertified},
+			},
+		},
+		{
+			Name:  "Breaks every rule",
+			Value: constants.CertificationSolo,
+			Holder: &CertificationHolder{
+				RatingID:       3,
+				Values:         map[string]string{"tower": constants.CertificationCertified, "ground": constants.CertificationSolo},
+				CertifiedSince: map[string]time.Time{"tower": now.Add(-10 * 24 * time.Hour)},
+			},
+			Expected: []string{
+				"requires a rating of S3 or above",
+				"requires tower certification for at least 30 days",
+				"requires ground certification",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			assert.Equal(t, test.Expected, CheckPrerequisites(approach, prerequisites, test.Value, test.Holder, ratings, now))
+		})
+	}
+}
+
+func TestCertifiedSince(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC)
+	}
+	changes := []*models.CertificationChange{
+		{Certification: "tower", OldValue: constants.CertificationCertified, NewValue: constants.CertificationCanTrain, CreatedAt: day(20)},
+		{Certification: "tower", OldValue: constants.CertificationSolo, NewValue: constants.CertificationCertified, CreatedAt: day(10)},
+		{Certification: "ground", OldValue: constants.CertificationNone, NewValue: constants.CertificationCertified, CreatedAt: day(1)},
+		{Certification: "ground", OldValue: constants.CertificationCertified, NewValue: constants.CertificationNone, CreatedAt: day(5)},
+	}
+
+	since := CertifiedSince(changes)
+	assert.Equal(t, map[string]time.Time{"tower": day(10)}, since)
+}
+
+func TestHasPrerequisiteCycle(t *testing.T) {
+	prerequisites := []*models.CertificationPrerequisite{
+		{Certification: "approach", Requires: "tower"},
+		{Certification: "tower", Requires: "ground"},
+		{Certification: "center", Requires: "approach"},
+		{Certification: "center", Requires: "ground"},
+	}
+	assert.False(t, HasPrerequisiteCycle(prerequisites))
+
+	prerequisites = append(prerequisites, &models.CertificationPrerequisite{Certification: "ground", Requires: "center"})
+	assert.True(t, HasPrerequisiteCycle(prerequisites))
+}
@@ -39,6 +39,12 @@ var Templates = map[string]string{
 	"visiting_removed":  "visiting_removed",
 	"inactive_warning":  "inactive_warning",
 	"inactive":          "inactive",
+	"solo_expiring":     "solo_expiring",
+	"solo_expired":      "solo_expired",
+	"event_assignment":  "event_assignment",
+	"event_reminder":    "event_reminder",
+	"event_update":      "event_update",
+	"event_cancelled":   "event_cancelled",
 }
 
 var log = logger.Logger.WithField("component", "email")
@@ -0,0 +1,359 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package events
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/adh-partnership/api/pkg/database/models/constants"
+)
+
+// Slot is an event position, or one shift of it, that the solver can fill
+type Slot struct {
+	ID       uint
+	ShiftID  uint
+	Position string
+	// When the slot is worked, zero if the requirements are checked without regard to time
+	Start time.Time
+	End   time.Time
+	// Certifications a controller must hold to work the position
+	Certifications []string
+	MinRatingID    int
+	// Short name of the minimum rating, used in violation messages
+	MinRating string
+}
+
+// Candidate is a controller that signed up for the event
+type Candidate struct {
+	CID      uint
+	RatingID int
+	Visitor  bool
+	// Positions the controller asked for, most preferred first
+	Choices        []string
+	Certifications map[string]string
+	// Window the controller said they are available for, open ended where nil
+	AvailableFrom  *time.Time
+	AvailableUntil *time.Time
+}
+
+type Assignment struct {
+	SlotID   uint   `json:"position_id"`
+	ShiftID  uint   `json:"shift_id,omitempty"`
+	Position string `json:"position"`
+	CID      *uint  `json:"cid"`
+	// Which of the controller's choices the position was, 0 if they did not ask for it
+	Choice int `json:"choice"`
+}
+
+type Solution struct {
+	Assignments []*Assignment `json:"assignments"`
+	Filled      int           `json:"filled"`
+	Unfilled    int           `json:"unfilled"`
+	// Number of slots that went to a controller's first, second and third choice
+	ByChoice map[int]int `json:"by_choice"`
+	Visitors int         `json:"visitors"`
+}
+
+// PositionCertification returns the certification needed to work a position, looked up in mapping by the full
+// callsign first and then by its facility type suffix, for example APP for DEN_APP. Returns an empty string if the
+// position does not need one.
+func PositionCertification(position string, mapping map[string]string) string {
+	position = strings.ToUpper(position)
+	if cert, ok := mapping[position]; ok {
+		return cert
+	}
+
+	if i := strings.LastIndex(position, "_"); i != -1 {
+		return mapping[position[i+1:]]
+	}
+
+	return ""
+}
+
+// Eligible returns true if the candidate holds every certification and the rating the slot needs, and is available
+// for all of it
+func Eligible(slot *Slot, candidate *Candidate) bool {
+	return len(Violations(slot, candidate)) == 0
+}
+
+// Violations returns the reasons the candidate cannot work the slot, empty if they can
+func Violations(slot *Slot, candidate *Candidate) []string {
+	violations := []string{}
+	if slot.MinRatingID > 0 && candidate.RatingID < slot.MinRatingID {
+		violations = append(violations, fmt.Sprintf("requires a rating of %s or above", slot.MinRating))
+	}
+	for _, cert := range slot.Certifications {
+		v := candidate.Certifications[cert]
+		if v != constants.CertificationCertified && v != constants.CertificationCanTrain {
+			violations = append(violations, fmt.Sprintf("requires %s certification", cert))
+		}
+	}
+	if !slot.Start.IsZero() && !Available(candidate.AvailableFrom, candidate.AvailableUntil, slot.Start, slot.End) {
+		violations = append(violations, fmt.Sprintf("not available from %s to %s",
+			slot.Start.UTC().Format("15:04Z"), slot.End.UTC().Format("15:04Z")))
+	}
+
+	return violations
+}
+
+// choiceRank returns which of the candidate's choices the position is, 0 if it is not one of them
+func choiceRank(slot *Slot, candidate *Candidate) int {
+	for i, choice := range candidate.Choices {
+		if strings.EqualFold(choice, slot.Position) {
+			return i + 1
+		}
+	}
+
+	return 0
+}
+
+// Solve proposes an assignment of candidates to slots, a candidate working any number of slots that do not overlap.
+// Slots are filled in rounds in which each candidate takes at most one more slot, so every candidate gets a slot
+// before anyone gets a second. Each round fills as many slots as possible with eligible candidates and, among those
+// rosters, gives controllers the positions they preferred most. Where several rosters satisfy preferences equally,
+// the one whose share of visiting controllers is closest to their share of the signups is picked.
+func Solve(slots []*Slot, candidates []*Candidate) *Solution {
+	visitors := 0
+	for _, c := range candidates {
+		if c.Visitor {
+			visitors++
+		}
+	}
+
+	var best *Solution
+	bestDistance := math.Inf(1)
+	for favoured := 0; favoured <= visitors; favoured++ {
+		solution := solve(slots, candidates, favoured)
+		if visitors == 0 || solution.Filled == 0 {
+			return solution
+		}
+
+		distance := math.Abs(float64(solution.Visitors)/float64(solution.Filled) - float64(visitors)/float64(len(candidates)))
+		if best == nil || distance < bestDistance {
+			best = solution
+			bestDistance = distance
+		}
+	}
+
+	return best
+}
+
+// solve runs the matching, breaking ties between equally preferred rosters in favour of the first favoured visiting
+// controllers and against the remaining ones, which lets Solve steer how many visitors end up on the roster.
+func solve(slots []*Slot, candidates []*Candidate, favoured int) *Solution {
+	// Weights are layered so that filling a slot beats any gain in preferences, which in turn beats any tie break
+	preferenceWeight := int64(2*len(candidates) + 1)
+	fillWeight := preferenceWeight * int64(3*len(candidates)+1)
+
+	bias := make([]int64, len(candidates))
+	visitor := 0
+	for j, c := range candidates {
+		if !c.Visitor {
+			continue
+		}
+		bias[j] = -1
+		if visitor < favoured {
+			bias[j] = 1
+		}
+		visitor++
+	}
+
+	proposed := map[*Slot]uint{}
+	taken := make([][]*Slot, len(candidates))
+	open := slots
+	for len(open) > 0 {
+		n := len(open)
+		if len(candidates) > n {
+			n = len(candidates)
+		}
+		weights := make([][]int64, n)
+		for i := range weights {
+			weights[i] = make([]int64, n)
+			if i >= len(open) {
+				continue
+			}
+			for j, c := range candidates {
+				if Eligible(open[i], c) && !overlapsAny(open[i], taken[j]) {
+					weights[i][j] = fillWeight + preferenceWeight*int64(preferenceScore(choiceRank(open[i], c))) + bias[j]
+				}
+			}
+		}
+
+		match := maxWeightMatching(weights)
+
+		var remaining []*Slot
+		for i, slot := range open {
+			if j := match[i]; j < len(candidates) && weights[i][j] > 0 {
+				proposed[slot] = candidates[j].CID
+				taken[j] = append(taken[j], slot)
+			} else {
+				remaining = append(remaining, slot)
+			}
+		}
+		if len(remaining) == len(open) {
+			break
+		}
+		open = remaining
+	}
+
+	return Summarize(slots, candidates, proposed)
+}
+
+// Summarize builds the solution for a roster, proposed mapping slots to the CID of the controller working them
+func Summarize(slots []*Slot, candidates []*Candidate, proposed map[*Slot]uint) *Solution {
+	byCID := map[uint]*Candidate{}
+	for _, c := range candidates {
+		byCID[c.CID] = c
+	}
+
+	sorted := append([]*Slot{}, slots...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Position == sorted[j].Position {
+			return sorted[i].Start.Before(sorted[j].Start)
+		}
+		return sorted[i].Position < sorted[j].Position
+	})
+
+	solution := &Solution{Assignments: []*Assignment{}, ByChoice: map[int]int{1: 0, 2: 0, 3: 0}}
+	for _, slot := range sorted {
+		a := &Assignment{SlotID: slot.ID, ShiftID: slot.ShiftID, Position: slot.Position}
+		if cid, ok := proposed[slot]; ok {
+			a.CID = &cid
+			solution.Filled++
+			if c, ok := byCID[cid]; ok {
+				a.Choice = choiceRank(slot, c)
+				if c.Visitor {
+					solution.Visitors++
+				}
+			}
+			if a.Choice > 0 {
+				solution.ByChoice[a.Choice]++
+			}
+		} else {
+			solution.Unfilled++
+		}
+		solution.Assignments = append(solution.Assignments, a)
+	}
+
+	return solution
+}
+
+// overlapsAny returns true if the slot overlaps any of the others. Slots without a time take the whole event and
+// overlap everything.
+func overlapsAny(slot *Slot, others []*Slot) bool {
+	for _, other := range others {
+		if slot.Start.IsZero() || other.Start.IsZero() || (slot.Start.Before(other.End) && other.Start.Before(slot.End)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func preferenceScore(choice int) int {
+	if choice == 0 {
+		return 0
+	}
+
+	return 4 - choice
+}
+
+// maxWeightMatching returns, for each row of the square weight matrix, the column it is matched to so that the total
+// weight is as large as possible. It is the Hungarian algorithm run on the costs max - weight.
+func maxWeightMatching(weights [][]int64) []int {
+	n := len(weights)
+	if n == 0 {
+		return nil
+	}
+
+	var maxWeight int64
+	for _, row := range weights {
+		for _, w := range row {
+			if w > maxWeight {
+				maxWeight = w
+			}
+		}
+	}
+
+	const inf = math.MaxInt64 / 4
+	u := make([]int64, n+1)
+	v := make([]int64, n+1)
+	p := make([]int, n+1)
+	way := make([]int, n+1)
+	for i := 1; i <= n; i++ {
+		p[0] = i
+		j0 := 0
+		minv := make([]int64, n+1)
+		used := make([]bool, n+1)
+		for j := range minv {
+			minv[j] = inf
+		}
+		for {
+			used[j0] = true
+			i0 := p[j0]
+			delta := int64(inf)
+			j1 := 0
+			for j := 1; j <= n; j++ {
+				if used[j] {
+					continue
+				}
+				cur := maxWeight - weights[i0-1][j-1] - u[i0] - v[j]
+				if cur < minv[j] {
+					minv[j] = cur
+					way[j] = j0
+				}
+				if minv[j] < delta {
+					delta = minv[j]
+					j1 = j
+				}
+			}
+			for j := 0; j <= n; j++ {
+				if used[j] {
+					u[p[j]] += delta
+					v[j] -= delta
+				} else {
+					minv[j] -= delta
+				}
+			}
+			j0 = j1
+			if p[j0] == 0 {
+				break
+			}
+		}
+		for {
+			j1 := way[j0]
+			p[j0] = p[j1]
+			j0 = j1
+			if j0 == 0 {
+				break
+			}
+		}
+	}
+
+	match := make([]int, n)
+	for j := 1; j <= n; j++ {
+		if p[j] > 0 {
+			match[p[j]-1] = j - 1
+		}
+	}
+
+	return match
+}
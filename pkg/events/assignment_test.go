@@ -0,0 +1,168 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package events
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/adh-partnership/api/pkg/database/models/constants"
+)
+
+var certified = map[string]string{"ground": constants.CertificationCertified, "tower": constants.CertificationCertified}
+
+func assigned(solution *Solution) map[string]uint {
+	ret := map[string]uint{}
+	for _, a := range solution.Assignments {
+		if a.CID != nil {
+			ret[a.Position] = *a.CID
+		}
+	}
+	return ret
+}
+
+func TestPositionCertification(t *testing.T) {
+	mapping := map[string]string{"TWR": "tower", "DEN_APP": "major"}
+	assert.Equal(t, "tower", PositionCertification("den_twr", mapping))
+	assert.Equal(t, "major", PositionCertification("DEN_APP", mapping))
+	assert.Equal(t, "", PositionCertification("COS_APP", mapping))
+	assert.Equal(t, "", PositionCertification("OBS", mapping))
+}
+
+func TestViolations(t *testing.T) {
+	slot := &Slot{Position: "DEN_APP", Certifications: []string{"approach"}, MinRatingID: 4, MinRating: "S3"}
+
+	assert.Equal(t, []string{"requires a rating of S3 or above", "requires approach certification"},
+		Violations(slot, &Candidate{RatingID: 3, Certifications: certified}))
+	assert.Empty(t, Violations(slot, &Candidate{RatingID: 5, Certifications: map[string]string{"approach": constants.CertificationCertified}}))
+	assert.False(t, Eligible(slot, &Candidate{RatingID: 5, Certifications: map[string]string{"approach": constants.CertificationSolo}}))
+}
+
+func TestSolvePreferences(t *testing.T) {
+	slots := []*Slot{{ID: 1, Position: "DEN_GND"}, {ID: 2, Position: "DEN_TWR"}}
+	candidates := []*Candidate{
+		{CID: 10, Choices: []string{"DEN_TWR", "DEN_GND"}, Certifications: certified},
+		{CID: 11, Choices: []string{"DEN_TWR"}, Certifications: certified},
+	}
+
+	// Either roster gives one first choice, so the second candidate only getting a position they did not ask for
+	// loses to giving the first candidate their second choice
+	solution := Solve(slots, candidates)
+	assert.Equal(t, map[string]uint{"DEN_GND": 10, "DEN_TWR": 11}, assigned(solution))
+	assert.Equal(t, 2, solution.Filled)
+	assert.Equal(t, 0, solution.Unfilled)
+	assert.Equal(t, 1, solution.ByChoice[1])
+	assert.Equal(t, 1, solution.ByChoice[2])
+}
+
+func TestSolveEligibility(t *testing.T) {
+	slots := []*Slot{
+		{ID: 1, Position: "DEN_GND", Certifications: []string{"ground"}},
+		{ID: 2, Position: "DEN_TWR", Certifications: []string{"tower"}},
+	}
+	candidates := []*Candidate{
+		{CID: 10, Choices: []string{"DEN_TWR"}, Certifications: map[string]string{"ground": constants.CertificationCertified}},
+		{CID: 11, Choices: []string{"DEN_GND"}, Certifications: map[string]string{"tower": constants.CertificationCanTrain}},
+		{CID: 12, Choices: []string{"DEN_TWR"}, Certifications: map[string]string{"tower": constants.CertificationSolo}},
+	}
+
+	// Filling both positions beats anyone getting their first choice
+	solution := Solve(slots, candidates)
+	assert.Equal(t, map[string]uint{"DEN_GND": 10, "DEN_TWR": 11}, assigned(solution))
+	assert.Equal(t, 0, solution.ByChoice[1])
+}
+
+func TestSolveUnfilled(t *testing.T) {
+	slots := []*Slot{{ID: 1, Position: "DEN_GND"}, {ID: 2, Position: "DEN_CTR", Certifications: []string{"enroute"}}}
+	candidates := []*Candidate{{CID: 10, Choices: []string{"DEN_CTR"}, Certifications: certified}}
+
+	solution := Solve(slots, candidates)
+	assert.Equal(t, map[string]uint{"DEN_GND": 10}, assigned(solution))
+	assert.Equal(t, 1, solution.Unfilled)
+	assert.Len(t, solution.Assignments, 2)
+
+	solution = Solve(slots, nil)
+	assert.Equal(t, 2, solution.Unfilled)
+}
+
+func TestSolveVisitorBalance(t *testing.T) {
+	slots := []*Slot{{ID: 1, Position: "DEN_GND"}, {ID: 2, Position: "DEN_TWR"}}
+	candidates := []*Candidate{
+		{CID: 10, Choices: []string{"DEN_GND"}, Certifications: certified},
+		{CID: 11, Choices: []string{"DEN_GND"}, Certifications: certified},
+		{CID: 20, Visitor: true, Choices: []string{"DEN_GND"}, Certifications: certified},
+		{CID: 21, Visitor: true, Choices: []string{"DEN_GND"}, Certifications: certified},
+	}
+
+	// Half of the signups are visitors, so one of the two positions should go to a visitor
+	solution := Solve(slots, candidates)
+	assert.Equal(t, 2, solution.Filled)
+	assert.Equal(t, 1, solution.Visitors)
+	assert.Equal(t, 1, solution.ByChoice[1])
+}
+
+func TestSolveShifts(t *testing.T) {
+	slots := []*Slot{
+		{ID: 1, ShiftID: 2, Position: "DEN_APP", Start: at(20), End: at(22)},
+		{ID: 1, ShiftID: 1, Position: "DEN_APP", Start: at(18), End: at(20)},
+	}
+	candidates := []*Candidate{
+		{CID: 10, Choices: []string{"DEN_APP"}, Certifications: certified, AvailableUntil: ptr(at(20))},
+		{CID: 11, Choices: []string{"DEN_APP"}, Certifications: certified},
+	}
+
+	solution := Solve(slots, candidates)
+	assert.Equal(t, 2, solution.Filled)
+	assert.Equal(t, uint(1), solution.Assignments[0].ShiftID)
+	assert.Equal(t, uint(10), *solution.Assignments[0].CID)
+	assert.Equal(t, uint(2), solution.Assignments[1].ShiftID)
+	assert.Equal(t, uint(11), *solution.Assignments[1].CID)
+
+	assert.Equal(t, []string{"not available from 20:00Z to 22:00Z"}, Violations(slots[0], candidates[0]))
+}
+
+func TestSolveSeveralSlots(t *testing.T) {
+	slots := []*Slot{
+		{ID: 1, ShiftID: 1, Position: "DEN_APP", Start: at(18), End: at(20)},
+		{ID: 1, ShiftID: 2, Position: "DEN_APP", Start: at(20), End: at(22)},
+		{ID: 2, ShiftID: 3, Position: "DEN_TWR", Start: at(19), End: at(21)},
+	}
+	candidates := []*Candidate{{CID: 10, Choices: []string{"DEN_APP"}, Certifications: certified}}
+
+	// Both shifts of the approach go to the only candidate, the tower shift overlaps them
+	solution := Solve(slots, candidates)
+	assert.Equal(t, 2, solution.Filled)
+	assert.Equal(t, 1, solution.Unfilled)
+	assert.Equal(t, 2, solution.ByChoice[1])
+	for _, a := range solution.Assignments {
+		if a.Position == "DEN_TWR" {
+			assert.Nil(t, a.CID)
+		} else {
+			assert.Equal(t, uint(10), *a.CID)
+		}
+	}
+
+	// With a second candidate everyone gets a slot before anyone gets a second, and the tower is filled too
+	candidates = append(candidates, &Candidate{CID: 11, Choices: []string{"DEN_TWR"}, Certifications: certified})
+	solution = Solve(slots, candidates)
+	assert.Equal(t, 3, solution.Filled)
+
+	// Slots without a time take the whole event
+	solution = Solve([]*Slot{{ID: 1, Position: "DEN_GND"}, {ID: 2, Position: "DEN_TWR"}}, candidates[:1])
+	assert.Equal(t, 1, solution.Filled)
+}
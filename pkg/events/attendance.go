@@ -0,0 +1,117 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package events
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	AttendanceAttended          = "attended"
+	AttendanceLate              = "late"
+	AttendanceLeftEarly         = "left_early"
+	AttendanceNoShow            = "no_show"
+	AttendanceDifferentPosition = "different_position"
+)
+
+// Session is a span of time a controller was connected to the network on a callsign
+type Session struct {
+	Callsign string
+	Start    time.Time
+	End      time.Time
+}
+
+// Attendance is how a controller worked a booking
+type Attendance struct {
+	Status string
+	// Time spent on the booked position within the booking
+	Worked time.Duration
+	// First logon and last logoff on the booked position that overlap the booking, nil if there were none
+	LoggedOn  *time.Time
+	LoggedOff *time.Time
+	// Callsigns other than the booked position worked during the booking
+	OtherPositions []string
+}
+
+// SamePosition returns true if the callsign is the event position, allowing for a different sector or relief
+// identifier between the facility and the type, so DEN_N_APP and DEN_1_APP both work DEN_APP.
+func SamePosition(position, callsign string) bool {
+	if strings.EqualFold(position, callsign) {
+		return true
+	}
+
+	p := strings.Split(strings.ToUpper(position), "_")
+	c := strings.Split(strings.ToUpper(callsign), "_")
+	return len(p) == 2 && len(c) == 3 && p[0] == c[0] && p[1] == c[2]
+}
+
+// Attend classifies how the sessions of a controller cover their booking. A controller that logged on more than
+// grace after the start is late, otherwise one that logged off more than grace before the end left early. Late
+// takes precedence when both are true. A controller with no session on the booked position is a no show unless they
+// worked another position during the booking.
+func Attend(booking *Booking, sessions []*Session, grace time.Duration) *Attendance {
+	ret := &Attendance{Status: AttendanceNoShow}
+
+	others := map[string]bool{}
+	for _, s := range sessions {
+		if !Overlaps(s.Start, s.End, booking.Start, booking.End) {
+			continue
+		}
+
+		if !SamePosition(booking.Position, s.Callsign) {
+			if !others[s.Callsign] {
+				others[s.Callsign] = true
+				ret.OtherPositions = append(ret.OtherPositions, s.Callsign)
+			}
+			continue
+		}
+
+		start, end := s.Start, s.End
+		if ret.LoggedOn == nil || start.Before(*ret.LoggedOn) {
+			ret.LoggedOn = &start
+		}
+		if ret.LoggedOff == nil || end.After(*ret.LoggedOff) {
+			ret.LoggedOff = &end
+		}
+
+		if start.Before(booking.Start) {
+			start = booking.Start
+		}
+		if end.After(booking.End) {
+			end = booking.End
+		}
+		ret.Worked += end.Sub(start)
+	}
+	sort.Strings(ret.OtherPositions)
+
+	switch {
+	case ret.LoggedOn == nil && len(ret.OtherPositions) > 0:
+		ret.Status = AttendanceDifferentPosition
+	case ret.LoggedOn == nil:
+		ret.Status = AttendanceNoShow
+	case ret.LoggedOn.After(booking.Start.Add(grace)):
+		ret.Status = AttendanceLate
+	case ret.LoggedOff.Before(booking.End.Add(-grace)):
+		ret.Status = AttendanceLeftEarly
+	default:
+		ret.Status = AttendanceAttended
+	}
+
+	return ret
+}
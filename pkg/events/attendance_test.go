@@ -0,0 +1,120 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package events
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSamePosition(t *testing.T) {
+	tests := []struct {
+		position string
+		callsign string
+		want     bool
+	}{
+		{"DEN_APP", "DEN_APP", true},
+		{"DEN_APP", "den_app", true},
+		{"DEN_APP", "DEN_N_APP", true},
+		{"DEN_APP", "DEN_1_APP", true},
+		{"DEN_APP", "DEN_TWR", false},
+		{"DEN_APP", "COS_APP", false},
+		{"DEN_N_APP", "DEN_S_APP", false},
+		{"DEN_N_APP", "DEN_N_APP", true},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.want, SamePosition(test.position, test.callsign), "%s/%s", test.position, test.callsign)
+	}
+}
+
+func TestAttend(t *testing.T) {
+	booking := &Booking{Position: "DEN_APP", Start: at(18), End: at(21)}
+	grace := 15 * time.Minute
+
+	tests := []struct {
+		name     string
+		sessions []*Session
+		status   string
+		worked   time.Duration
+		others   []string
+	}{
+		{
+			name:     "attended, early and late margins are not counted",
+			sessions: []*Session{{Callsign: "DEN_N_APP", Start: at(17), End: at(22)}},
+			status:   AttendanceAttended,
+			worked:   3 * time.Hour,
+		},
+		{
+			name:     "logged on inside the grace period",
+			sessions: []*Session{{Callsign: "DEN_APP", Start: at(18).Add(10 * time.Minute), End: at(21)}},
+			status:   AttendanceAttended,
+			worked:   2*time.Hour + 50*time.Minute,
+		},
+		{
+			name:     "late",
+			sessions: []*Session{{Callsign: "DEN_APP", Start: at(19), End: at(21)}},
+			status:   AttendanceLate,
+			worked:   2 * time.Hour,
+		},
+		{
+			name:     "left early",
+			sessions: []*Session{{Callsign: "DEN_APP", Start: at(18), End: at(20)}},
+			status:   AttendanceLeftEarly,
+			worked:   2 * time.Hour,
+		},
+		{
+			name: "reconnected mid-event",
+			sessions: []*Session{
+				{Callsign: "DEN_APP", Start: at(18), End: at(19)},
+				{Callsign: "DEN_APP", Start: at(19).Add(5 * time.Minute), End: at(21)},
+			},
+			status: AttendanceAttended,
+			worked: 2*time.Hour + 55*time.Minute,
+		},
+		{
+			name: "worked another position",
+			sessions: []*Session{
+				{Callsign: "DEN_TWR", Start: at(18), End: at(21)},
+				{Callsign: "DEN_GND", Start: at(18), End: at(19)},
+				{Callsign: "DEN_TWR", Start: at(20), End: at(21)},
+			},
+			status: AttendanceDifferentPosition,
+			others: []string{"DEN_GND", "DEN_TWR"},
+		},
+		{
+			name:     "session outside the booking is a no show",
+			sessions: []*Session{{Callsign: "DEN_APP", Start: at(12), End: at(18)}},
+			status:   AttendanceNoShow,
+		},
+		{
+			name:   "no sessions",
+			status: AttendanceNoShow,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := Attend(booking, test.sessions, grace)
+			assert.Equal(t, test.status, got.Status)
+			assert.Equal(t, test.worked, got.Worked)
+			assert.Equal(t, test.others, got.OtherPositions)
+		})
+	}
+}
@@ -0,0 +1,91 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package events
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/adh-partnership/api/pkg/config"
+	"github.com/adh-partnership/api/pkg/database/models"
+)
+
+// HostFacility returns the facility of this instance, which hosts the events created on it
+func HostFacility() string {
+	return strings.ToUpper(config.Cfg.VATUSA.Facility)
+}
+
+// NormalizeFacility upper cases a facility ID, returning an error if it is not a valid one
+func NormalizeFacility(facility string) (string, error) {
+	facility = strings.ToUpper(strings.TrimSpace(facility))
+	if len(facility) < 3 || len(facility) > 4 {
+		return "", fmt.Errorf("invalid facility %q", facility)
+	}
+	for _, r := range facility {
+		if (r < 'A' || r > 'Z') && (r < '0' || r > '9') {
+			return "", fmt.Errorf("invalid facility %q", facility)
+		}
+	}
+
+	return facility, nil
+}
+
+// PartnerFacilities returns the co-hosting facilities of an event from a request, without duplicates and without
+// the host facility which always participates
+func PartnerFacilities(host string, facilities []string) ([]*models.EventFacility, error) {
+	ret := []*models.EventFacility{}
+	seen := map[string]bool{host: true}
+	for _, f := range facilities {
+		facility, err := NormalizeFacility(f)
+		if err != nil {
+			return nil, err
+		}
+		if seen[facility] {
+			continue
+		}
+		seen[facility] = true
+		ret = append(ret, &models.EventFacility{Facility: facility})
+	}
+
+	return ret, nil
+}
+
+// Participates returns if a facility co-hosts an event, the host facility always does
+func Participates(event *models.Event, host, facility string) bool {
+	if facility == "" {
+		return false
+	}
+	if facility == host {
+		return true
+	}
+	for _, f := range event.Facilities {
+		if f.Facility == facility {
+			return true
+		}
+	}
+
+	return false
+}
+
+// PositionFacility returns the facility staffing a position, positions not tagged with one belong to the host
+func PositionFacility(position *models.EventPosition, host string) string {
+	if position.Facility == "" {
+		return host
+	}
+
+	return position.Facility
+}
@@ -0,0 +1,77 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package events
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/adh-partnership/api/pkg/database/models"
+)
+
+func TestNormalizeFacility(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		err  bool
+	}{
+		{in: "zlc", want: "ZLC"},
+		{in: " ZDV ", want: "ZDV"},
+		{in: "ZAN1", want: "ZAN1"},
+		{in: "ZD", err: true},
+		{in: "ZDVXX", err: true},
+		{in: "Z-V", err: true},
+		{in: "", err: true},
+	}
+	for _, tt := range tests {
+		got, err := NormalizeFacility(tt.in)
+		if tt.err {
+			assert.Error(t, err, tt.in)
+			continue
+		}
+		assert.NoError(t, err, tt.in)
+		assert.Equal(t, tt.want, got)
+	}
+}
+
+func TestPartnerFacilities(t *testing.T) {
+	facilities, err := PartnerFacilities("ZDV", []string{"zlc", "ZDV", "ZLC", "ZAB"})
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.EventFacility{{Facility: "ZLC"}, {Facility: "ZAB"}}, facilities)
+
+	_, err = PartnerFacilities("ZDV", []string{"ZLC", "nope!"})
+	assert.Error(t, err)
+
+	facilities, err = PartnerFacilities("ZDV", nil)
+	assert.NoError(t, err)
+	assert.Empty(t, facilities)
+}
+
+func TestParticipates(t *testing.T) {
+	event := &models.Event{Facilities: []*models.EventFacility{{Facility: "ZLC"}}}
+
+	assert.True(t, Participates(event, "ZDV", "ZDV"))
+	assert.True(t, Participates(event, "ZDV", "ZLC"))
+	assert.False(t, Participates(event, "ZDV", "ZAB"))
+	assert.False(t, Participates(event, "ZDV", ""))
+}
+
+func TestPositionFacility(t *testing.T) {
+	assert.Equal(t, "ZDV", PositionFacility(&models.EventPosition{}, "ZDV"))
+	assert.Equal(t, "ZLC", PositionFacility(&models.EventPosition{Facility: "ZLC"}, "ZDV"))
+}
@@ -0,0 +1,106 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package events
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/adh-partnership/api/pkg/database/models"
+)
+
+const displayTime = "2006-01-02 15:04Z"
+
+// ControllerAssignments returns the positions each controller is assigned to in the event, keyed by CID, with the
+// times of shifts
+func ControllerAssignments(event *models.Event) map[uint][]string {
+	ret := map[uint][]string{}
+	for _, position := range event.Positions {
+		if len(position.Shifts) == 0 {
+			if position.UserID != nil {
+				ret[*position.UserID] = append(ret[*position.UserID], position.Position)
+			}
+			continue
+		}
+
+		for _, shift := range position.Shifts {
+			if shift.UserID != nil {
+				ret[*shift.UserID] = append(ret[*shift.UserID], SlotName(position, shift))
+			}
+		}
+	}
+
+	for cid := range ret {
+		sort.Strings(ret[cid])
+	}
+
+	return ret
+}
+
+// SlotName returns the name of the position, followed by the times of the shift if there is one
+func SlotName(position *models.EventPosition, shift *models.EventShift) string {
+	if shift == nil {
+		return position.Position
+	}
+
+	return fmt.Sprintf("%s %s-%s", position.Position, shift.StartsAt.UTC().Format("1504Z"), shift.EndsAt.UTC().Format("1504Z"))
+}
+
+// DueReminder returns which of the reminders, given in hours before the start of an event, should be sent now. Only
+// the closest reminder is sent if several are due, and a reminder is skipped once one closer to the start was sent.
+func DueReminder(start, now time.Time, hours []int, sent []int) (int, bool) {
+	if !now.Before(start) {
+		return 0, false
+	}
+
+	due, found := 0, false
+	for _, h := range hours {
+		if h <= 0 || now.Before(start.Add(-time.Duration(h)*time.Hour)) {
+			continue
+		}
+
+		covered := false
+		for _, s := range sent {
+			if s <= h {
+				covered = true
+				break
+			}
+		}
+		if !covered && (!found || h < due) {
+			due, found = h, true
+		}
+	}
+
+	return due, found
+}
+
+// Changes describes what changed about an event that controllers working it should know about
+func Changes(before, after *models.Event) []string {
+	ret := []string{}
+	if before.Title != after.Title {
+		ret = append(ret, fmt.Sprintf("Renamed to %s", after.Title))
+	}
+	if !before.StartDate.Equal(after.StartDate) {
+		ret = append(ret, fmt.Sprintf("Now starts at %s", after.StartDate.UTC().Format(displayTime)))
+	}
+	if !before.EndDate.Equal(after.EndDate) {
+		ret = append(ret, fmt.Sprintf("Now ends at %s", after.EndDate.UTC().Format(displayTime)))
+	}
+
+	return ret
+}
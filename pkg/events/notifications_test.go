@@ -0,0 +1,81 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package events
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/adh-partnership/api/pkg/database/models"
+)
+
+func TestControllerAssignments(t *testing.T) {
+	event := &models.Event{
+		Positions: []*models.EventPosition{
+			{Position: "DEN_TWR", UserID: ptr(uint(10))},
+			{Position: "DEN_GND"},
+			{Position: "DEN_APP", UserID: ptr(uint(11)), Shifts: []*models.EventShift{
+				{StartsAt: at(18), EndsAt: at(20), UserID: ptr(uint(10))},
+				{StartsAt: at(20), EndsAt: at(22)},
+			}},
+		},
+	}
+
+	assert.Equal(t, map[uint][]string{10: {"DEN_APP 1800Z-2000Z", "DEN_TWR"}}, ControllerAssignments(event))
+}
+
+func TestDueReminder(t *testing.T) {
+	start := at(23)
+	tests := []struct {
+		Name     string
+		Now      int
+		Sent     []int
+		Expected int
+		Found    bool
+	}{
+		{Name: "Too early", Now: 0, Expected: 0, Found: false},
+		{Name: "First reminder", Now: 13, Expected: 10, Found: true},
+		{Name: "First reminder already sent", Now: 14, Sent: []int{10}, Expected: 0, Found: false},
+		{Name: "Second reminder", Now: 22, Sent: []int{10}, Expected: 1, Found: true},
+		{Name: "Both due sends the closest", Now: 22, Expected: 1, Found: true},
+		{Name: "Closer reminder covers earlier", Now: 22, Sent: []int{1}, Expected: 0, Found: false},
+		{Name: "Event started", Now: 23, Expected: 0, Found: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			due, found := DueReminder(start, at(test.Now), []int{10, 1}, test.Sent)
+			assert.Equal(t, test.Expected, due)
+			assert.Equal(t, test.Found, found)
+		})
+	}
+}
+
+func TestChanges(t *testing.T) {
+	before := &models.Event{Title: "FNO", StartDate: at(18), EndDate: at(22)}
+
+	assert.Empty(t, Changes(before, &models.Event{Title: "FNO", StartDate: at(18), EndDate: at(22), Description: "New"}))
+	assert.Equal(t, []string{"Renamed to Denver FNO", "Now starts at 2024-01-06 19:00Z"},
+		Changes(before, &models.Event{Title: "Denver FNO", StartDate: at(19), EndDate: at(22)}))
+}
+
+func TestChunkLines(t *testing.T) {
+	assert.Equal(t, []string{"one\ntwo", "three"}, chunkLines([]string{"one", "two", "three"}, 8))
+	assert.Equal(t, []string{"abc"}, chunkLines([]string{"abcdef"}, 3))
+	assert.Empty(t, chunkLines(nil, 8))
+}
@@ -0,0 +1,209 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package events
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/adh-partnership/api/pkg/config"
+	"github.com/adh-partnership/api/pkg/database"
+	"github.com/adh-partnership/api/pkg/database/models"
+	"github.com/adh-partnership/api/pkg/database/models/constants"
+	"github.com/adh-partnership/api/pkg/discord"
+	"github.com/adh-partnership/api/pkg/email"
+	"github.com/adh-partnership/api/pkg/logger"
+)
+
+var log = logger.Logger.WithField("component", "events")
+
+var notificationTemplates = map[string]string{
+	constants.EventNotificationAssignment: email.Templates["event_assignment"],
+	constants.EventNotificationReminder:   email.Templates["event_reminder"],
+	constants.EventNotificationUpdate:     email.Templates["event_update"],
+	constants.EventNotificationCancelled:  email.Templates["event_cancelled"],
+	constants.EventNotificationRemoved:    email.Templates["event_update"],
+}
+
+// NotificationsEnabled returns true if event notifications are configured to be sent
+func NotificationsEnabled() bool {
+	return config.Cfg.Facility.Events.Notifications.Enabled
+}
+
+// Announce posts the event to the announcement webhook, content saying why
+func Announce(event *models.Event, content string) {
+	embed := discord.NewEmbed().
+		SetTitle(event.Title).
+		SetDescription(event.Description).
+		AddField(discord.NewField().SetName("Starts").SetValue(event.StartDate.UTC().Format(displayTime)).SetInline(true)).
+		AddField(discord.NewField().SetName("Ends").SetValue(event.EndDate.UTC().Format(displayTime)).SetInline(true))
+	if url := eventURL(event); url != "" {
+		embed.SetURL(url)
+	}
+	if event.Banner != "" {
+		embed.SetImage(&discord.Image{URL: &event.Banner})
+	}
+
+	if err := discord.NewMessage().SetContent(content).AddEmbed(embed).Send(config.Cfg.Facility.Events.Notifications.AnnouncementWebhook); err != nil {
+		log.Warnf("Error sending event announcement to Discord: %s", err)
+	}
+}
+
+// NotifyStaff posts a message about the event, with a line for each detail, to the events staff webhook
+func NotifyStaff(event *models.Event, content string, details []string) {
+	embed := discord.NewEmbed().
+		SetTitle(event.Title).
+		SetDescription(strings.Join(details, "\n")).
+		AddField(discord.NewField().SetName("Starts").SetValue(event.StartDate.UTC().Format(displayTime)).SetInline(true))
+	if url := eventURL(event); url != "" {
+		embed.SetURL(url)
+	}
+
+	if err := discord.NewMessage().SetContent(content).AddEmbed(embed).Send(config.Cfg.Facility.Events.Notifications.StaffWebhook); err != nil {
+		log.Warnf("Error sending event staff notification to Discord: %s", err)
+	}
+}
+
+// AnnounceNew announces a newly created event and records that it was announced
+func AnnounceNew(event *models.Event) {
+	Announce(event, "New event")
+
+	now := time.Now()
+	event.AnnouncedAt = &now
+	if err := database.DB.Model(&models.Event{}).Where("id = ?", event.ID).Update("announced_at", now).Error; err != nil {
+		log.Errorf("Error marking event %d as announced: %s", event.ID, err)
+	}
+}
+
+// NotifyControllers tells controllers about an event they work, by email if enabled and by mentioning them on the
+// assignment webhook, and records that they were told. assignments are the positions of each controller as returned
+// by ControllerAssignments, and hours is how long before the start a reminder is for.
+func NotifyControllers(event *models.Event, users []*models.User, assignments map[uint][]string, kind string, hours int, content string) {
+	if len(users) == 0 {
+		return
+	}
+
+	var mentions []string
+	for _, user := range users {
+		positions := strings.Join(assignments[user.CID], ", ")
+		if config.Cfg.Facility.Events.Notifications.Email && user.Email != "" {
+			err := email.Send(user.Email, "", "", notificationTemplates[kind], map[string]interface{}{
+				"FirstName":  user.FirstName,
+				"LastName":   user.LastName,
+				"EventTitle": event.Title,
+				"StartDate":  event.StartDate.UTC().Format(displayTime),
+				"EndDate":    event.EndDate.UTC().Format(displayTime),
+				"Positions":  positions,
+				"Content":    content,
+				"URL":        eventURL(event),
+			})
+			if err != nil {
+				log.Errorf("Error sending %s email for event %d to %d: %s", kind, event.ID, user.CID, err)
+			}
+		}
+
+		mention := fmt.Sprintf("%s %s", user.FirstName, user.LastName)
+		if user.DiscordID != "" {
+			mention = fmt.Sprintf("<@%s>", user.DiscordID)
+		}
+		if positions != "" {
+			mention += ": " + positions
+		}
+		mentions = append(mentions, mention)
+
+		if err := database.DB.Create(&models.EventNotification{EventID: event.ID, CID: user.CID, Kind: kind, Hours: hours}).Error; err != nil {
+			log.Errorf("Error recording %s notification for event %d to %d: %s", kind, event.ID, user.CID, err)
+		}
+	}
+
+	embed := discord.NewEmbed().
+		SetTitle(event.Title).
+		AddField(discord.NewField().SetName("Starts").SetValue(event.StartDate.UTC().Format(displayTime)).SetInline(true)).
+		AddField(discord.NewField().SetName("Ends").SetValue(event.EndDate.UTC().Format(displayTime)).SetInline(true))
+	if url := eventURL(event); url != "" {
+		embed.SetURL(url)
+	}
+
+	// Mentions only notify when they are in the content, which Discord limits in length
+	for i, chunk := range chunkLines(append([]string{content}, mentions...), 2000) {
+		msg := discord.NewMessage().SetContent(chunk)
+		if i == 0 {
+			msg.AddEmbed(embed)
+		}
+		if err := msg.Send(config.Cfg.Facility.Events.Notifications.AssignmentWebhook); err != nil {
+			log.Warnf("Error sending event %s message to Discord: %s", kind, err)
+			return
+		}
+	}
+}
+
+// AssignedControllers returns the controllers assigned to a position or shift of the event
+func AssignedControllers(event *models.Event) []*models.User {
+	seen := map[uint]bool{}
+	var ret []*models.User
+	add := func(user *models.User) {
+		if user != nil && !seen[user.CID] {
+			seen[user.CID] = true
+			ret = append(ret, user)
+		}
+	}
+
+	for _, position := range event.Positions {
+		if len(position.Shifts) == 0 {
+			add(position.User)
+			continue
+		}
+		for _, shift := range position.Shifts {
+			add(shift.User)
+		}
+	}
+
+	return ret
+}
+
+// chunkLines joins lines into messages of at most limit characters
+func chunkLines(lines []string, limit int) []string {
+	var ret []string
+	current := ""
+	for _, line := range lines {
+		if len(line) > limit {
+			line = line[:limit]
+		}
+		if current != "" && len(current)+1+len(line) > limit {
+			ret = append(ret, current)
+			current = ""
+		}
+		if current != "" {
+			current += "\n"
+		}
+		current += line
+	}
+	if current != "" {
+		ret = append(ret, current)
+	}
+
+	return ret
+}
+
+func eventURL(event *models.Event) string {
+	if config.Cfg.Facility.FrontendURL == "" {
+		return ""
+	}
+
+	return fmt.Sprintf("%s/events/%d", strings.TrimSuffix(config.Cfg.Facility.FrontendURL, "/"), event.ID)
+}
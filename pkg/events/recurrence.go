@@ -0,0 +1,155 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package events
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/adh-partnership/api/pkg/database/models"
+	"github.com/adh-partnership/api/pkg/database/models/constants"
+)
+
+// Recurrence is the rule a series of events recurs by, such as every other Friday or the 2nd Friday of the month
+type Recurrence struct {
+	Frequency   string
+	Every       int
+	Weekday     time.Weekday
+	WeekOfMonth int
+	Hour        int
+	Minute      int
+	StartsOn    time.Time
+	EndsOn      *time.Time
+}
+
+var weekdays = map[string]time.Weekday{}
+
+func init() {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		weekdays[strings.ToLower(d.String())] = d
+	}
+}
+
+// ParseWeekday returns the day of the week named by s, such as friday
+func ParseWeekday(s string) (time.Weekday, error) {
+	d, ok := weekdays[strings.ToLower(strings.TrimSpace(s))]
+	if !ok {
+		return 0, fmt.Errorf("invalid weekday %q", s)
+	}
+
+	return d, nil
+}
+
+// ParseStartTime returns the hour and minute of a time of day written as HH:MM
+func ParseStartTime(s string) (int, int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid start time %q, expected HH:MM", s)
+	}
+
+	return t.Hour(), t.Minute(), nil
+}
+
+// TemplateRecurrence returns the rule the template recurs by, nil if it does not recur, or an error describing what
+// is wrong with it
+func TemplateRecurrence(template *models.EventTemplate) (*Recurrence, error) {
+	if template.Frequency == "" {
+		return nil, nil
+	}
+	if template.Frequency != constants.EventRecurrenceWeekly && template.Frequency != constants.EventRecurrenceMonthly {
+		return nil, fmt.Errorf("invalid frequency %q", template.Frequency)
+	}
+
+	r := &Recurrence{
+		Frequency:   template.Frequency,
+		Every:       template.Every,
+		WeekOfMonth: template.WeekOfMonth,
+		StartsOn:    template.StartsOn,
+		EndsOn:      template.EndsOn,
+	}
+	if r.Every == 0 {
+		r.Every = 1
+	}
+	if r.Every < 0 {
+		return nil, errors.New("every must be at least 1")
+	}
+	if r.Frequency == constants.EventRecurrenceMonthly && (r.WeekOfMonth < -1 || r.WeekOfMonth == 0 || r.WeekOfMonth > 4) {
+		return nil, errors.New("week of month must be 1 to 4, or -1 for the last")
+	}
+	if r.StartsOn.IsZero() {
+		return nil, errors.New("recurring templates need a start date")
+	}
+	if r.EndsOn != nil && r.EndsOn.Before(r.StartsOn) {
+		return nil, errors.New("end date is before the start date")
+	}
+
+	var err error
+	if r.Weekday, err = ParseWeekday(template.Weekday); err != nil {
+		return nil, err
+	}
+	if r.Hour, r.Minute, err = ParseStartTime(template.StartTime); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Occurrences returns the start of each event of the series from from up to, but not including, until
+func (r *Recurrence) Occurrences(from, until time.Time) []time.Time {
+	first := day(r.StartsOn)
+	ret := []time.Time{}
+	add := func(date time.Time) {
+		if date.Before(first) || (r.EndsOn != nil && date.After(day(*r.EndsOn))) {
+			return
+		}
+		start := date.Add(time.Duration(r.Hour)*time.Hour + time.Duration(r.Minute)*time.Minute)
+		if !start.Before(from) && start.Before(until) {
+			ret = append(ret, start)
+		}
+	}
+
+	if r.Frequency == constants.EventRecurrenceWeekly {
+		date := first.AddDate(0, 0, (int(r.Weekday)-int(first.Weekday())+7)%7)
+		for ; date.Before(until); date = date.AddDate(0, 0, 7*r.Every) {
+			add(date)
+		}
+		return ret
+	}
+
+	for month := time.Date(first.Year(), first.Month(), 1, 0, 0, 0, 0, time.UTC); month.Before(until); month = month.AddDate(0, r.Every, 0) {
+		add(weekdayOfMonth(month, r.Weekday, r.WeekOfMonth))
+	}
+
+	return ret
+}
+
+// weekdayOfMonth returns the nth weekday of the month, or the last when n is -1
+func weekdayOfMonth(month time.Time, weekday time.Weekday, n int) time.Time {
+	if n == -1 {
+		last := month.AddDate(0, 1, -1)
+		return last.AddDate(0, 0, -((int(last.Weekday()) - int(weekday) + 7) % 7))
+	}
+
+	return month.AddDate(0, 0, (int(weekday)-int(month.Weekday())+7)%7+(n-1)*7)
+}
+
+func day(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
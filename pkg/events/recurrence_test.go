@@ -0,0 +1,123 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package events
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/adh-partnership/api/pkg/database/models"
+	"github.com/adh-partnership/api/pkg/database/models/constants"
+)
+
+func date(year int, month time.Month, d, hour int) time.Time {
+	return time.Date(year, month, d, hour, 0, 0, 0, time.UTC)
+}
+
+func TestOccurrences(t *testing.T) {
+	tests := []struct {
+		name     string
+		template *models.EventTemplate
+		from     time.Time
+		until    time.Time
+		want     []time.Time
+	}{
+		{
+			name: "every friday",
+			template: &models.EventTemplate{
+				Frequency: constants.EventRecurrenceWeekly, Weekday: "Friday", StartTime: "23:00",
+				StartsOn: date(2024, time.January, 1, 0),
+			},
+			from:  date(2024, time.January, 1, 0),
+			until: date(2024, time.January, 20, 0),
+			want:  []time.Time{date(2024, time.January, 5, 23), date(2024, time.January, 12, 23), date(2024, time.January, 19, 23)},
+		},
+		{
+			name: "every other friday keeps the series anchored to the start date",
+			template: &models.EventTemplate{
+				Frequency: constants.EventRecurrenceWeekly, Every: 2, Weekday: "friday", StartTime: "23:00",
+				StartsOn: date(2024, time.January, 1, 0),
+			},
+			from:  date(2024, time.January, 10, 0),
+			until: date(2024, time.February, 10, 0),
+			want:  []time.Time{date(2024, time.January, 19, 23), date(2024, time.February, 2, 23)},
+		},
+		{
+			name: "2nd friday of the month",
+			template: &models.EventTemplate{
+				Frequency: constants.EventRecurrenceMonthly, Weekday: "friday", WeekOfMonth: 2, StartTime: "00:30",
+				StartsOn: date(2024, time.January, 1, 0),
+			},
+			from:  date(2024, time.January, 1, 0),
+			until: date(2024, time.April, 1, 0),
+			want: []time.Time{
+				date(2024, time.January, 12, 0).Add(30 * time.Minute),
+				date(2024, time.February, 9, 0).Add(30 * time.Minute),
+				date(2024, time.March, 8, 0).Add(30 * time.Minute),
+			},
+		},
+		{
+			name: "last sunday, ending",
+			template: &models.EventTemplate{
+				Frequency: constants.EventRecurrenceMonthly, Weekday: "sunday", WeekOfMonth: -1, StartTime: "20:00",
+				StartsOn: date(2024, time.January, 1, 0), EndsOn: ptr(date(2024, time.March, 1, 0)),
+			},
+			from:  date(2024, time.January, 1, 0),
+			until: date(2024, time.June, 1, 0),
+			want:  []time.Time{date(2024, time.January, 28, 20), date(2024, time.February, 25, 20)},
+		},
+		{
+			name: "occurrences before the start date are skipped",
+			template: &models.EventTemplate{
+				Frequency: constants.EventRecurrenceMonthly, Weekday: "friday", WeekOfMonth: 1, StartTime: "23:00",
+				StartsOn: date(2024, time.January, 10, 0),
+			},
+			from:  date(2024, time.January, 1, 0),
+			until: date(2024, time.March, 1, 0),
+			want:  []time.Time{date(2024, time.February, 2, 23)},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r, err := TemplateRecurrence(test.template)
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, r.Occurrences(test.from, test.until))
+		})
+	}
+}
+
+func TestTemplateRecurrence(t *testing.T) {
+	r, err := TemplateRecurrence(&models.EventTemplate{})
+	assert.NoError(t, err)
+	assert.Nil(t, r)
+
+	invalid := []*models.EventTemplate{
+		{Frequency: "daily", Weekday: "friday", StartTime: "23:00", StartsOn: date(2024, time.January, 1, 0)},
+		{Frequency: constants.EventRecurrenceWeekly, Weekday: "fri", StartTime: "23:00", StartsOn: date(2024, time.January, 1, 0)},
+		{Frequency: constants.EventRecurrenceWeekly, Weekday: "friday", StartTime: "2300", StartsOn: date(2024, time.January, 1, 0)},
+		{Frequency: constants.EventRecurrenceWeekly, Weekday: "friday", StartTime: "23:00"},
+		{Frequency: constants.EventRecurrenceMonthly, Weekday: "friday", StartTime: "23:00", StartsOn: date(2024, time.January, 1, 0)},
+		{Frequency: constants.EventRecurrenceMonthly, Weekday: "friday", WeekOfMonth: 5, StartTime: "23:00", StartsOn: date(2024, time.January, 1, 0)},
+	}
+	for _, template := range invalid {
+		_, err := TemplateRecurrence(template)
+		assert.Error(t, err, "%+v", template)
+	}
+}
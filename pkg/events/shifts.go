@@ -0,0 +1,58 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package events
+
+import (
+	"time"
+)
+
+// Booking is a span of time a controller is assigned to work during an event
+type Booking struct {
+	Position string
+	Start    time.Time
+	End      time.Time
+}
+
+// Overlaps returns true if the spans share any time. Spans that only touch, one ending as the other starts, do not
+// overlap.
+func Overlaps(aStart, aEnd, bStart, bEnd time.Time) bool {
+	return aStart.Before(bEnd) && bStart.Before(aEnd)
+}
+
+// Conflicts returns the bookings that overlap the span from start to end
+func Conflicts(bookings []*Booking, start, end time.Time) []*Booking {
+	ret := []*Booking{}
+	for _, b := range bookings {
+		if Overlaps(b.Start, b.End, start, end) {
+			ret = append(ret, b)
+		}
+	}
+
+	return ret
+}
+
+// Available returns true if the availability window, open ended where from or until is nil, covers start to end
+func Available(from, until *time.Time, start, end time.Time) bool {
+	if from != nil && start.Before(*from) {
+		return false
+	}
+	if until != nil && end.After(*until) {
+		return false
+	}
+
+	return true
+}
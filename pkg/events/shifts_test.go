@@ -0,0 +1,83 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package events
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func at(hour int) time.Time {
+	return time.Date(2024, time.January, 6, hour, 0, 0, 0, time.UTC)
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestOverlaps(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Start    time.Time
+		End      time.Time
+		Expected bool
+	}{
+		{Name: "Same shift", Start: at(18), End: at(20), Expected: true},
+		{Name: "Starts inside", Start: at(19), End: at(21), Expected: true},
+		{Name: "Contains", Start: at(17), End: at(21), Expected: true},
+		{Name: "Back to back", Start: at(20), End: at(22), Expected: false},
+		{Name: "Before", Start: at(16), End: at(18), Expected: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			assert.Equal(t, test.Expected, Overlaps(at(18), at(20), test.Start, test.End))
+		})
+	}
+}
+
+func TestConflicts(t *testing.T) {
+	bookings := []*Booking{
+		{Position: "DEN_APP", Start: at(18), End: at(20)},
+		{Position: "DEN_TWR", Start: at(20), End: at(22)},
+	}
+
+	assert.Equal(t, []*Booking{bookings[1]}, Conflicts(bookings, at(21), at(23)))
+	assert.Empty(t, Conflicts(bookings, at(22), at(23)))
+}
+
+func TestAvailable(t *testing.T) {
+	tests := []struct {
+		Name     string
+		From     *time.Time
+		Until    *time.Time
+		Expected bool
+	}{
+		{Name: "No window", Expected: true},
+		{Name: "Covers", From: ptr(at(17)), Until: ptr(at(22)), Expected: true},
+		{Name: "Arrives late", From: ptr(at(19)), Expected: false},
+		{Name: "Leaves early", Until: ptr(at(19)), Expected: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			assert.Equal(t, test.Expected, Available(test.From, test.Until, at(18), at(20)))
+		})
+	}
+}
@@ -0,0 +1,54 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package events
+
+import (
+	"strings"
+
+	"github.com/adh-partnership/api/pkg/config"
+	"github.com/adh-partnership/api/pkg/database/models"
+)
+
+// AirportPositions returns the positions to staff at an airport given its tower type from the airports table, such
+// as ATCT or ATCT-A/C. Towered airports get delivery, ground and tower, and those with their own approach control
+// also get approach. An unknown tower type is treated as towered.
+func AirportPositions(prefix, towerType string) []string {
+	towerType = strings.ToUpper(towerType)
+
+	var ret []string
+	if towerType == "" || (strings.Contains(towerType, "ATCT") && !strings.HasPrefix(towerType, "NON")) {
+		ret = append(ret, prefix+"_DEL", prefix+"_GND", prefix+"_TWR")
+	}
+	for _, approach := range []string{"A/C", "TRACON", "RAPCON", "RATCF"} {
+		if strings.Contains(towerType, approach) {
+			ret = append(ret, prefix+"_APP")
+			break
+		}
+	}
+
+	return ret
+}
+
+// NewPosition returns an unsaved event position needing the certification configured for it
+func NewPosition(name string, minRatingID int) *models.EventPosition {
+	position := &models.EventPosition{Position: name, MinRatingID: minRatingID}
+	if cert := PositionCertification(name, config.Cfg.Facility.Events.PositionCertifications); cert != "" {
+		position.Certifications = []*models.EventPositionCertification{{Certification: cert}}
+	}
+
+	return position
+}
@@ -0,0 +1,40 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package events
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAirportPositions(t *testing.T) {
+	tests := []struct {
+		towerType string
+		want      []string
+	}{
+		{"ATCT", []string{"DEN_DEL", "DEN_GND", "DEN_TWR"}},
+		{"ATCT-A/C", []string{"DEN_DEL", "DEN_GND", "DEN_TWR", "DEN_APP"}},
+		{"ATCT-TRACON", []string{"DEN_DEL", "DEN_GND", "DEN_TWR", "DEN_APP"}},
+		{"", []string{"DEN_DEL", "DEN_GND", "DEN_TWR"}},
+		{"NON-ATCT", nil},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.want, AirportPositions("DEN", test.towerType), test.towerType)
+	}
+}
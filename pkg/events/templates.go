@@ -0,0 +1,85 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package events
+
+import (
+	"time"
+
+	"github.com/adh-partnership/api/pkg/database"
+	"github.com/adh-partnership/api/pkg/database/models"
+)
+
+// NewFromTemplate returns an unsaved event copied from the template, starting at start. Positions need the
+// certification configured for them, as any new position does.
+func NewFromTemplate(template *models.EventTemplate, start time.Time) *models.Event {
+	event := &models.Event{
+		Title:       template.Title,
+		Description: template.Description,
+		Banner:      template.Banner,
+		StartDate:   start,
+		EndDate:     start.Add(time.Duration(template.DurationMinutes) * time.Minute),
+		TemplateID:  &template.ID,
+	}
+
+	for _, p := range template.Positions {
+		event.Positions = append(event.Positions, NewPosition(p.Position, p.MinRatingID))
+	}
+
+	return event
+}
+
+// GenerateRecurring creates the events of the template's series that start from now up to until and were not
+// generated before. Events deleted from the series are not created again.
+func GenerateRecurring(template *models.EventTemplate, now, until time.Time) ([]*models.Event, error) {
+	r, err := TemplateRecurrence(template)
+	if err != nil || r == nil {
+		return nil, err
+	}
+
+	from := now
+	if template.GeneratedUntil != nil && template.GeneratedUntil.After(from) {
+		from = *template.GeneratedUntil
+	}
+
+	var created []*models.Event
+	for _, start := range r.Occurrences(from, until) {
+		exists, err := database.TemplateEventExists(template.ID, start)
+		if err != nil {
+			return created, err
+		}
+		if exists {
+			continue
+		}
+
+		event := NewFromTemplate(template, start)
+		if err := database.DB.Create(event).Error; err != nil {
+			return created, err
+		}
+		created = append(created, event)
+
+		if NotificationsEnabled() {
+			AnnounceNew(event)
+		}
+	}
+
+	template.GeneratedUntil = &until
+	if err := database.DB.Model(&models.EventTemplate{}).Where("id = ?", template.ID).Update("generated_until", until).Error; err != nil {
+		return created, err
+	}
+
+	return created, nil
+}
@@ -0,0 +1,68 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package events
+
+import (
+	"time"
+
+	"github.com/adh-partnership/api/pkg/database"
+	"github.com/adh-partnership/api/pkg/database/models"
+)
+
+// EventAirports returns the airports the event's positions are at, skipping positions that are not at an airport
+// in the airports table
+func EventAirports(event *models.Event) ([]*models.Airport, error) {
+	var positions []string
+	for _, p := range event.Positions {
+		positions = append(positions, p.Position)
+	}
+
+	var ret []*models.Airport
+	for _, id := range EventAirportIDs(positions) {
+		airport, err := database.FindAirportByID(id)
+		if err != nil {
+			return nil, err
+		}
+		if airport != nil && airport.ICAO != "" {
+			ret = append(ret, airport)
+		}
+	}
+
+	return ret, nil
+}
+
+// TrackedAirports returns the airports, keyed by ICAO identifier, of the events that are running at now or whose
+// baseline window is, so their movements can be recorded
+func TrackedAirports(now time.Time) (map[string]*models.Airport, error) {
+	tracked, err := database.FindTrackedEvents(now, BaselineOffset)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := map[string]*models.Airport{}
+	for _, event := range tracked {
+		airports, err := EventAirports(event)
+		if err != nil {
+			return nil, err
+		}
+		for _, airport := range airports {
+			ret[airport.ICAO] = airport
+		}
+	}
+
+	return ret, nil
+}
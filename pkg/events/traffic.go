@@ -0,0 +1,176 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package events
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	MovementDeparture = "departure"
+	MovementArrival   = "arrival"
+
+	// Flights faster than this are taking off or airborne, slower ones are on the ground
+	groundSpeedThreshold = 40
+	// Movements are only counted within this many nautical miles of the airport
+	movementRadius = 10.0
+	earthRadiusNM  = 3440.065
+
+	// BaselineOffset is how long before an event the window its traffic is compared against is
+	BaselineOffset = 7 * 24 * time.Hour
+)
+
+// Movement is a departure or arrival at an airport
+type Movement struct {
+	Airport  string
+	Callsign string
+	Kind     string
+	At       time.Time
+}
+
+// Traffic is the movements at a set of airports during a window
+type Traffic struct {
+	Start                time.Time
+	End                  time.Time
+	Departures           int
+	Arrivals             int
+	UniqueCallsigns      int
+	PeakHourlyDepartures int
+	PeakHourlyArrivals   int
+	PeakHourlyMovements  int
+	Airports             map[string]*AirportTraffic
+}
+
+// AirportTraffic is the movements at one airport during a window
+type AirportTraffic struct {
+	Departures      int
+	Arrivals        int
+	UniqueCallsigns int
+}
+
+// DetectMovement returns whether a flight whose groundspeed went from prevSpeed to speed knots took off or landed,
+// empty if it did neither
+func DetectMovement(prevSpeed, speed int) string {
+	switch {
+	case prevSpeed < groundSpeedThreshold && speed >= groundSpeedThreshold:
+		return MovementDeparture
+	case prevSpeed >= groundSpeedThreshold && speed < groundSpeedThreshold:
+		return MovementArrival
+	}
+
+	return ""
+}
+
+// NearAirport returns true if the position is close enough to the airport for a movement to count
+func NearAirport(lat, lon, airportLat, airportLon float64) bool {
+	return distanceNM(lat, lon, airportLat, airportLon) <= movementRadius
+}
+
+func distanceNM(lat1, lon1, lat2, lon2 float64) float64 {
+	rad := math.Pi / 180
+	dLat := (lat2 - lat1) * rad
+	dLon := (lon2 - lon1) * rad
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1*rad)*math.Cos(lat2*rad)*math.Sin(dLon/2)*math.Sin(dLon/2)
+
+	return 2 * earthRadiusNM * math.Asin(math.Sqrt(a))
+}
+
+// EventAirportIDs returns the distinct airport identifiers of the event's positions, such as DEN for DEN_N_APP.
+// Positions of en route and other non-airport facilities are included, and are expected to not match an airport.
+func EventAirportIDs(positions []string) []string {
+	seen := map[string]bool{}
+	ret := []string{}
+	for _, position := range positions {
+		id := strings.ToUpper(position)
+		if i := strings.Index(id, "_"); i != -1 {
+			id = id[:i]
+		}
+		if id != "" && !seen[id] {
+			seen[id] = true
+			ret = append(ret, id)
+		}
+	}
+	sort.Strings(ret)
+
+	return ret
+}
+
+// CountTraffic returns the traffic of the movements from start up to end. Peak hourly rates are the most movements in
+// any 60 minute span within the window.
+func CountTraffic(movements []*Movement, start, end time.Time) *Traffic {
+	t := &Traffic{Start: start, End: end, Airports: map[string]*AirportTraffic{}}
+
+	var departures, arrivals, all []time.Time
+	callsigns := map[string]bool{}
+	airportCallsigns := map[string]map[string]bool{}
+	for _, m := range movements {
+		if m.At.Before(start) || !m.At.Before(end) {
+			continue
+		}
+
+		airport, ok := t.Airports[m.Airport]
+		if !ok {
+			airport = &AirportTraffic{}
+			t.Airports[m.Airport] = airport
+			airportCallsigns[m.Airport] = map[string]bool{}
+		}
+
+		if m.Kind == MovementDeparture {
+			t.Departures++
+			airport.Departures++
+			departures = append(departures, m.At)
+		} else {
+			t.Arrivals++
+			airport.Arrivals++
+			arrivals = append(arrivals, m.At)
+		}
+		all = append(all, m.At)
+
+		callsigns[m.Callsign] = true
+		if !airportCallsigns[m.Airport][m.Callsign] {
+			airportCallsigns[m.Airport][m.Callsign] = true
+			airport.UniqueCallsigns++
+		}
+	}
+
+	t.UniqueCallsigns = len(callsigns)
+	t.PeakHourlyDepartures = peakHourly(departures)
+	t.PeakHourlyArrivals = peakHourly(arrivals)
+	t.PeakHourlyMovements = peakHourly(all)
+
+	return t
+}
+
+// peakHourly returns the most times that fall within any 60 minute span
+func peakHourly(times []time.Time) int {
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+
+	peak := 0
+	for i, j := 0, 0; j < len(times); j++ {
+		for times[j].Sub(times[i]) >= time.Hour {
+			i++
+		}
+		if j-i+1 > peak {
+			peak = j - i + 1
+		}
+	}
+
+	return peak
+}
@@ -0,0 +1,71 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package events
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectMovement(t *testing.T) {
+	assert.Equal(t, MovementDeparture, DetectMovement(0, 140))
+	assert.Equal(t, MovementDeparture, DetectMovement(15, 45))
+	assert.Equal(t, MovementArrival, DetectMovement(130, 20))
+	assert.Equal(t, "", DetectMovement(10, 20))
+	assert.Equal(t, "", DetectMovement(250, 450))
+}
+
+func TestNearAirport(t *testing.T) {
+	// KDEN and a point about 5nm north
+	assert.True(t, NearAirport(39.9447, -104.6731, 39.8617, -104.6731))
+	// KDEN and KCOS, about 60nm apart
+	assert.False(t, NearAirport(38.8058, -104.7008, 39.8617, -104.6731))
+}
+
+func TestEventAirportIDs(t *testing.T) {
+	assert.Equal(t, []string{"COS", "DEN", "ZDV"}, EventAirportIDs([]string{"DEN_TWR", "DEN_N_APP", "ZDV_CTR", "COS_TWR", "den_gnd"}))
+	assert.Equal(t, []string{}, EventAirportIDs(nil))
+}
+
+func TestCountTraffic(t *testing.T) {
+	movements := []*Movement{
+		{Airport: "KDEN", Callsign: "AAL1", Kind: MovementDeparture, At: at(18)},
+		{Airport: "KDEN", Callsign: "AAL2", Kind: MovementDeparture, At: at(18).Add(20 * time.Minute)},
+		{Airport: "KDEN", Callsign: "UAL3", Kind: MovementArrival, At: at(18).Add(50 * time.Minute)},
+		{Airport: "KCOS", Callsign: "AAL1", Kind: MovementArrival, At: at(19).Add(10 * time.Minute)},
+		{Airport: "KDEN", Callsign: "SWA4", Kind: MovementDeparture, At: at(20).Add(30 * time.Minute)},
+		// Outside the window
+		{Airport: "KDEN", Callsign: "SWA5", Kind: MovementDeparture, At: at(17).Add(59 * time.Minute)},
+		{Airport: "KDEN", Callsign: "SWA6", Kind: MovementDeparture, At: at(21)},
+	}
+
+	traffic := CountTraffic(movements, at(18), at(21))
+	assert.Equal(t, 3, traffic.Departures)
+	assert.Equal(t, 2, traffic.Arrivals)
+	assert.Equal(t, 4, traffic.UniqueCallsigns)
+	assert.Equal(t, 2, traffic.PeakHourlyDepartures)
+	assert.Equal(t, 2, traffic.PeakHourlyArrivals)
+	assert.Equal(t, 3, traffic.PeakHourlyMovements)
+	assert.Equal(t, &AirportTraffic{Departures: 3, Arrivals: 1, UniqueCallsigns: 4}, traffic.Airports["KDEN"])
+	assert.Equal(t, &AirportTraffic{Arrivals: 1, UniqueCallsigns: 1}, traffic.Airports["KCOS"])
+
+	empty := CountTraffic(nil, at(18), at(21))
+	assert.Equal(t, 0, empty.PeakHourlyMovements)
+	assert.Empty(t, empty.Airports)
+}
@@ -0,0 +1,76 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package events
+
+import (
+	"strings"
+	"time"
+)
+
+// SignupsOpen returns true if signups are accepted at now, given when they close. Signups without a close time are
+// always open.
+func SignupsOpen(closeAt *time.Time, now time.Time) bool {
+	return closeAt == nil || now.Before(*closeAt)
+}
+
+// Waitlisted returns true if a new signup joins the waitlist, given the cap and how many signups are not waitlisted.
+// A cap of 0 means signups are unlimited.
+func Waitlisted(limit, active int) bool {
+	return limit > 0 && active >= limit
+}
+
+// Openings returns how many of the waiting signups can leave the waitlist, given the cap and how many signups are
+// not waitlisted
+func Openings(limit, active, waiting int) int {
+	if limit == 0 {
+		return waiting
+	}
+
+	open := limit - active
+	if open < 0 {
+		return 0
+	}
+	if open > waiting {
+		return waiting
+	}
+
+	return open
+}
+
+// Promote returns the first candidate of the waitlist that can work the vacant slot without overlapping their
+// bookings, preferring those that asked for the position. Candidates are in waitlist order, and nil is returned if
+// none can work it.
+func Promote(slot *Slot, waitlist []*Candidate, bookings map[uint][]*Booking) *Candidate {
+	fits := func(c *Candidate) bool {
+		return len(Violations(slot, c)) == 0 && len(Conflicts(bookings[c.CID], slot.Start, slot.End)) == 0
+	}
+
+	for _, c := range waitlist {
+		for _, choice := range c.Choices {
+			if strings.EqualFold(choice, slot.Position) && fits(c) {
+				return c
+			}
+		}
+	}
+	for _, c := range waitlist {
+		if fits(c) {
+			return c
+		}
+	}
+
+	return nil
+}
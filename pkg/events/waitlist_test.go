@@ -0,0 +1,60 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package events
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignupsOpen(t *testing.T) {
+	assert.True(t, SignupsOpen(nil, at(18)))
+	assert.True(t, SignupsOpen(ptr(at(18)), at(17)))
+	assert.False(t, SignupsOpen(ptr(at(18)), at(18)))
+	assert.False(t, SignupsOpen(ptr(at(18)), at(19)))
+}
+
+func TestWaitlisted(t *testing.T) {
+	assert.False(t, Waitlisted(0, 100))
+	assert.False(t, Waitlisted(3, 2))
+	assert.True(t, Waitlisted(3, 3))
+	assert.True(t, Waitlisted(3, 4))
+}
+
+func TestOpenings(t *testing.T) {
+	assert.Equal(t, 4, Openings(0, 10, 4))
+	assert.Equal(t, 1, Openings(3, 2, 4))
+	assert.Equal(t, 2, Openings(5, 2, 2))
+	assert.Equal(t, 0, Openings(3, 3, 4))
+	assert.Equal(t, 0, Openings(3, 5, 4))
+}
+
+func TestPromote(t *testing.T) {
+	slot := &Slot{Position: "DEN_TWR", Start: at(18), End: at(20), Certifications: []string{"tower"}}
+
+	uncertified := &Candidate{CID: 1, Choices: []string{"DEN_TWR"}, Certifications: map[string]string{}}
+	busy := &Candidate{CID: 2, Choices: []string{"DEN_TWR"}, Certifications: map[string]string{"tower": "certified"}}
+	other := &Candidate{CID: 3, Choices: []string{"DEN_GND"}, Certifications: map[string]string{"tower": "certified"}}
+	asked := &Candidate{CID: 4, Choices: []string{"DEN_GND", "den_twr"}, Certifications: map[string]string{"tower": "certified"}}
+	bookings := map[uint][]*Booking{2: {{Position: "DEN_GND", Start: at(19), End: at(21)}}}
+
+	assert.Equal(t, asked, Promote(slot, []*Candidate{uncertified, busy, other, asked}, bookings))
+	assert.Equal(t, other, Promote(slot, []*Candidate{uncertified, busy, other}, bookings))
+	assert.Nil(t, Promote(slot, []*Candidate{uncertified, busy}, bookings))
+	assert.Nil(t, Promote(slot, nil, bookings))
+}
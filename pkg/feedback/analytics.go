@@ -0,0 +1,273 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package feedback
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/adh-partnership/api/pkg/database/models"
+	"github.com/adh-partnership/api/pkg/database/models/constants"
+)
+
+const (
+	// OutlierWindow is how far back from the end of the analysed range ratings count as recent
+	OutlierWindow = 30 * 24 * time.Hour
+	// OutlierMinPoor is how many recent poor ratings a controller needs before being flagged
+	OutlierMinPoor = 2
+	// OutlierFactor is how many times their usual share of poor ratings a controller's recent share must be
+	OutlierFactor = 2
+)
+
+// Ratings are the feedback ratings from best to worst
+var Ratings = []string{
+	constants.FeedbackRatingExcellent,
+	constants.FeedbackRatingGood,
+	constants.FeedbackRatingFair,
+	constants.FeedbackRatingPoor,
+}
+
+var scores = map[string]int{
+	constants.FeedbackRatingExcellent: 4,
+	constants.FeedbackRatingGood:      3,
+	constants.FeedbackRatingFair:      2,
+	constants.FeedbackRatingPoor:      1,
+}
+
+// Summary totals a set of feedback. Rejected feedback counts towards the statuses but not the ratings.
+type Summary struct {
+	Total   int
+	Ratings map[string]int
+	// Average rating from 4 for excellent to 1 for poor, 0 without ratings
+	Score    float64
+	Pending  int
+	Approved int
+	Rejected int
+	// Share of reviewed feedback that was approved, 0 if none was reviewed
+	ApprovalRate float64
+}
+
+type MonthSummary struct {
+	Month string
+	Summary
+}
+
+type PositionSummary struct {
+	Prefix string
+	Summary
+}
+
+type ControllerSummary struct {
+	CID  uint
+	Name string
+	Summary
+}
+
+// Outlier is a controller whose share of poor ratings recently rose well above their usual share
+type Outlier struct {
+	CID            uint
+	Name           string
+	Recent         int
+	RecentPoor     int
+	RecentPoorRate float64
+	// Share of poor ratings before the recent window, 0 if the controller had none rated
+	BaselinePoorRate float64
+}
+
+// DefaultRange returns the range analysed when none is given, the twelve months up to and including the current one.
+// to is exclusive.
+func DefaultRange(now time.Time) (time.Time, time.Time) {
+	now = now.UTC()
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1)
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -11, 0)
+
+	return from, to
+}
+
+// Summarize totals the ratings and statuses of the feedback
+func Summarize(feedback []*models.Feedback) Summary {
+	s := Summary{Ratings: map[string]int{}}
+	for _, rating := range Ratings {
+		s.Ratings[rating] = 0
+	}
+
+	score := 0
+	rated := 0
+	for _, f := range feedback {
+		s.Total++
+		switch f.Status {
+		case constants.FeedbackStatusPending:
+			s.Pending++
+		case constants.FeedbackStatusApproved:
+			s.Approved++
+		case constants.FeedbackStatusRejected:
+			s.Rejected++
+			continue
+		}
+		if _, ok := scores[f.Rating]; ok {
+			s.Ratings[f.Rating]++
+			score += scores[f.Rating]
+			rated++
+		}
+	}
+
+	if rated > 0 {
+		s.Score = float64(score) / float64(rated)
+	}
+	if reviewed := s.Approved + s.Rejected; reviewed > 0 {
+		s.ApprovalRate = float64(s.Approved) / float64(reviewed)
+	}
+
+	return s
+}
+
+// ByMonth summarizes the feedback of each month in the range from from to the exclusive to, including months
+// without any feedback
+func ByMonth(feedback []*models.Feedback, from, to time.Time) []*MonthSummary {
+	months := map[string][]*models.Feedback{}
+	for _, f := range feedback {
+		if f.CreatedAt == nil {
+			continue
+		}
+		month := f.CreatedAt.UTC().Format("2006-01")
+		months[month] = append(months[month], f)
+	}
+
+	ret := []*MonthSummary{}
+	from = from.UTC()
+	last := to.UTC().Add(-time.Nanosecond).Format("2006-01")
+	for m := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, time.UTC); m.Format("2006-01") <= last; m = m.AddDate(0, 1, 0) {
+		month := m.Format("2006-01")
+		ret = append(ret, &MonthSummary{Month: month, Summary: Summarize(months[month])})
+	}
+
+	return ret
+}
+
+// PositionPrefix returns the part of a position before the first underscore, so DEN_N_APP becomes DEN
+func PositionPrefix(position string) string {
+	prefix, _, _ := strings.Cut(strings.ToUpper(strings.TrimSpace(position)), "_")
+	return prefix
+}
+
+// ByPositionPrefix summarizes the feedback of each position prefix, ordered by prefix
+func ByPositionPrefix(feedback []*models.Feedback) []*PositionSummary {
+	prefixes := map[string][]*models.Feedback{}
+	for _, f := range feedback {
+		prefix := PositionPrefix(f.Position)
+		prefixes[prefix] = append(prefixes[prefix], f)
+	}
+
+	ret := []*PositionSummary{}
+	for prefix, fs := range prefixes {
+		ret = append(ret, &PositionSummary{Prefix: prefix, Summary: Summarize(fs)})
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Prefix < ret[j].Prefix })
+
+	return ret
+}
+
+// ByController summarizes the feedback of each controller, ordered by CID
+func ByController(feedback []*models.Feedback) []*ControllerSummary {
+	controllers := map[uint][]*models.Feedback{}
+	for _, f := range feedback {
+		controllers[f.ControllerID] = append(controllers[f.ControllerID], f)
+	}
+
+	ret := []*ControllerSummary{}
+	for cid, fs := range controllers {
+		ret = append(ret, &ControllerSummary{CID: cid, Name: controllerName(fs[0]), Summary: Summarize(fs)})
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].CID < ret[j].CID })
+
+	return ret
+}
+
+// Outliers returns the controllers with at least OutlierMinPoor poor ratings in the window before end, whose share
+// of poor ratings in it is at least OutlierFactor times their share before it. Most recent poor ratings come first.
+func Outliers(feedback []*models.Feedback, end time.Time, window time.Duration) []*Outlier {
+	type counts struct {
+		recent, recentPoor, baseline, baselinePoor int
+	}
+	start := end.Add(-window)
+	controllers := map[uint]*counts{}
+	names := map[uint]string{}
+	for _, f := range feedback {
+		if f.CreatedAt == nil || f.Status == constants.FeedbackStatusRejected || !f.CreatedAt.Before(end) {
+			continue
+		}
+		if _, ok := scores[f.Rating]; !ok {
+			continue
+		}
+		c, ok := controllers[f.ControllerID]
+		if !ok {
+			c = &counts{}
+			controllers[f.ControllerID] = c
+			names[f.ControllerID] = controllerName(f)
+		}
+		poor := f.Rating == constants.FeedbackRatingPoor
+		if f.CreatedAt.Before(start) {
+			c.baseline++
+			if poor {
+				c.baselinePoor++
+			}
+			continue
+		}
+		c.recent++
+		if poor {
+			c.recentPoor++
+		}
+	}
+
+	ret := []*Outlier{}
+	for cid, c := range controllers {
+		if c.recentPoor < OutlierMinPoor {
+			continue
+		}
+		o := &Outlier{
+			CID:            cid,
+			Name:           names[cid],
+			Recent:         c.recent,
+			RecentPoor:     c.recentPoor,
+			RecentPoorRate: float64(c.recentPoor) / float64(c.recent),
+		}
+		if c.baseline > 0 {
+			o.BaselinePoorRate = float64(c.baselinePoor) / float64(c.baseline)
+		}
+		if o.RecentPoorRate < o.BaselinePoorRate*OutlierFactor {
+			continue
+		}
+		ret = append(ret, o)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].RecentPoor != ret[j].RecentPoor {
+			return ret[i].RecentPoor > ret[j].RecentPoor
+		}
+		return ret[i].CID < ret[j].CID
+	})
+
+	return ret
+}
+
+func controllerName(f *models.Feedback) string {
+	if f.Controller == nil {
+		return ""
+	}
+
+	return f.Controller.FirstName + " " + f.Controller.LastName
+}
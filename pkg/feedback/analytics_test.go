@@ -0,0 +1,159 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package feedback
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/adh-partnership/api/pkg/database/models"
+	"github.com/adh-partnership/api/pkg/database/models/constants"
+)
+
+var now = time.Date(2024, time.June, 15, 12, 0, 0, 0, time.UTC)
+
+func fb(cid uint, rating, status, position string, daysAgo int) *models.Feedback {
+	created := now.AddDate(0, 0, -daysAgo)
+	return &models.Feedback{
+		ControllerID: cid,
+		Controller:   &models.User{CID: cid, FirstName: "Controller", LastName: "Test"},
+		Rating:       rating,
+		Status:       status,
+		Position:     position,
+		CreatedAt:    &created,
+	}
+}
+
+func TestDefaultRange(t *testing.T) {
+	from, to := DefaultRange(now)
+	assert.Equal(t, time.Date(2023, time.July, 1, 0, 0, 0, 0, time.UTC), from)
+	assert.Equal(t, time.Date(2024, time.June, 16, 0, 0, 0, 0, time.UTC), to)
+}
+
+func TestSummarize(t *testing.T) {
+	s := Summarize([]*models.Feedback{
+		fb(1, constants.FeedbackRatingExcellent, constants.FeedbackStatusApproved, "DEN_TWR", 1),
+		fb(1, constants.FeedbackRatingGood, constants.FeedbackStatusApproved, "DEN_TWR", 1),
+		fb(1, constants.FeedbackRatingPoor, constants.FeedbackStatusPending, "DEN_TWR", 1),
+		fb(1, constants.FeedbackRatingPoor, constants.FeedbackStatusRejected, "DEN_TWR", 1),
+	})
+
+	assert.Equal(t, 4, s.Total)
+	assert.Equal(t, map[string]int{"excellent": 1, "good": 1, "fair": 0, "poor": 1}, s.Ratings)
+	assert.InDelta(t, 8.0/3, s.Score, 0.001)
+	assert.Equal(t, 1, s.Pending)
+	assert.Equal(t, 2, s.Approved)
+	assert.Equal(t, 1, s.Rejected)
+	assert.InDelta(t, 2.0/3, s.ApprovalRate, 0.001)
+
+	empty := Summarize(nil)
+	assert.Equal(t, 0, empty.Total)
+	assert.Equal(t, 0.0, empty.Score)
+	assert.Len(t, empty.Ratings, 4)
+}
+
+func TestByMonth(t *testing.T) {
+	months := ByMonth([]*models.Feedback{
+		fb(1, constants.FeedbackRatingGood, constants.FeedbackStatusApproved, "DEN_TWR", 0),
+		fb(1, constants.FeedbackRatingGood, constants.FeedbackStatusApproved, "DEN_TWR", 70),
+	}, now.AddDate(0, -3, 0), now)
+
+	assert.Len(t, months, 4)
+	assert.Equal(t, "2024-03", months[0].Month)
+	assert.Equal(t, "2024-06", months[3].Month)
+	assert.Equal(t, []int{0, 1, 0, 1}, []int{months[0].Total, months[1].Total, months[2].Total, months[3].Total})
+
+	// The end of the range is exclusive
+	assert.Len(t, ByMonth(nil, time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, time.July, 1, 0, 0, 0, 0, time.UTC)), 6)
+}
+
+func TestPositionPrefix(t *testing.T) {
+	assert.Equal(t, "DEN", PositionPrefix("den_n_app"))
+	assert.Equal(t, "COS", PositionPrefix("COS_TWR"))
+	assert.Equal(t, "DEN", PositionPrefix("DEN"))
+}
+
+func TestByPositionPrefix(t *testing.T) {
+	positions := ByPositionPrefix([]*models.Feedback{
+		fb(1, constants.FeedbackRatingGood, constants.FeedbackStatusApproved, "DEN_TWR", 0),
+		fb(2, constants.FeedbackRatingPoor, constants.FeedbackStatusApproved, "COS_APP", 0),
+		fb(2, constants.FeedbackRatingFair, constants.FeedbackStatusApproved, "DEN_APP", 0),
+	})
+
+	assert.Len(t, positions, 2)
+	assert.Equal(t, "COS", positions[0].Prefix)
+	assert.Equal(t, 1, positions[0].Ratings["poor"])
+	assert.Equal(t, "DEN", positions[1].Prefix)
+	assert.Equal(t, 2, positions[1].Total)
+}
+
+func TestByController(t *testing.T) {
+	controllers := ByController([]*models.Feedback{
+		fb(2, constants.FeedbackRatingPoor, constants.FeedbackStatusApproved, "COS_APP", 0),
+		fb(1, constants.FeedbackRatingGood, constants.FeedbackStatusApproved, "DEN_TWR", 0),
+	})
+
+	assert.Len(t, controllers, 2)
+	assert.Equal(t, uint(1), controllers[0].CID)
+	assert.Equal(t, "Controller Test", controllers[0].Name)
+	assert.Equal(t, 1.0, controllers[1].Score)
+}
+
+func TestOutliers(t *testing.T) {
+	var feedback []*models.Feedback
+	// Controller 1 was usually rated well and got three poor ratings this month
+	for i := 0; i < 10; i++ {
+		feedback = append(feedback, fb(1, constants.FeedbackRatingGood, constants.FeedbackStatusApproved, "DEN_TWR", 60+i))
+	}
+	feedback = append(feedback,
+		fb(1, constants.FeedbackRatingPoor, constants.FeedbackStatusApproved, "DEN_TWR", 1),
+		fb(1, constants.FeedbackRatingPoor, constants.FeedbackStatusPending, "DEN_TWR", 2),
+		fb(1, constants.FeedbackRatingPoor, constants.FeedbackStatusApproved, "DEN_TWR", 3),
+		fb(1, constants.FeedbackRatingGood, constants.FeedbackStatusApproved, "DEN_TWR", 4),
+	)
+	// Controller 2 is always rated poorly, which is not a spike
+	for i := 0; i < 4; i++ {
+		feedback = append(feedback,
+			fb(2, constants.FeedbackRatingPoor, constants.FeedbackStatusApproved, "COS_APP", 5+i),
+			fb(2, constants.FeedbackRatingPoor, constants.FeedbackStatusApproved, "COS_APP", 60+i),
+		)
+	}
+	// Controller 3 only has one recent poor rating, and rejected feedback does not count
+	feedback = append(feedback,
+		fb(3, constants.FeedbackRatingPoor, constants.FeedbackStatusApproved, "DEN_APP", 1),
+		fb(3, constants.FeedbackRatingPoor, constants.FeedbackStatusRejected, "DEN_APP", 2),
+	)
+	// Controller 4 has no history, so any recent poor ratings are a spike
+	feedback = append(feedback,
+		fb(4, constants.FeedbackRatingPoor, constants.FeedbackStatusApproved, "DEN_GND", 1),
+		fb(4, constants.FeedbackRatingPoor, constants.FeedbackStatusApproved, "DEN_GND", 2),
+	)
+
+	outliers := Outliers(feedback, now, OutlierWindow)
+	assert.Len(t, outliers, 2)
+	assert.Equal(t, uint(1), outliers[0].CID)
+	assert.Equal(t, 4, outliers[0].Recent)
+	assert.Equal(t, 3, outliers[0].RecentPoor)
+	assert.InDelta(t, 0.75, outliers[0].RecentPoorRate, 0.001)
+	assert.Equal(t, 0.0, outliers[0].BaselinePoorRate)
+	assert.Equal(t, uint(4), outliers[1].CID)
+
+	// Ratings after the end of the range are ignored
+	assert.Empty(t, Outliers(feedback, now.AddDate(0, -1, 0), OutlierWindow))
+}
@@ -0,0 +1,75 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package feedback
+
+import "github.com/adh-partnership/api/pkg/database/dto"
+
+// SummaryResponse converts a summary for the API
+func SummaryResponse(s Summary) dto.FeedbackSummary {
+	return dto.FeedbackSummary{
+		Total:        s.Total,
+		Ratings:      s.Ratings,
+		Score:        s.Score,
+		Pending:      s.Pending,
+		Approved:     s.Approved,
+		Rejected:     s.Rejected,
+		ApprovalRate: s.ApprovalRate,
+	}
+}
+
+func MonthsResponse(months []*MonthSummary) []*dto.FeedbackMonthSummary {
+	ret := []*dto.FeedbackMonthSummary{}
+	for _, m := range months {
+		ret = append(ret, &dto.FeedbackMonthSummary{Month: m.Month, FeedbackSummary: SummaryResponse(m.Summary)})
+	}
+
+	return ret
+}
+
+func PositionsResponse(positions []*PositionSummary) []*dto.FeedbackPositionSummary {
+	ret := []*dto.FeedbackPositionSummary{}
+	for _, p := range positions {
+		ret = append(ret, &dto.FeedbackPositionSummary{Prefix: p.Prefix, FeedbackSummary: SummaryResponse(p.Summary)})
+	}
+
+	return ret
+}
+
+func ControllersResponse(controllers []*ControllerSummary) []*dto.FeedbackControllerSummary {
+	ret := []*dto.FeedbackControllerSummary{}
+	for _, c := range controllers {
+		ret = append(ret, &dto.FeedbackControllerSummary{CID: c.CID, Name: c.Name, FeedbackSummary: SummaryResponse(c.Summary)})
+	}
+
+	return ret
+}
+
+func OutliersResponse(outliers []*Outlier) []*dto.FeedbackOutlier {
+	ret := []*dto.FeedbackOutlier{}
+	for _, o := range outliers {
+		ret = append(ret, &dto.FeedbackOutlier{
+			CID:              o.CID,
+			Name:             o.Name,
+			Recent:           o.Recent,
+			RecentPoor:       o.RecentPoor,
+			RecentPoorRate:   o.RecentPoorRate,
+			BaselinePoorRate: o.BaselinePoorRate,
+		})
+	}
+
+	return ret
+}
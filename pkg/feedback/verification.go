@@ -0,0 +1,125 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package feedback
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/adh-partnership/api/pkg/events"
+)
+
+const (
+	VerificationVerified   = "verified"
+	VerificationPartial    = "partial"
+	VerificationUnverified = "unverified"
+)
+
+// Flight is a stretch of time a pilot flew a callsign within the facility
+type Flight struct {
+	Callsign string
+	CID      uint
+	Start    time.Time
+	End      time.Time
+}
+
+// Verification is how well a submission matches what the controller and the submitter were doing on the network
+type Verification struct {
+	Result string
+	// From 0 when nothing matches to 1 when the controller was on the position while the submitter flew the callsign
+	Confidence float64
+	// What was found, for moderators
+	Notes []string
+}
+
+// Verify checks a submission's position against the controller's sessions and its callsign against the flights of
+// the submitter and of the callsign, between from and to. Half the confidence comes from the controller being on the
+// position, a quarter if they were on another, and half from the submitter flying the callsign, a quarter if they
+// flew another. A matching session and flight that do not overlap lose a quarter.
+func Verify(position, callsign string, submitter uint, from, to time.Time, sessions []*events.Session, flights []*Flight) *Verification {
+	v := &Verification{Notes: []string{}}
+
+	var session *events.Session
+	var other []string
+	for _, s := range sessions {
+		if !events.Overlaps(s.Start, s.End, from, to) {
+			continue
+		}
+		if events.SamePosition(position, s.Callsign) || events.SamePosition(s.Callsign, position) {
+			session = s
+			break
+		}
+		other = append(other, s.Callsign)
+	}
+	switch {
+	case session != nil:
+		v.Confidence += 0.5
+		v.Notes = append(v.Notes, fmt.Sprintf("Controller was online as %s", session.Callsign))
+	case len(other) > 0:
+		v.Confidence += 0.25
+		v.Notes = append(v.Notes, fmt.Sprintf("Controller was online as %s, not %s", strings.Join(other, ", "), position))
+	default:
+		v.Notes = append(v.Notes, fmt.Sprintf("Controller was not online as %s", position))
+	}
+
+	var flight *Flight
+	var flown []string
+	var others []string
+	for _, f := range flights {
+		if !events.Overlaps(f.Start, f.End, from, to) {
+			continue
+		}
+		sameCallsign := strings.EqualFold(f.Callsign, callsign)
+		switch {
+		case sameCallsign && f.CID == submitter:
+			flight = f
+		case f.CID == submitter:
+			flown = append(flown, f.Callsign)
+		case sameCallsign:
+			others = append(others, fmt.Sprint(f.CID))
+		}
+	}
+	switch {
+	case flight != nil:
+		v.Confidence += 0.5
+		v.Notes = append(v.Notes, fmt.Sprintf("Submitter flew %s within the facility", flight.Callsign))
+	case len(flown) > 0:
+		v.Confidence += 0.25
+		v.Notes = append(v.Notes, fmt.Sprintf("Submitter flew %s within the facility, not %s", strings.Join(flown, ", "), callsign))
+	case len(others) > 0:
+		v.Notes = append(v.Notes, fmt.Sprintf("%s was flown within the facility by %s, not the submitter", callsign, strings.Join(others, ", ")))
+	default:
+		v.Notes = append(v.Notes, "Submitter did not fly within the facility")
+	}
+
+	if session != nil && flight != nil && !events.Overlaps(session.Start, session.End, flight.Start, flight.End) {
+		v.Confidence -= 0.25
+		v.Notes = append(v.Notes, fmt.Sprintf("%s was not flown while the controller was online as %s", flight.Callsign, session.Callsign))
+	}
+
+	switch {
+	case v.Confidence >= 1:
+		v.Result = VerificationVerified
+	case v.Confidence > 0:
+		v.Result = VerificationPartial
+	default:
+		v.Result = VerificationUnverified
+	}
+
+	return v
+}
@@ -0,0 +1,106 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package feedback
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/adh-partnership/api/pkg/events"
+)
+
+func TestVerify(t *testing.T) {
+	from := now.Add(-12 * time.Hour)
+	hours := func(start, end int) (time.Time, time.Time) {
+		return now.Add(time.Duration(start) * time.Hour), now.Add(time.Duration(end) * time.Hour)
+	}
+	session := func(callsign string, start, end int) *events.Session {
+		s, e := hours(start, end)
+		return &events.Session{Callsign: callsign, Start: s, End: e}
+	}
+	flight := func(callsign string, cid uint, start, end int) *Flight {
+		s, e := hours(start, end)
+		return &Flight{Callsign: callsign, CID: cid, Start: s, End: e}
+	}
+
+	tests := []struct {
+		name       string
+		sessions   []*events.Session
+		flights    []*Flight
+		result     string
+		confidence float64
+		note       string
+	}{
+		{
+			name:       "controller on position while submitter flew the callsign",
+			sessions:   []*events.Session{session("DEN_N_APP", -4, -1)},
+			flights:    []*Flight{flight("ual123", 100, -3, -2)},
+			result:     VerificationVerified,
+			confidence: 1,
+			note:       "Controller was online as DEN_N_APP",
+		},
+		{
+			name:       "session and flight do not overlap",
+			sessions:   []*events.Session{session("DEN_APP", -8, -6)},
+			flights:    []*Flight{flight("UAL123", 100, -3, -2)},
+			result:     VerificationPartial,
+			confidence: 0.75,
+			note:       "UAL123 was not flown while the controller was online as DEN_APP",
+		},
+		{
+			name:       "controller on another position",
+			sessions:   []*events.Session{session("DEN_TWR", -4, -1)},
+			flights:    []*Flight{flight("UAL123", 100, -3, -2)},
+			result:     VerificationPartial,
+			confidence: 0.75,
+			note:       "Controller was online as DEN_TWR, not DEN_APP",
+		},
+		{
+			name:       "submitter flew another callsign",
+			sessions:   []*events.Session{session("DEN_APP", -4, -1)},
+			flights:    []*Flight{flight("SWA1", 100, -3, -2)},
+			result:     VerificationPartial,
+			confidence: 0.75,
+			note:       "Submitter flew SWA1 within the facility, not UAL123",
+		},
+		{
+			name:       "callsign flown by someone else",
+			flights:    []*Flight{flight("UAL123", 200, -3, -2)},
+			result:     VerificationUnverified,
+			confidence: 0,
+			note:       "UAL123 was flown within the facility by 200, not the submitter",
+		},
+		{
+			name:       "nothing before the window counts",
+			sessions:   []*events.Session{session("DEN_APP", -20, -13)},
+			flights:    []*Flight{flight("UAL123", 100, -20, -14)},
+			result:     VerificationUnverified,
+			confidence: 0,
+			note:       "Submitter did not fly within the facility",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := Verify("DEN_APP", "UAL123", 100, from, now, tt.sessions, tt.flights)
+			assert.Equal(t, tt.result, v.Result)
+			assert.InDelta(t, tt.confidence, v.Confidence, 0.001)
+			assert.Contains(t, v.Notes, tt.note)
+		})
+	}
+}
@@ -111,6 +111,7 @@ func Auth(c *gin.Context) {
 		c.Set("x-guest", false)
 		c.Set("x-user", user)
 		c.Set("x-auth-type", "apikey")
+		c.Set("x-api-facility", apikey.Facility)
 		c.Set("x-cid", user.CID)
 		c.Next()
 		return
@@ -160,6 +161,11 @@ func IsAPIKey(c *gin.Context) bool {
 	return c.GetString("x-auth-type") == "apikey"
 }
 
+// APIFacility returns the partner facility the API key of the request was issued to, empty if there is none
+func APIFacility(c *gin.Context) string {
+	return c.GetString("x-api-facility")
+}
+
 func HasRole(roles ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		user := c.MustGet("x-user").(*models.User)
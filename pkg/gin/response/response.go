@@ -17,7 +17,9 @@
 package response
 
 import (
+	"encoding/csv"
 	"encoding/xml"
+	"fmt"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -57,6 +59,17 @@ func Respond(c *gin.Context, status int, data interface{}) {
 	}
 }
 
+// RespondCSV writes the records as a CSV attachment named filename
+func RespondCSV(c *gin.Context, status int, filename string, header []string, records [][]string) {
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Status(status)
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+
+	w := csv.NewWriter(c.Writer)
+	_ = w.Write(header)
+	_ = w.WriteAll(records)
+}
+
 func HandleError(c *gin.Context, message string) {
 	c.HTML(http.StatusInternalServerError, "error.tmpl", gin.H{"message": message})
 	c.Abort()
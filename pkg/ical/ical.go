@@ -0,0 +1,148 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package ical
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	StatusConfirmed = "CONFIRMED"
+	StatusCancelled = "CANCELLED"
+)
+
+const timeFormat = "20060102T150405Z"
+
+// Calendar is an RFC 5545 calendar of events
+type Calendar struct {
+	// Name shown for the calendar by clients that support it
+	Name   string
+	Events []*Event
+}
+
+// Event is a VEVENT. UID must stay the same for the life of the event so clients update it in place, and Sequence
+// must increase whenever the event changes.
+type Event struct {
+	UID          string
+	Sequence     int
+	Status       string
+	Summary      string
+	Description  string
+	Location     string
+	URL          string
+	Start        time.Time
+	End          time.Time
+	LastModified time.Time
+}
+
+// Sequence derives a SEQUENCE number from when a record was created and last updated. It is the number of seconds
+// between the two, which only ever grows as the record is changed.
+func Sequence(created, updated time.Time) int {
+	if !updated.After(created) {
+		return 0
+	}
+
+	return int(updated.Sub(created) / time.Second)
+}
+
+// Write writes the calendar to w, stamped with now
+func (c *Calendar) Write(w io.Writer, now time.Time) error {
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//ADH Partnership//API//EN",
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+	}
+	if c.Name != "" {
+		lines = append(lines, "X-WR-CALNAME:"+escape(c.Name))
+	}
+
+	for _, e := range c.Events {
+		status := e.Status
+		if status == "" {
+			status = StatusConfirmed
+		}
+		lines = append(lines,
+			"BEGIN:VEVENT",
+			"UID:"+escape(e.UID),
+			"DTSTAMP:"+now.UTC().Format(timeFormat),
+			"DTSTART:"+e.Start.UTC().Format(timeFormat),
+			"DTEND:"+e.End.UTC().Format(timeFormat),
+			fmt.Sprintf("SEQUENCE:%d", e.Sequence),
+			"STATUS:"+status,
+			"SUMMARY:"+escape(e.Summary),
+		)
+		if e.Description != "" {
+			lines = append(lines, "DESCRIPTION:"+escape(e.Description))
+		}
+		if e.Location != "" {
+			lines = append(lines, "LOCATION:"+escape(e.Location))
+		}
+		if e.URL != "" {
+			lines = append(lines, "URL:"+e.URL)
+		}
+		if !e.LastModified.IsZero() {
+			lines = append(lines, "LAST-MODIFIED:"+e.LastModified.UTC().Format(timeFormat))
+		}
+		lines = append(lines, "END:VEVENT")
+	}
+	lines = append(lines, "END:VCALENDAR")
+
+	for _, line := range lines {
+		if _, err := io.WriteString(w, fold(line)+"\r\n"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// escape escapes a TEXT value
+func escape(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", "",
+	).Replace(s)
+}
+
+// fold splits a content line into lines of at most 75 octets, continuation lines starting with a space. Lines are
+// only split between UTF-8 characters.
+func fold(line string) string {
+	const limit = 75
+
+	var b strings.Builder
+	n := 0
+	for _, r := range line {
+		size := len(string(r))
+		if n+size > limit {
+			b.WriteString("\r\n ")
+			n = 1
+		}
+		b.WriteRune(r)
+		n += size
+	}
+
+	return b.String()
+}
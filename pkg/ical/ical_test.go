@@ -0,0 +1,77 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrite(t *testing.T) {
+	start := time.Date(2024, time.January, 6, 23, 0, 0, 0, time.UTC)
+	cal := &Calendar{
+		Name: "ZDV Events",
+		Events: []*Event{
+			{
+				UID:         "event-1@zdvartcc.org",
+				Sequence:    3,
+				Summary:     "Denver FNO, with friends",
+				Description: "Line one\nLine two; more",
+				Start:       start,
+				End:         start.Add(3 * time.Hour),
+			},
+			{UID: "event-2@zdvartcc.org", Status: StatusCancelled, Summary: "Cancelled", Start: start, End: start},
+		},
+	}
+
+	var b bytes.Buffer
+	assert.NoError(t, cal.Write(&b, start.Add(-time.Hour)))
+
+	out := b.String()
+	assert.True(t, strings.HasPrefix(out, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+	assert.True(t, strings.HasSuffix(out, "END:VCALENDAR\r\n"))
+	assert.Contains(t, out, "X-WR-CALNAME:ZDV Events\r\n")
+	assert.Contains(t, out, "UID:event-1@zdvartcc.org\r\nDTSTAMP:20240106T220000Z\r\nDTSTART:20240106T230000Z\r\nDTEND:20240107T020000Z\r\n")
+	assert.Contains(t, out, "SEQUENCE:3\r\nSTATUS:CONFIRMED\r\nSUMMARY:Denver FNO\\, with friends\r\n")
+	assert.Contains(t, out, `DESCRIPTION:Line one\nLine two\; more`+"\r\n")
+	assert.Contains(t, out, "STATUS:CANCELLED\r\n")
+	assert.Equal(t, 2, strings.Count(out, "BEGIN:VEVENT"))
+}
+
+func TestFold(t *testing.T) {
+	assert.Equal(t, "short", fold("short"))
+
+	folded := fold(strings.Repeat("a", 80))
+	assert.Equal(t, strings.Repeat("a", 75)+"\r\n "+strings.Repeat("a", 5), folded)
+
+	// Multi-byte characters are never split across lines
+	for _, line := range strings.Split(fold("DESCRIPTION:"+strings.Repeat("é", 60)), "\r\n") {
+		assert.LessOrEqual(t, len(line), 75)
+		assert.True(t, strings.ToValidUTF8(line, "?") == line)
+	}
+}
+
+func TestSequence(t *testing.T) {
+	created := time.Date(2024, time.January, 6, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, 0, Sequence(created, created))
+	assert.Equal(t, 90, Sequence(created, created.Add(90*time.Second)))
+	assert.Equal(t, 0, Sequence(created, created.Add(-time.Minute)))
+}
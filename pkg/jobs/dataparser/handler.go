@@ -34,6 +34,7 @@ import (
 	"github.com/adh-partnership/api/pkg/database/models"
 	"github.com/adh-partnership/api/pkg/database/models/constants"
 	"github.com/adh-partnership/api/pkg/discord"
+	"github.com/adh-partnership/api/pkg/events"
 	"github.com/adh-partnership/api/pkg/geo"
 	"github.com/adh-partnership/api/pkg/logger"
 	"github.com/adh-partnership/api/pkg/network/vatsim"
@@ -42,6 +43,9 @@ import (
 
 var fac []Facility
 
+// flightLogGap is how long a pilot can disappear from the network and still continue the same flight log
+const flightLogGap = 10 * time.Minute
+
 var log = logger.Logger.WithField("component", "job/flightparser")
 
 func Initialize(cron *gocron.Scheduler) error {
@@ -51,6 +55,12 @@ func Initialize(cron *gocron.Scheduler) error {
 		return err
 	}
 
+	_, err = cron.Every(1).Day().SingletonMode().Do(pruneFlightLogs)
+	if err != nil {
+		log.Errorf("Error scheduling PruneFlightLogs: %v", err)
+		return err
+	}
+
 	jsonfile, err := os.Open("boundaries.json")
 	if err != nil {
 		log.Errorf("Error opening boundaries.json: %v", err)
@@ -215,8 +225,68 @@ func parseATC(atcDone chan bool, controllers []*vatsim.VATSIMController) {
 	atcDone <- true
 }
 
+// recordMovement records the flight taking off from or landing at an airport of a running event
+func recordMovement(tracked map[string]*models.Airport, f *models.Flights, prevSpeed int) {
+	kind := events.DetectMovement(prevSpeed, f.Groundspeed)
+	icao := f.Departure
+	if kind == events.MovementArrival {
+		icao = f.Arrival
+	}
+
+	airport, ok := tracked[icao]
+	if kind == "" || !ok {
+		return
+	}
+	if !events.NearAirport(float64(f.Latitude), float64(f.Longitude), float64(airport.Latitude), float64(airport.Longitude)) {
+		return
+	}
+
+	if err := database.DB.Create(&models.FlightMovement{
+		Airport:  icao,
+		Callsign: f.Callsign,
+		CID:      f.CID,
+		Kind:     kind,
+		MovedAt:  time.Now(),
+	}).Error; err != nil {
+		log.Errorf("Error recording %s of %s at %s: %v", kind, f.Callsign, icao, err)
+	}
+}
+
+// logFlight extends the pilot's log of flying the callsign within the facility, starting a new one after a gap
+func logFlight(f *models.Flights, now time.Time) {
+	flightLog := &models.FlightLog{}
+	err := database.DB.Where("callsign = ? AND cid = ? AND last_seen > ?", f.Callsign, f.CID, now.Add(-flightLogGap)).
+		Order("last_seen desc").First(flightLog).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Errorf("Error looking up flight log of %s: %v", f.Callsign, err)
+		return
+	}
+	if flightLog.ID == 0 {
+		flightLog = &models.FlightLog{Callsign: f.Callsign, CID: f.CID, FirstSeen: now}
+	}
+	flightLog.LastSeen = now
+
+	if err := database.DB.Save(flightLog).Error; err != nil {
+		log.Errorf("Error saving flight log of %s: %v", f.Callsign, err)
+	}
+}
+
+func pruneFlightLogs() {
+	before := time.Now().AddDate(0, 0, -config.Cfg.Facility.Feedback.FlightLogDays)
+	if err := database.DB.Where("last_seen < ?", before).Delete(&models.FlightLog{}).Error; err != nil {
+		log.Errorf("Error pruning flight logs: %v", err)
+	}
+}
+
 func parseFlights(flightDone chan bool, flights []*vatsim.VATSIMFlight) {
 	updateid, _ := gonanoid.New(24)
+	now := time.Now()
+	host := events.HostFacility()
+
+	tracked, err := events.TrackedAirports(time.Now())
+	if err != nil {
+		log.Errorf("Error looking up airports of running events: %v", err)
+	}
 
 	for _, flight := range flights {
 		f := &models.Flights{}
@@ -226,6 +296,7 @@ func parseFlights(flightDone chan bool, flights []*vatsim.VATSIMFlight) {
 				continue
 			}
 		}
+		known, prevSpeed := f.ID != 0, f.Groundspeed
 
 		f.Aircraft = flight.FlightPlan.Aircraft
 		f.CID = flight.CID
@@ -255,6 +326,13 @@ func parseFlights(flightDone chan bool, flights []*vatsim.VATSIMFlight) {
 		if err := database.DB.Save(&f).Error; err != nil {
 			log.Error("Error saving flight information for " + f.Callsign + " to database: " + err.Error())
 		}
+
+		if known && len(tracked) > 0 {
+			recordMovement(tracked, f, prevSpeed)
+		}
+		if f.Facility == host {
+			logFlight(f, now)
+		}
 	}
 
 	if err := database.DB.Where("update_id != ?", updateid).Delete(&models.Flights{}).Error; err != nil {
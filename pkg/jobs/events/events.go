@@ -0,0 +1,124 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package events
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-co-op/gocron"
+
+	"github.com/adh-partnership/api/pkg/config"
+	"github.com/adh-partnership/api/pkg/database"
+	"github.com/adh-partnership/api/pkg/database/models/constants"
+	"github.com/adh-partnership/api/pkg/events"
+	"github.com/adh-partnership/api/pkg/logger"
+)
+
+var log = logger.Logger.WithField("component", "job/events")
+
+func ScheduleJobs(s *gocron.Scheduler) error {
+	_, err := s.Every(15).Minutes().SingletonMode().Do(SendReminders)
+	if err != nil {
+		log.Errorf("Error scheduling SendReminders: %s", err)
+		return err
+	}
+
+	_, err = s.Every(1).Hour().SingletonMode().Do(GenerateRecurringEvents)
+	if err != nil {
+		log.Errorf("Error scheduling GenerateRecurringEvents: %s", err)
+		return err
+	}
+
+	return nil
+}
+
+// SendReminders reminds assigned controllers of upcoming events once each configured reminder is due
+func SendReminders() error {
+	if !events.NotificationsEnabled() {
+		return nil
+	}
+
+	hours := config.Cfg.Facility.Events.Notifications.ReminderHours
+	longest := 0
+	for _, h := range hours {
+		if h > longest {
+			longest = h
+		}
+	}
+	if longest == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	upcoming, err := database.FindEventsStartingBefore(now.Add(time.Duration(longest) * time.Hour))
+	if err != nil {
+		log.Errorf("Error getting upcoming events: %s", err)
+		return err
+	}
+
+	for _, event := range upcoming {
+		sent, err := database.FindEventReminderHours(event.ID)
+		if err != nil {
+			log.Errorf("Error getting reminders sent for event %d: %s", event.ID, err)
+			continue
+		}
+
+		due, ok := events.DueReminder(event.StartDate, now, hours, sent)
+		if !ok {
+			continue
+		}
+
+		events.NotifyControllers(event, events.AssignedControllers(event), events.ControllerAssignments(event),
+			constants.EventNotificationReminder, due, fmt.Sprintf("Reminder: %s starts in %s", event.Title, startsIn(event.StartDate.Sub(now))))
+	}
+
+	return nil
+}
+
+// GenerateRecurringEvents creates the upcoming events of every recurring event template
+func GenerateRecurringEvents() error {
+	templates, err := database.FindRecurringEventTemplates()
+	if err != nil {
+		log.Errorf("Error getting recurring event templates: %s", err)
+		return err
+	}
+
+	now := time.Now()
+	until := now.AddDate(0, 0, config.Cfg.Facility.Events.RecurringDaysAhead)
+	for _, template := range templates {
+		created, err := events.GenerateRecurring(template, now, until)
+		if err != nil {
+			log.Errorf("Error generating events of template %d: %s", template.ID, err)
+		}
+		for _, event := range created {
+			log.Infof("Created event %d, %s at %s, from template %d", event.ID, event.Title, event.StartDate, template.ID)
+		}
+	}
+
+	return nil
+}
+
+func startsIn(d time.Duration) string {
+	if d < time.Hour {
+		return fmt.Sprintf("%d minutes", int(d.Minutes()))
+	}
+	if d < 2*time.Hour {
+		return "1 hour"
+	}
+	return fmt.Sprintf("%d hours", int(d.Hours()))
+}
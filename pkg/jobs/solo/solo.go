@@ -0,0 +1,177 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package solo
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-co-op/gocron"
+
+	"github.com/adh-partnership/api/pkg/config"
+	"github.com/adh-partnership/api/pkg/database"
+	"github.com/adh-partnership/api/pkg/database/models"
+	"github.com/adh-partnership/api/pkg/database/models/constants"
+	"github.com/adh-partnership/api/pkg/email"
+	"github.com/adh-partnership/api/pkg/logger"
+	"github.com/adh-partnership/api/pkg/training"
+)
+
+var log = logger.Logger.WithField("component", "job/solo")
+
+func ScheduleJobs(s *gocron.Scheduler) error {
+	_, err := s.Every(1).Hour().SingletonMode().Do(CheckSoloEndorsements)
+	if err != nil {
+		log.Errorf("Error scheduling CheckSoloEndorsements: %s", err)
+		return err
+	}
+
+	return nil
+}
+
+// CheckSoloEndorsements starts solo endorsements that were issued for a later start, warns about the ones that are
+// about to expire and lapses the expired ones
+func CheckSoloEndorsements() error {
+	endorsements, err := database.FindOpenSoloEndorsements()
+	if err != nil {
+		log.Errorf("Error getting open solo endorsements: %s", err)
+		return err
+	}
+
+	now := time.Now()
+	warnBefore := time.Duration(config.Cfg.Facility.Solo.WarnDaysBefore) * 24 * time.Hour
+	for _, e := range endorsements {
+		switch {
+		case training.SoloLapsed(e, now):
+			lapse(e, now)
+		case training.SoloDue(e, now):
+			start(e, now)
+		case training.SoloNeedsWarning(e, now, warnBefore):
+			warn(e, now)
+		}
+	}
+
+	return nil
+}
+
+func warn(e *models.SoloEndorsement, now time.Time) {
+	e.WarnedAt = &now
+	if err := database.DB.Model(e).Update("warned_at", e.WarnedAt).Error; err != nil {
+		log.Errorf("Error marking solo endorsement %d as warned: %s", e.ID, err)
+		return
+	}
+
+	sendEmail(e, email.Templates["solo_expiring"])
+	if err := training.NotifySolo(e, fmt.Sprintf("Solo endorsement expires in %s", formatRemaining(e.ExpiresAt.Sub(now)))); err != nil {
+		log.Warnf("Error sending solo endorsement message to Discord: %s", err)
+	}
+}
+
+func start(e *models.SoloEndorsement, now time.Time) {
+	current, err := database.FindUserCertificationValue(e.StudentID, e.Certification)
+	if err != nil {
+		log.Errorf("Error getting certification %s for %d: %s", e.Certification, e.StudentID, err)
+		return
+	}
+
+	// The student was certified before the endorsement started, so there is nothing left for it to do
+	if current == constants.CertificationCertified || current == constants.CertificationCanTrain {
+		if err := database.EndSoloEndorsement(e, constants.SoloEndedCertified, "", nil, now); err != nil {
+			log.Errorf("Error ending solo endorsement %d: %s", e.ID, err)
+		}
+		return
+	}
+
+	err = database.StartSoloEndorsement(e, current)
+	var prerequisiteErr *database.PrerequisiteError
+	if errors.As(err, &prerequisiteErr) {
+		log.Warnf("Solo endorsement %d could not start: %s", e.ID, prerequisiteErr)
+		e.Pending = true
+		if err := database.EndSoloEndorsement(e, constants.SoloEndedBlocked, "", nil, now); err != nil {
+			log.Errorf("Error ending solo endorsement %d: %s", e.ID, err)
+			return
+		}
+		if err := training.NotifySolo(e, fmt.Sprintf("Solo endorsement could not start: %s", prerequisiteErr)); err != nil {
+			log.Warnf("Error sending solo endorsement message to Discord: %s", err)
+		}
+		return
+	}
+	if err != nil {
+		log.Errorf("Error starting solo endorsement %d: %s", e.ID, err)
+		return
+	}
+
+	if err := training.NotifySolo(e, "Solo endorsement has started"); err != nil {
+		log.Warnf("Error sending solo endorsement message to Discord: %s", err)
+	}
+}
+
+func lapse(e *models.SoloEndorsement, now time.Time) {
+	current, err := database.FindUserCertificationValue(e.StudentID, e.Certification)
+	if err != nil {
+		log.Errorf("Error getting certification %s for %d: %s", e.Certification, e.StudentID, err)
+		return
+	}
+
+	restore, _ := training.SoloRestoreValue(e, current)
+	if err := database.EndSoloEndorsement(e, constants.SoloEndedLapsed, restore, nil, now); err != nil {
+		log.Errorf("Error lapsing solo endorsement %d: %s", e.ID, err)
+		return
+	}
+
+	sendEmail(e, email.Templates["solo_expired"])
+	content := "Solo endorsement has lapsed"
+	if restore != "" {
+		content += fmt.Sprintf(", %s certification reverted to %s", e.Certification, restore)
+	}
+	if err := training.NotifySolo(e, content); err != nil {
+		log.Warnf("Error sending solo endorsement message to Discord: %s", err)
+	}
+}
+
+func sendEmail(e *models.SoloEndorsement, template string) {
+	if e.Student == nil || e.Student.Email == "" {
+		return
+	}
+
+	err := email.Send(
+		e.Student.Email,
+		"",
+		"",
+		template,
+		map[string]interface{}{
+			"FirstName":     e.Student.FirstName,
+			"LastName":      e.Student.LastName,
+			"Certification": e.Certification,
+			"Position":      e.Position,
+			"ExpiresAt":     e.ExpiresAt.UTC().Format("2006-01-02 15:04Z"),
+		},
+	)
+	if err != nil {
+		log.Errorf("Error sending %s email for solo endorsement %d: %s", template, e.ID, err)
+	}
+}
+
+func formatRemaining(d time.Duration) string {
+	days := int(d.Hours() / 24)
+	if days >= 1 {
+		return fmt.Sprintf("%d day(s)", days)
+	}
+
+	return fmt.Sprintf("%d hour(s)", int(d.Hours()))
+}
@@ -0,0 +1,156 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package trainingsync
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-co-op/gocron"
+
+	"github.com/adh-partnership/api/pkg/database"
+	"github.com/adh-partnership/api/pkg/database/models"
+	"github.com/adh-partnership/api/pkg/database/models/constants"
+	"github.com/adh-partnership/api/pkg/logger"
+	"github.com/adh-partnership/api/pkg/network/vatusa"
+	"github.com/adh-partnership/api/pkg/training"
+)
+
+var log = logger.Logger.WithField("component", "job/trainingsync")
+
+func ScheduleJobs(s *gocron.Scheduler) error {
+	_, err := s.Every(1).Minute().SingletonMode().Do(SyncTrainingNotes)
+	if err != nil {
+		log.Errorf("Error scheduling SyncTrainingNotes: %s", err)
+		return err
+	}
+
+	return nil
+}
+
+// SyncTrainingNotes pushes pending training note changes to VATUSA
+func SyncTrainingNotes() error {
+	notes, err := database.FindTrainingNotesToSync(time.Now())
+	if err != nil {
+		log.Errorf("Error getting training notes to sync: %s", err)
+		return err
+	}
+
+	for _, note := range notes {
+		syncNote(note)
+	}
+
+	return nil
+}
+
+func syncNote(note *models.TrainingNote) {
+	// Changes made while we talk to VATUSA bump updated_at, in which case the note is left pending for the next run
+	updatedAt := note.UpdatedAt
+
+	err := pushNote(note)
+	if err == nil && note.SyncAction == constants.TrainingNoteSyncActionDelete {
+		tx := database.DB.Unscoped().Where("id = ? AND updated_at = ?", note.ID, updatedAt).Delete(&models.TrainingNote{})
+		if tx.Error != nil {
+			log.Errorf("Error purging training note %d: %s", note.ID, tx.Error)
+			return
+		}
+		if tx.RowsAffected > 0 {
+			if err := database.DB.Where(models.TrainingNoteProgress{TrainingNoteID: note.ID}).Delete(&models.TrainingNoteProgress{}).Error; err != nil {
+				log.Errorf("Error purging progress of training note %d: %s", note.ID, err)
+			}
+		}
+		return
+	}
+
+	now := time.Now()
+	if err != nil {
+		log.Warnf("Error syncing training note %d (%s, attempt %d): %s", note.ID, note.SyncAction, note.SyncAttempts+1, err)
+		training.SyncFailed(note, err, now)
+		if note.SyncStatus == constants.TrainingNoteSyncFailed {
+			log.Errorf("Giving up syncing training note %d to VATUSA after %d attempts", note.ID, note.SyncAttempts)
+		}
+	} else {
+		training.SyncSucceeded(note, now)
+	}
+
+	if err := database.DB.Model(&models.TrainingNote{}).Unscoped().Where("id = ? AND updated_at = ?", note.ID, updatedAt).
+		Updates(map[string]interface{}{
+			"sync_status":   note.SyncStatus,
+			"sync_action":   note.SyncAction,
+			"sync_attempts": note.SyncAttempts,
+			"sync_error":    note.SyncError,
+			"next_sync_at":  note.NextSyncAt,
+			"synced_at":     note.SyncedAt,
+		}).Error; err != nil {
+		log.Errorf("Error updating sync status of training note %d: %s", note.ID, err)
+	}
+}
+
+// pushNote sends the note's pending change to VATUSA
+func pushNote(note *models.TrainingNote) error {
+	if note.Controller == nil || note.Instructor == nil || note.SessionDate == nil {
+		return fmt.Errorf("training note is missing its controller, instructor or session date")
+	}
+
+	switch {
+	case note.SyncAction == constants.TrainingNoteSyncActionDelete:
+		if note.VATUSAID == 0 {
+			return nil
+		}
+		status, err := vatusa.DeleteTrainingNote(fmt.Sprint(note.VATUSAID))
+		return checkStatus(status, err)
+	case note.VATUSAID == 0:
+		status, id, err := vatusa.SubmitTrainingNote(
+			fmt.Sprint(note.Controller.CID),
+			fmt.Sprint(note.Instructor.CID),
+			note.Position,
+			*note.SessionDate,
+			note.Duration,
+			note.Comments,
+			note.Type,
+		)
+		if err := checkStatus(status, err); err != nil {
+			return err
+		}
+		note.VATUSAID = uint(id)
+		// Record the VATUSA ID straight away, without touching updated_at, so it is never submitted twice
+		return database.DB.Model(&models.TrainingNote{}).Unscoped().Where("id = ?", note.ID).UpdateColumn("vatusa_id", note.VATUSAID).Error
+	default:
+		status, err := vatusa.EditTrainingNote(
+			fmt.Sprint(note.VATUSAID),
+			fmt.Sprint(note.Controller.CID),
+			fmt.Sprint(note.Instructor.CID),
+			note.Position,
+			*note.SessionDate,
+			note.Duration,
+			note.Comments,
+			note.Type,
+		)
+		return checkStatus(status, err)
+	}
+}
+
+func checkStatus(status int, err error) error {
+	if err != nil {
+		return err
+	}
+	if status > 299 {
+		return fmt.Errorf("VATUSA returned status code %d", status)
+	}
+
+	return nil
+}
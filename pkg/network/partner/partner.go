@@ -0,0 +1,70 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package partner
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/adh-partnership/api/pkg/config"
+	"github.com/adh-partnership/api/pkg/logger"
+	"github.com/adh-partnership/api/pkg/network"
+)
+
+var log = logger.Logger.WithField("component", "network/partner")
+
+// ErrUnknownPartner is returned for facilities without a partner API configured
+var ErrUnknownPartner = errors.New("partner API not configured")
+
+// GetSharedEvent gets an event the partner hosts as seen through its shared roster, returning the status and body of
+// the partner's response
+func GetSharedEvent(facility string, id uint) (int, []byte, error) {
+	return handle("GET", facility, fmt.Sprintf("/v1/events/%d/shared", id), nil)
+}
+
+// PutSharedAssignments sends the assignments of our controllers to an event the partner hosts, returning the status
+// and body of the partner's response
+func PutSharedAssignments(facility string, id uint, assignments interface{}) (int, []byte, error) {
+	return handle("PUT", facility, fmt.Sprintf("/v1/events/%d/shared/assignments", id), assignments)
+}
+
+func handle(method, facility, endpoint string, data interface{}) (int, []byte, error) {
+	partner, ok := config.Cfg.Facility.Events.Partners[facility]
+	if !ok {
+		return 0, nil, ErrUnknownPartner
+	}
+
+	body := ""
+	if data != nil {
+		j, err := json.Marshal(data)
+		if err != nil {
+			return 0, nil, err
+		}
+		body = string(j)
+	}
+
+	status, ret, err := network.HandleWithHeaders(method, strings.TrimRight(partner.URL, "/")+endpoint, "application/json", body, map[string]string{
+		"X-Api-Token": partner.APIKey,
+	})
+	if err != nil || status > 299 {
+		log.Warnf("Error calling %s %s%s: %d %v", facility, method, endpoint, status, err)
+	}
+
+	return status, ret, err
+}
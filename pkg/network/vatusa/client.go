@@ -0,0 +1,54 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package vatusa
+
+import (
+	"fmt"
+	"time"
+)
+
+// Client is the part of the VATUSA API used by workflows that need to be exercised without calling VATUSA.
+// DefaultClient talks to the API; tests can swap in their own implementation.
+type Client interface {
+	PromoteController(cid string, rating int, examiner uint, position string, examDate time.Time) (int, error)
+}
+
+type apiClient struct{}
+
+var DefaultClient Client = &apiClient{}
+
+// PromoteController sets the controller's rating at VATUSA, recording the examiner, position and date of the exam
+func PromoteController(cid string, rating int, examiner uint, position string, examDate time.Time) (int, error) {
+	return DefaultClient.PromoteController(cid, rating, examiner, position, examDate)
+}
+
+func (c *apiClient) PromoteController(cid string, rating int, examiner uint, position string, examDate time.Time) (int, error) {
+	status, body, err := handle("POST", "/v2/user/"+cid+"/rating", map[string]string{
+		"rating":   fmt.Sprint(rating),
+		"examDate": examDate.Format("2006-01-02"),
+		"examiner": fmt.Sprint(examiner),
+		"position": position,
+	})
+
+	if err != nil || status > 299 {
+		log.Errorf("Error promoting controller %s to %d: %s", cid, rating, err)
+		log.Errorf("Status: %d", status)
+		log.Errorf("Body: %s", body)
+	}
+
+	return status, err
+}
@@ -0,0 +1,179 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package rosterfile
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/adh-partnership/api/pkg/database"
+	"github.com/adh-partnership/api/pkg/database/models"
+	"github.com/adh-partnership/api/pkg/database/models/constants"
+)
+
+var errDryRun = errors.New("dry run")
+
+type ImportOptions struct {
+	Format   string
+	ActorID  *uint
+	Reason   string
+	Override bool
+	// Validate and diff the import without saving anything
+	DryRun bool
+}
+
+type ImportResult struct {
+	DryRun  bool                 `json:"dry_run"`
+	Import  *models.RosterImport `json:"import"`
+	Changes []*Change            `json:"changes"`
+	Errors  []string             `json:"errors"`
+}
+
+// Export writes the facility roster, every controller that is not of type none, to w in format
+func Export(w io.Writer, format string) error {
+	var users []*models.User
+	if err := database.DB.Preload("Rating").Not(&models.User{ControllerType: constants.ControllerTypeNone}).Find(&users).Error; err != nil {
+		return err
+	}
+
+	var cids []uint
+	for _, u := range users {
+		cids = append(cids, u.CID)
+	}
+	var certs []*models.UserCertification
+	if len(cids) > 0 {
+		if err := database.DB.Where("cid IN ?", cids).Find(&certs).Error; err != nil {
+			return err
+		}
+	}
+
+	return Write(w, format, BuildEntries(users, certs), database.GetCertifications())
+}
+
+// Import reads a roster file from r and applies its certification values in a single transaction, recording a
+// RosterImport audit entry that every change links to. Problems with the file, including unmet certification
+// prerequisites, are returned in the result's errors and nothing is saved. With DryRun set the import runs in a
+// transaction that is always rolled back.
+func Import(r io.Reader, opts ImportOptions) (*ImportResult, error) {
+	result := &ImportResult{DryRun: opts.DryRun, Changes: []*Change{}, Errors: []string{}}
+
+	if opts.Override && strings.TrimSpace(opts.Reason) == "" {
+		result.Errors = append(result.Errors, database.ErrOverrideReasonRequired.Error())
+		return result, nil
+	}
+
+	entries, err := Read(r, opts.Format)
+	if err != nil {
+		result.Errors = append(result.Errors, err.Error())
+		return result, nil
+	}
+
+	current, err := findCurrentValues()
+	if err != nil {
+		return nil, err
+	}
+
+	changes, errs := Plan(entries, database.GetCertifications(), current)
+	result.Changes = changes
+	if len(errs) > 0 {
+		result.Errors = errs
+		return result, nil
+	}
+
+	reason := opts.Reason
+	if reason == "" {
+		reason = "Roster import"
+	}
+
+	rosterImport := &models.RosterImport{
+		ActorID:  opts.ActorID,
+		Format:   opts.Format,
+		Reason:   opts.Reason,
+		Override: opts.Override,
+		Changes:  len(changes),
+	}
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Create(rosterImport).Error; err != nil {
+			return err
+		}
+
+		for _, c := range changes {
+			err := database.ChangeUserCertification(tx, &models.CertificationChange{
+				CID:            c.CID,
+				Certification:  c.Certification,
+				NewValue:       c.NewValue,
+				Source:         constants.CertificationChangeSourceImport,
+				ActorID:        opts.ActorID,
+				Reason:         reason,
+				Override:       opts.Override,
+				RosterImportID: &rosterImport.ID,
+			})
+			var prerequisiteErr *database.PrerequisiteError
+			if errors.As(err, &prerequisiteErr) {
+				result.Errors = append(result.Errors, fmt.Sprintf("cid %d: %s", c.CID, err))
+				continue
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(result.Errors) > 0 || opts.DryRun {
+			return errDryRun
+		}
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		return nil, err
+	}
+	if err == nil {
+		result.Import = rosterImport
+	}
+
+	return result, nil
+}
+
+// findCurrentValues returns the certification values of every user, users without any are included with none
+func findCurrentValues() (map[uint]map[string]string, error) {
+	var cids []uint
+	if err := database.DB.Model(&models.User{}).Pluck("cid", &cids).Error; err != nil {
+		return nil, err
+	}
+
+	ret := map[uint]map[string]string{}
+	for _, cid := range cids {
+		ret[cid] = map[string]string{}
+	}
+
+	var certs []*models.UserCertification
+	if err := database.DB.Find(&certs).Error; err != nil {
+		return nil, err
+	}
+	for _, c := range certs {
+		if _, ok := ret[c.CID]; ok {
+			ret[c.CID][c.Name] = c.Value
+		}
+	}
+
+	return ret, nil
+}
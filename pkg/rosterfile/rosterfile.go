@@ -0,0 +1,301 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package rosterfile
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/adh-partnership/api/pkg/database/models"
+	"github.com/adh-partnership/api/pkg/database/models/constants"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+)
+
+// Columns written before the certification columns of a CSV roster. Only cid is read back on import, the roster
+// sync owns the rest.
+var fixedColumns = []string{"cid", "first_name", "last_name", "rating", "controller_type"}
+
+// Entry is a controller's row in a roster file
+type Entry struct {
+	CID            uint              `json:"cid"`
+	FirstName      string            `json:"first_name"`
+	LastName       string            `json:"last_name"`
+	Rating         string            `json:"rating"`
+	ControllerType string            `json:"controller_type"`
+	Certifications map[string]string `json:"certifications"`
+}
+
+// Change is a certification value an import would change
+type Change struct {
+	CID           uint   `json:"cid"`
+	Certification string `json:"certification"`
+	OldValue      string `json:"old_value"`
+	NewValue      string `json:"new_value"`
+}
+
+func IsValidFormat(format string) bool {
+	return format == FormatCSV || format == FormatJSON
+}
+
+// BuildEntries returns the roster entries of the users, with certs holding every user's certifications
+func BuildEntries(users []*models.User, certs []*models.UserCertification) []*Entry {
+	byUser := map[uint]map[string]string{}
+	for _, c := range certs {
+		if _, ok := byUser[c.CID]; !ok {
+			byUser[c.CID] = map[string]string{}
+		}
+		byUser[c.CID][c.Name] = c.Value
+	}
+
+	ret := []*Entry{}
+	for _, u := range users {
+		e := &Entry{
+			CID:            u.CID,
+			FirstName:      u.FirstName,
+			LastName:       u.LastName,
+			Rating:         u.Rating.Short,
+			ControllerType: u.ControllerType,
+			Certifications: byUser[u.CID],
+		}
+		if e.Certifications == nil {
+			e.Certifications = map[string]string{}
+		}
+		ret = append(ret, e)
+	}
+
+	sort.SliceStable(ret, func(i, j int) bool {
+		return ret[i].CID < ret[j].CID
+	})
+
+	return ret
+}
+
+// Write writes the entries to w in format. Every certification gets a column, or a key in JSON, defaulting to none.
+func Write(w io.Writer, format string, entries []*Entry, certifications []models.Certification) error {
+	sorted := sortCertifications(certifications)
+	for _, e := range entries {
+		for _, c := range sorted {
+			if _, ok := e.Certifications[c.Name]; !ok {
+				e.Certifications[c.Name] = constants.CertificationNone
+			}
+		}
+	}
+
+	if format == FormatJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(entries)
+	}
+
+	cw := csv.NewWriter(w)
+	header := append([]string{}, fixedColumns...)
+	for _, c := range sorted {
+		header = append(header, c.Name)
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		record := []string{fmt.Sprint(e.CID), e.FirstName, e.LastName, e.Rating, e.ControllerType}
+		for _, c := range sorted {
+			record = append(record, e.Certifications[c.Name])
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+
+	return cw.Error()
+}
+
+// Read parses a roster file in format. CSV files need a header row with a cid column, every column that is not one
+// of the fixed columns is treated as a certification. Empty certification values are left out.
+func Read(r io.Reader, format string) ([]*Entry, error) {
+	if format == FormatJSON {
+		var entries []*Entry
+		if err := json.NewDecoder(r).Decode(&entries); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+		for _, e := range entries {
+			if e == nil {
+				return nil, fmt.Errorf("invalid JSON: null entry")
+			}
+			certs := map[string]string{}
+			for name, value := range e.Certifications {
+				if value = normalize(value); value != "" {
+					certs[strings.TrimSpace(name)] = value
+				}
+			}
+			e.Certifications = certs
+		}
+		return entries, nil
+	}
+
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("invalid CSV: missing header row")
+	}
+
+	cidColumn := -1
+	certColumns := map[int]string{}
+	for i, name := range records[0] {
+		name = strings.TrimSpace(name)
+		switch {
+		case strings.EqualFold(name, "cid"):
+			cidColumn = i
+		case !isFixedColumn(name) && name != "":
+			certColumns[i] = name
+		}
+	}
+	if cidColumn == -1 {
+		return nil, fmt.Errorf("invalid CSV: missing cid column")
+	}
+
+	entries := []*Entry{}
+	for line, record := range records[1:] {
+		cid, err := strconv.ParseUint(strings.TrimSpace(record[cidColumn]), 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid cid %q", line+2, record[cidColumn])
+		}
+
+		e := &Entry{CID: uint(cid), Certifications: map[string]string{}}
+		for i, name := range certColumns {
+			if value := normalize(record[i]); value != "" {
+				e.Certifications[name] = value
+			}
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, nil
+}
+
+// Plan validates the entries against the certifications and the current certification values of every known user,
+// returning the changes needed to apply them. Certification names are matched case insensitively and changes use the
+// certification's own name. Changes are ordered by controller and certification order, so a certification's
+// prerequisites are normally set before it.
+func Plan(entries []*Entry, certifications []models.Certification, current map[uint]map[string]string) ([]*Change, []string) {
+	order := map[string]int{}
+	known := map[string]string{}
+	for i, c := range sortCertifications(certifications) {
+		order[c.Name] = i
+		known[strings.ToLower(c.Name)] = c.Name
+	}
+
+	var errs []string
+	changes := []*Change{}
+	seen := map[uint]bool{}
+	for i, e := range entries {
+		if e.CID == 0 {
+			errs = append(errs, fmt.Sprintf("entry %d: missing cid", i+1))
+			continue
+		}
+		if seen[e.CID] {
+			errs = append(errs, fmt.Sprintf("entry %d: duplicate cid %d", i+1, e.CID))
+			continue
+		}
+		seen[e.CID] = true
+
+		values, ok := current[e.CID]
+		if !ok {
+			errs = append(errs, fmt.Sprintf("cid %d: user not found", e.CID))
+			continue
+		}
+
+		names := make([]string, 0, len(e.Certifications))
+		for name := range e.Certifications {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		planned := map[string]bool{}
+		for _, name := range names {
+			value := e.Certifications[name]
+			certification, ok := known[strings.ToLower(name)]
+			if !ok {
+				errs = append(errs, fmt.Sprintf("cid %d: unknown certification %s", e.CID, name))
+				continue
+			}
+			if planned[certification] {
+				errs = append(errs, fmt.Sprintf("cid %d: duplicate certification %s", e.CID, certification))
+				continue
+			}
+			planned[certification] = true
+			if _, ok := models.CertificationOptions[value]; !ok {
+				errs = append(errs, fmt.Sprintf("cid %d: invalid value %s for %s", e.CID, value, certification))
+				continue
+			}
+
+			old, ok := values[certification]
+			if !ok {
+				old = constants.CertificationNone
+			}
+			if old != value {
+				changes = append(changes, &Change{CID: e.CID, Certification: certification, OldValue: old, NewValue: value})
+			}
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].CID != changes[j].CID {
+			return changes[i].CID < changes[j].CID
+		}
+		return order[changes[i].Certification] < order[changes[j].Certification]
+	})
+
+	return changes, errs
+}
+
+func sortCertifications(certifications []models.Certification) []models.Certification {
+	sorted := make([]models.Certification, len(certifications))
+	copy(sorted, certifications)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Order < sorted[j].Order
+	})
+
+	return sorted
+}
+
+func isFixedColumn(name string) bool {
+	for _, c := range fixedColumns {
+		if strings.EqualFold(c, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// normalize trims and lowercases certification values, which are matched case insensitively
+func normalize(value string) string {
+	return strings.ToLower(strings.TrimSpace(value))
+}
@@ -0,0 +1,150 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package rosterfile
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/adh-partnership/api/pkg/database/models"
+)
+
+var certifications = []models.Certification{
+	{Name: "tower", Order: 2},
+	{Name: "ground", Order: 1},
+}
+
+func TestWriteAndRead(t *testing.T) {
+	users := []*models.User{
+		{CID: 2, FirstName: "Jane", LastName: "Doe", Rating: models.Rating{Short: "S2"}, ControllerType: "home"},
+		{CID: 1, FirstName: "John", LastName: "Smith", Rating: models.Rating{Short: "S1"}, ControllerType: "visitor"},
+	}
+	certs := []*models.UserCertification{
+		{CID: 2, Name: "ground", Value: "certified"},
+		{CID: 2, Name: "tower", Value: "training"},
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, Write(&buf, FormatCSV, BuildEntries(users, certs), certifications))
+	assert.Equal(t, "cid,first_name,last_name,rating,controller_type,ground,tower\n"+
+		"1,John,Smith,S1,visitor,none,none\n"+
+		"2,Jane,Doe,S2,home,certified,training\n", buf.String())
+
+	entries, err := Read(&buf, FormatCSV)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+	assert.Equal(t, uint(2), entries[1].CID)
+	assert.Equal(t, map[string]string{"ground": "certified", "tower": "training"}, entries[1].Certifications)
+
+	buf.Reset()
+	assert.NoError(t, Write(&buf, FormatJSON, BuildEntries(users, certs), certifications))
+	entries, err = Read(&buf, FormatJSON)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+	assert.Equal(t, "Doe", entries[1].LastName)
+	assert.Equal(t, map[string]string{"ground": "none", "tower": "none"}, entries[0].Certifications)
+}
+
+func TestReadNormalizesValues(t *testing.T) {
+	expected := map[string]string{"Ground": "certified", "TOWER": "training"}
+
+	entries, err := Read(strings.NewReader("CID, Ground ,TOWER\n1,Certified, training\n"), FormatCSV)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, entries[0].Certifications)
+
+	entries, err = Read(strings.NewReader(`[{"cid":1,"certifications":{" Ground ":"Certified","TOWER":"training"}}]`), FormatJSON)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, entries[0].Certifications)
+}
+
+func TestReadErrors(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Format   string
+		Input    string
+		Expected string
+	}{
+		{Name: "Empty CSV", Format: FormatCSV, Input: "", Expected: "invalid CSV: missing header row"},
+		{Name: "Missing cid column", Format: FormatCSV, Input: "ground\ncertified\n", Expected: "invalid CSV: missing cid column"},
+		{Name: "Invalid cid", Format: FormatCSV, Input: "cid,ground\nabc,certified\n", Expected: `line 2: invalid cid "abc"`},
+		{Name: "Invalid JSON", Format: FormatJSON, Input: "{", Expected: "invalid JSON: unexpected EOF"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			_, err := Read(strings.NewReader(test.Input), test.Format)
+			assert.EqualError(t, err, test.Expected)
+		})
+	}
+}
+
+func TestPlan(t *testing.T) {
+	current := map[uint]map[string]string{
+		1: {"ground": "certified"},
+		2: {},
+	}
+	entries := []*Entry{
+		{CID: 2, Certifications: map[string]string{"tower": "solo", "ground": "certified"}},
+		{CID: 1, Certifications: map[string]string{"ground": "certified", "center": "certified"}},
+		{CID: 2, Certifications: map[string]string{}},
+		{CID: 3, Certifications: map[string]string{"ground": "training"}},
+		{CID: 1, Certifications: map[string]string{}},
+		{Certifications: map[string]string{}},
+	}
+
+	changes, errs := Plan(entries[:1], certifications, current)
+	assert.Empty(t, errs)
+	assert.Equal(t, []*Change{
+		{CID: 2, Certification: "ground", OldValue: "none", NewValue: "certified"},
+		{CID: 2, Certification: "tower", OldValue: "none", NewValue: "solo"},
+	}, changes)
+
+	entries[0].Certifications["tower"] = "expert"
+	_, errs = Plan(entries, certifications, current)
+	assert.Equal(t, []string{
+		"cid 2: invalid value expert for tower",
+		"cid 1: unknown certification center",
+		"entry 3: duplicate cid 2",
+		"cid 3: user not found",
+		"entry 5: duplicate cid 1",
+		"entry 6: missing cid",
+	}, errs)
+}
+
+func TestPlanMatchesNamesCaseInsensitively(t *testing.T) {
+	certifications := []models.Certification{
+		{Name: "DCA_Tower", Order: 2},
+		{Name: "DCA_Ground", Order: 1},
+	}
+	current := map[uint]map[string]string{1: {"DCA_Ground": "certified"}}
+
+	changes, errs := Plan([]*Entry{
+		{CID: 1, Certifications: map[string]string{"dca_tower": "solo", "DCA_GROUND": "certified"}},
+	}, certifications, current)
+	assert.Empty(t, errs)
+	assert.Equal(t, []*Change{
+		{CID: 1, Certification: "DCA_Tower", OldValue: "none", NewValue: "solo"},
+	}, changes)
+
+	_, errs = Plan([]*Entry{
+		{CID: 1, Certifications: map[string]string{"dca_tower": "solo", "DCA_Tower": "certified"}},
+	}, certifications, current)
+	assert.Equal(t, []string{"cid 1: duplicate certification DCA_Tower"}, errs)
+}
@@ -101,7 +101,17 @@ func NewServer(o *ServerOpts) (*ServerStruct, error) {
 		&models.DelayedJob{},
 		&models.Document{},
 		&models.EventPosition{},
+		&models.EventPositionCertification{},
+		&models.EventShift{},
+		&models.EventNotification{},
+		&models.EventNoShow{},
+		&models.EventTemplate{},
+		&models.EventTemplatePosition{},
+		&models.StaffingRequest{},
+		&models.FlightMovement{},
+		&models.FlightLog{},
 		&models.Event{},
+		&models.EventFacility{},
 		&models.EventSignup{},
 		&models.Feedback{},
 		&models.Flights{},
@@ -117,6 +127,19 @@ func NewServer(o *ServerOpts) (*ServerStruct, error) {
 		&models.VisitorApplication{},
 		&models.TrainingRequest{},
 		&models.TrainingRequestSlot{},
+		&models.TrainingAvailability{},
+		&models.SyllabusItem{},
+		&models.TrainingNoteProgress{},
+		&models.SoloEndorsement{},
+		&models.CertificationChange{},
+		&models.OTSRubric{},
+		&models.OTSRubricCategory{},
+		&models.OTSEvaluation{},
+		&models.OTSEvaluationScore{},
+		&models.PromotionRequest{},
+		&models.MentorAssignment{},
+		&models.CertificationPrerequisite{},
+		&models.RosterImport{},
 	)
 	if err != nil {
 		log.Errorf("Failed to run migrations: %v", err)
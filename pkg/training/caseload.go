@@ -0,0 +1,103 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package training
+
+import (
+	"sort"
+
+	"github.com/adh-partnership/api/pkg/database/models"
+)
+
+type MentorCaseload struct {
+	Mentor     *models.User   `json:"mentor"`
+	Students   []*models.User `json:"students"`
+	Count      int            `json:"count"`
+	Overloaded bool           `json:"overloaded"`
+}
+
+// BuildCaseload groups the active assignments by mentor. Every member of staff is listed, including those without
+// students, and mentors with more than maxStudents students are flagged as overloaded. The busiest mentors come first.
+func BuildCaseload(staff []*models.User, assignments []*models.MentorAssignment, maxStudents int) []*MentorCaseload {
+	byMentor := map[uint]*MentorCaseload{}
+	var ret []*MentorCaseload
+	for _, s := range staff {
+		if _, ok := byMentor[s.CID]; ok {
+			continue
+		}
+		byMentor[s.CID] = &MentorCaseload{Mentor: s, Students: []*models.User{}}
+		ret = append(ret, byMentor[s.CID])
+	}
+
+	for _, a := range assignments {
+		if a.EndedAt != nil {
+			continue
+		}
+		c, ok := byMentor[a.MentorID]
+		if !ok {
+			// The mentor may have left the training staff while still holding students
+			c = &MentorCaseload{Mentor: a.Mentor, Students: []*models.User{}}
+			byMentor[a.MentorID] = c
+			ret = append(ret, c)
+		}
+		c.Students = append(c.Students, a.Student)
+	}
+
+	for _, c := range ret {
+		c.Count = len(c.Students)
+		c.Overloaded = c.Count > maxStudents
+	}
+
+	sort.SliceStable(ret, func(i, j int) bool {
+		return ret[i].Count > ret[j].Count
+	})
+
+	return ret
+}
+
+// UnassignedStudents returns the students without an active mentor assignment
+func UnassignedStudents(students []*models.User, assignments []*models.MentorAssignment) []*models.User {
+	assigned := map[uint]bool{}
+	for _, a := range assignments {
+		if a.EndedAt == nil {
+			assigned[a.StudentID] = true
+		}
+	}
+
+	ret := []*models.User{}
+	for _, s := range students {
+		if !assigned[s.CID] {
+			ret = append(ret, s)
+		}
+	}
+
+	return ret
+}
+
+// LatestTrainingNote returns the most recent training note by session date, or nil if there are none
+func LatestTrainingNote(notes []*models.TrainingNote) *models.TrainingNote {
+	var latest *models.TrainingNote
+	for _, n := range notes {
+		if n.SessionDate == nil {
+			continue
+		}
+		if latest == nil || n.SessionDate.After(*latest.SessionDate) {
+			latest = n
+		}
+	}
+
+	return latest
+}
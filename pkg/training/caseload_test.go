@@ -0,0 +1,82 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package training
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/adh-partnership/api/pkg/database/models"
+)
+
+func TestBuildCaseload(t *testing.T) {
+	staff := []*models.User{{CID: 1}, {CID: 2}, {CID: 3}}
+	ended := date(2, 0, 0)
+	assignments := []*models.MentorAssignment{
+		{MentorID: 1, StudentID: 10, Student: &models.User{CID: 10}},
+		{MentorID: 1, StudentID: 11, Student: &models.User{CID: 11}},
+		{MentorID: 1, StudentID: 12, Student: &models.User{CID: 12}},
+		{MentorID: 2, StudentID: 13, Student: &models.User{CID: 13}},
+		{MentorID: 2, StudentID: 14, Student: &models.User{CID: 14}, EndedAt: &ended},
+		{MentorID: 4, Mentor: &models.User{CID: 4}, StudentID: 15, Student: &models.User{CID: 15}},
+	}
+
+	caseload := BuildCaseload(staff, assignments, 2)
+	assert.Len(t, caseload, 4)
+
+	assert.Equal(t, uint(1), caseload[0].Mentor.CID)
+	assert.Equal(t, 3, caseload[0].Count)
+	assert.True(t, caseload[0].Overloaded)
+
+	assert.Equal(t, uint(2), caseload[1].Mentor.CID)
+	assert.Equal(t, 1, caseload[1].Count)
+	assert.False(t, caseload[1].Overloaded)
+
+	assert.Equal(t, uint(4), caseload[2].Mentor.CID)
+	assert.Equal(t, 1, caseload[2].Count)
+
+	assert.Equal(t, uint(3), caseload[3].Mentor.CID)
+	assert.Equal(t, 0, caseload[3].Count)
+	assert.NotNil(t, caseload[3].Students)
+}
+
+func TestUnassignedStudents(t *testing.T) {
+	ended := date(2, 0, 0)
+	students := []*models.User{{CID: 10}, {CID: 11}, {CID: 12}}
+	assignments := []*models.MentorAssignment{
+		{MentorID: 1, StudentID: 10},
+		{MentorID: 1, StudentID: 11, EndedAt: &ended},
+	}
+
+	unassigned := UnassignedStudents(students, assignments)
+	assert.Len(t, unassigned, 2)
+	assert.Equal(t, uint(11), unassigned[0].CID)
+	assert.Equal(t, uint(12), unassigned[1].CID)
+}
+
+func TestLatestTrainingNote(t *testing.T) {
+	assert.Nil(t, LatestTrainingNote(nil))
+
+	notes := []*models.TrainingNote{
+		{ID: 1, SessionDate: ptr(date(3, 18, 0))},
+		{ID: 2},
+		{ID: 3, SessionDate: ptr(date(10, 18, 0))},
+		{ID: 4, SessionDate: ptr(date(5, 18, 0))},
+	}
+	assert.Equal(t, uint(3), LatestTrainingNote(notes).ID)
+}
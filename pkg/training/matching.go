@@ -0,0 +1,196 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package training
+
+import (
+	"sort"
+	"time"
+
+	"github.com/adh-partnership/api/pkg/database/models"
+	"github.com/adh-partnership/api/pkg/database/models/constants"
+)
+
+type Window struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Candidate is an instructor or mentor that can be matched against a training request
+type Candidate struct {
+	Instructor *models.User
+	// Certification name -> value
+	Certifications map[string]string
+	// Number of upcoming sessions the instructor is already scheduled for
+	Workload     int64
+	Availability []*models.TrainingAvailability
+}
+
+type Match struct {
+	Instructor    *models.User
+	Certification string
+	Workload      int64
+	Start         time.Time
+	End           time.Time
+}
+
+// ParseClock parses a HH:MM time of day and returns the offset from midnight
+func ParseClock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, err
+	}
+
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// ExpandAvailability returns the concrete windows of an availability entry that fall within from and to,
+// clipped to that range.
+func ExpandAvailability(a *models.TrainingAvailability, from, to time.Time) []Window {
+	var windows []Window
+	bounds := Window{Start: from, End: to}
+
+	if a.Type == constants.TrainingAvailabilityTypeOnce {
+		if a.Start == nil || a.End == nil {
+			return nil
+		}
+		if w, ok := Intersect(Window{Start: *a.Start, End: *a.End}, bounds); ok {
+			windows = append(windows, w)
+		}
+		return windows
+	}
+
+	start, err := ParseClock(a.StartTime)
+	if err != nil {
+		return nil
+	}
+	end, err := ParseClock(a.EndTime)
+	if err != nil {
+		return nil
+	}
+	if end <= start {
+		end += 24 * time.Hour
+	}
+
+	// Start a day early so windows that started the previous day and run past midnight are included
+	from = from.UTC()
+	day := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, -1)
+	for !day.After(to) {
+		if int(day.Weekday()) == a.Weekday {
+			if w, ok := Intersect(Window{Start: day.Add(start), End: day.Add(end)}, bounds); ok {
+				windows = append(windows, w)
+			}
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+
+	return windows
+}
+
+// Intersect returns the overlap of two windows, if any
+func Intersect(a, b Window) (Window, bool) {
+	w := Window{Start: a.Start, End: a.End}
+	if b.Start.After(w.Start) {
+		w.Start = b.Start
+	}
+	if b.End.Before(w.End) {
+		w.End = b.End
+	}
+
+	return w, w.End.After(w.Start)
+}
+
+// WithinSlots reports whether start to end falls entirely within one of a training request's slots
+func WithinSlots(slots []*models.TrainingRequestSlot, start, end time.Time) bool {
+	for _, slot := range slots {
+		if slot.Start != nil && slot.End != nil && !start.Before(*slot.Start) && !end.After(*slot.End) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CertificationRank orders certification values by how suitable they make an instructor, higher is better
+func CertificationRank(value string) int {
+	switch value {
+	case constants.CertificationCanTrain:
+		return 3
+	case constants.CertificationCertified:
+		return 2
+	case constants.CertificationSolo:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// FindMatches computes the overlaps between the request's slots and each candidate's availability that are at least
+// minLength long. Slot time that has already passed is ignored. Matches are ranked by the candidate's value for
+// certification, then by workload and then by start time. If certification is empty, candidates are ranked by
+// workload only.
+func FindMatches(request *models.TrainingRequest, candidates []*Candidate, certification string, minLength time.Duration, now time.Time) []*Match {
+	var matches []*Match
+
+	for _, candidate := range candidates {
+		if candidate.Instructor == nil || candidate.Instructor.CID == request.StudentID {
+			continue
+		}
+
+		value := ""
+		if certification != "" {
+			value = candidate.Certifications[certification]
+		}
+
+		for _, slot := range request.Slots {
+			if slot.Start == nil || slot.End == nil || !slot.End.After(now) {
+				continue
+			}
+			slotWindow := Window{Start: *slot.Start, End: *slot.End}
+			if slotWindow.Start.Before(now) {
+				slotWindow.Start = now
+			}
+
+			for _, a := range candidate.Availability {
+				for _, w := range ExpandAvailability(a, slotWindow.Start, slotWindow.End) {
+					if w.End.Sub(w.Start) < minLength {
+						continue
+					}
+					matches = append(matches, &Match{
+						Instructor:    candidate.Instructor,
+						Certification: value,
+						Workload:      candidate.Workload,
+						Start:         w.Start,
+						End:           w.End,
+					})
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		ri, rj := CertificationRank(matches[i].Certification), CertificationRank(matches[j].Certification)
+		if ri != rj {
+			return ri > rj
+		}
+		if matches[i].Workload != matches[j].Workload {
+			return matches[i].Workload < matches[j].Workload
+		}
+		return matches[i].Start.Before(matches[j].Start)
+	})
+
+	return matches
+}
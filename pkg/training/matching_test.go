@@ -0,0 +1,173 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package training
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/adh-partnership/api/pkg/database/models"
+	"github.com/adh-partnership/api/pkg/database/models/constants"
+)
+
+func date(day, hour, minute int) time.Time {
+	// 2024-01-07 is a Sunday
+	return time.Date(2024, time.January, day, hour, minute, 0, 0, time.UTC)
+}
+
+func TestExpandAvailability(t *testing.T) {
+	tests := []struct {
+		Name         string
+		Availability *models.TrainingAvailability
+		From         time.Time
+		To           time.Time
+		Expected     []Window
+	}{
+		{
+			Name: "Recurring weekly",
+			Availability: &models.TrainingAvailability{
+				Type:      constants.TrainingAvailabilityTypeRecurring,
+				Weekday:   1,
+				StartTime: "18:00",
+				EndTime:   "20:00",
+			},
+			From: date(7, 0, 0),
+			To:   date(21, 0, 0),
+			Expected: []Window{
+				{Start: date(8, 18, 0), End: date(8, 20, 0)},
+				{Start: date(15, 18, 0), End: date(15, 20, 0)},
+			},
+		},
+		{
+			Name: "Recurring across midnight is clipped",
+			Availability: &models.TrainingAvailability{
+				Type:      constants.TrainingAvailabilityTypeRecurring,
+				Weekday:   0,
+				StartTime: "22:00",
+				EndTime:   "02:00",
+			},
+			From: date(8, 0, 0),
+			To:   date(8, 12, 0),
+			Expected: []Window{
+				{Start: date(8, 0, 0), End: date(8, 2, 0)},
+			},
+		},
+		{
+			Name: "One-off",
+			Availability: &models.TrainingAvailability{
+				Type:  constants.TrainingAvailabilityTypeOnce,
+				Start: ptr(date(9, 17, 0)),
+				End:   ptr(date(9, 21, 0)),
+			},
+			From: date(9, 18, 0),
+			To:   date(10, 0, 0),
+			Expected: []Window{
+				{Start: date(9, 18, 0), End: date(9, 21, 0)},
+			},
+		},
+		{
+			Name: "One-off outside range",
+			Availability: &models.TrainingAvailability{
+				Type:  constants.TrainingAvailabilityTypeOnce,
+				Start: ptr(date(9, 17, 0)),
+				End:   ptr(date(9, 21, 0)),
+			},
+			From:     date(10, 0, 0),
+			To:       date(11, 0, 0),
+			Expected: nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			assert.Equal(t, test.Expected, ExpandAvailability(test.Availability, test.From, test.To))
+		})
+	}
+}
+
+func TestFindMatches(t *testing.T) {
+	mentor := &models.User{CID: 2}
+	instructor := &models.User{CID: 3}
+	availability := []*models.TrainingAvailability{
+		{
+			Type:  constants.TrainingAvailabilityTypeOnce,
+			Start: ptr(date(9, 18, 0)),
+			End:   ptr(date(9, 20, 0)),
+		},
+	}
+
+	request := &models.TrainingRequest{
+		StudentID: 1,
+		Slots: []*models.TrainingRequestSlot{
+			{Start: ptr(date(9, 19, 0)), End: ptr(date(9, 23, 0))},
+		},
+	}
+
+	candidates := []*Candidate{
+		{
+			Instructor:     mentor,
+			Certifications: map[string]string{"approach": constants.CertificationCertified},
+			Availability:   availability,
+		},
+		{
+			Instructor:     instructor,
+			Certifications: map[string]string{"approach": constants.CertificationCanTrain},
+			Workload:       4,
+			Availability:   availability,
+		},
+		{
+			Instructor:   &models.User{CID: 1},
+			Availability: availability,
+		},
+	}
+
+	matches := FindMatches(request, candidates, "approach", time.Hour, date(1, 0, 0))
+	assert.Len(t, matches, 2)
+	assert.Equal(t, instructor, matches[0].Instructor)
+	assert.Equal(t, mentor, matches[1].Instructor)
+	assert.Equal(t, date(9, 19, 0), matches[0].Start)
+	assert.Equal(t, date(9, 20, 0), matches[0].End)
+
+	// Without a certification mapping, the least busy instructor comes first
+	matches = FindMatches(request, candidates, "", time.Hour, date(1, 0, 0))
+	assert.Equal(t, mentor, matches[0].Instructor)
+
+	// Overlap shorter than the minimum session length is not a match
+	assert.Empty(t, FindMatches(request, candidates, "approach", 2*time.Hour, date(1, 0, 0)))
+}
+
+func ptr(t time.Time) *time.Time {
+	return &t
+}
+
+func TestWithinSlots(t *testing.T) {
+	slot := func(start, end time.Time) *models.TrainingRequestSlot {
+		return &models.TrainingRequestSlot{Start: &start, End: &end}
+	}
+	slots := []*models.TrainingRequestSlot{
+		slot(date(8, 18, 0), date(8, 20, 0)),
+		slot(date(9, 12, 0), date(9, 16, 0)),
+	}
+
+	assert.True(t, WithinSlots(slots, date(8, 18, 0), date(8, 20, 0)))
+	assert.True(t, WithinSlots(slots, date(9, 13, 0), date(9, 14, 30)))
+	assert.False(t, WithinSlots(slots, date(8, 19, 0), date(8, 21, 0)))
+	assert.False(t, WithinSlots(slots, date(8, 19, 0), date(9, 13, 0)))
+	assert.False(t, WithinSlots(nil, date(8, 18, 0), date(8, 20, 0)))
+}
@@ -0,0 +1,85 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package training
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/adh-partnership/api/pkg/database/models"
+	"github.com/adh-partnership/api/pkg/database/models/constants"
+)
+
+// ScoreEvaluation validates an OTS evaluation against its rubric and returns the scores in rubric order, with the
+// category details copied over so the evaluation still reads correctly if the rubric changes. Every category must be
+// scored, unsatisfactory marks need a comment and a pass is not allowed with an unsatisfactory critical category.
+func ScoreEvaluation(rubric *models.OTSRubric, result string, scores []*models.OTSEvaluationScore) ([]*models.OTSEvaluationScore, error) {
+	if !models.IsValidOTSResult(result) {
+		return nil, fmt.Errorf("invalid result %q", result)
+	}
+
+	categories := map[uint]*models.OTSRubricCategory{}
+	for _, category := range rubric.Categories {
+		categories[category.ID] = category
+	}
+
+	scored := map[uint]*models.OTSEvaluationScore{}
+	for _, score := range scores {
+		category, ok := categories[score.CategoryID]
+		if !ok {
+			return nil, fmt.Errorf("category %d is not part of the rubric", score.CategoryID)
+		}
+		if _, ok := scored[category.ID]; ok {
+			return nil, fmt.Errorf("category %q is scored more than once", category.Name)
+		}
+		if !models.IsValidOTSScore(score.Score) {
+			return nil, fmt.Errorf("invalid score %q for category %q", score.Score, category.Name)
+		}
+		if score.Score == constants.OTSScoreUnsatisfactory && strings.TrimSpace(score.Comments) == "" {
+			return nil, fmt.Errorf("category %q is unsatisfactory and requires a comment", category.Name)
+		}
+		if score.Score == constants.OTSScoreUnsatisfactory && category.Critical && result == constants.OTSResultPass {
+			return nil, fmt.Errorf("cannot pass with critical category %q unsatisfactory", category.Name)
+		}
+
+		scored[category.ID] = &models.OTSEvaluationScore{
+			CategoryID:   category.ID,
+			CategoryName: category.Name,
+			Critical:     category.Critical,
+			Score:        score.Score,
+			Comments:     score.Comments,
+		}
+	}
+
+	sorted := make([]*models.OTSRubricCategory, len(rubric.Categories))
+	copy(sorted, rubric.Categories)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Order < sorted[j].Order
+	})
+
+	ret := []*models.OTSEvaluationScore{}
+	for _, category := range sorted {
+		score, ok := scored[category.ID]
+		if !ok {
+			return nil, fmt.Errorf("category %q has not been scored", category.Name)
+		}
+		ret = append(ret, score)
+	}
+
+	return ret, nil
+}
@@ -0,0 +1,101 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package training
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/adh-partnership/api/pkg/database/models"
+	"github.com/adh-partnership/api/pkg/database/models/constants"
+)
+
+func TestScoreEvaluation(t *testing.T) {
+	rubric := &models.OTSRubric{
+		Categories: []*models.OTSRubricCategory{
+			{ID: 1, Order: 2, Name: "Phraseology"},
+			{ID: 2, Order: 1, Name: "Separation", Critical: true},
+		},
+	}
+
+	tests := []struct {
+		Name   string
+		Result string
+		Scores []*models.OTSEvaluationScore
+		Err    string
+	}{
+		{
+			Name:   "Pass",
+			Result: constants.OTSResultPass,
+			Scores: []*models.OTSEvaluationScore{
+				{CategoryID: 1, Score: constants.OTSScoreNeedsImprovement},
+				{CategoryID: 2, Score: constants.OTSScoreSatisfactory},
+			},
+		},
+		{
+			Name:   "Invalid result",
+			Result: "maybe",
+			Err:    `invalid result "maybe"`,
+		},
+		{
+			Name:   "Missing category",
+			Result: constants.OTSResultFail,
+			Scores: []*models.OTSEvaluationScore{{CategoryID: 1, Score: constants.OTSScoreSatisfactory}},
+			Err:    `category "Separation" has not been scored`,
+		},
+		{
+			Name:   "Unknown category",
+			Result: constants.OTSResultFail,
+			Scores: []*models.OTSEvaluationScore{{CategoryID: 3, Score: constants.OTSScoreSatisfactory}},
+			Err:    "category 3 is not part of the rubric",
+		},
+		{
+			Name:   "Unsatisfactory without comment",
+			Result: constants.OTSResultFail,
+			Scores: []*models.OTSEvaluationScore{
+				{CategoryID: 1, Score: constants.OTSScoreUnsatisfactory},
+				{CategoryID: 2, Score: constants.OTSScoreSatisfactory},
+			},
+			Err: `category "Phraseology" is unsatisfactory and requires a comment`,
+		},
+		{
+			Name:   "Pass with unsatisfactory critical category",
+			Result: constants.OTSResultPass,
+			Scores: []*models.OTSEvaluationScore{
+				{CategoryID: 1, Score: constants.OTSScoreSatisfactory},
+				{CategoryID: 2, Score: constants.OTSScoreUnsatisfactory, Comments: "Loss of separation"},
+			},
+			Err: `cannot pass with critical category "Separation" unsatisfactory`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			scores, err := ScoreEvaluation(rubric, test.Result, test.Scores)
+			if test.Err != "" {
+				assert.EqualError(t, err, test.Err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Len(t, scores, 2)
+			assert.Equal(t, "Separation", scores[0].CategoryName)
+			assert.True(t, scores[0].Critical)
+			assert.Equal(t, "Phraseology", scores[1].CategoryName)
+		})
+	}
+}
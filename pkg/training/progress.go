@@ -0,0 +1,127 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package training
+
+import (
+	"sort"
+	"time"
+
+	"github.com/adh-partnership/api/pkg/database/models"
+	"github.com/adh-partnership/api/pkg/database/models/constants"
+)
+
+type ItemProgress struct {
+	Item       *models.SyllabusItem `json:"item"`
+	Status     string               `json:"status"`
+	Comments   string               `json:"comments"`
+	LastMarked *time.Time           `json:"last_marked"`
+}
+
+type CertificationProgress struct {
+	Certification string          `json:"certification"`
+	DisplayName   string          `json:"display_name"`
+	Value         string          `json:"value"`
+	Satisfactory  int             `json:"satisfactory"`
+	Total         int             `json:"total"`
+	Items         []*ItemProgress `json:"items"`
+}
+
+// BuildProgress returns the syllabus progress for every certification, in certification order. certs holds the
+// student's certification values by name, marks are every progress mark recorded for the student. The latest mark
+// for an item is its current status.
+func BuildProgress(
+	certifications []models.Certification,
+	certs map[string]string,
+	items []*models.SyllabusItem,
+	marks []*models.TrainingNoteProgress,
+) []*CertificationProgress {
+	latest := map[uint]*models.TrainingNoteProgress{}
+	for _, mark := range marks {
+		if l, ok := latest[mark.SyllabusItemID]; !ok || mark.CreatedAt.After(l.CreatedAt) {
+			latest[mark.SyllabusItemID] = mark
+		}
+	}
+
+	sorted := make([]models.Certification, len(certifications))
+	copy(sorted, certifications)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Order < sorted[j].Order
+	})
+
+	sortedItems := make([]*models.SyllabusItem, len(items))
+	copy(sortedItems, items)
+	sort.SliceStable(sortedItems, func(i, j int) bool {
+		return sortedItems[i].Order < sortedItems[j].Order
+	})
+
+	var ret []*CertificationProgress
+	for _, cert := range sorted {
+		value := certs[cert.Name]
+		if value == "" {
+			value = constants.CertificationNone
+		}
+
+		p := &CertificationProgress{
+			Certification: cert.Name,
+			DisplayName:   cert.DisplayName,
+			Value:         value,
+			Items:         []*ItemProgress{},
+		}
+
+		for _, item := range sortedItems {
+			if item.Certification != cert.Name {
+				continue
+			}
+
+			ip := &ItemProgress{
+				Item:   item,
+				Status: constants.SyllabusProgressNone,
+			}
+			if mark, ok := latest[item.ID]; ok {
+				createdAt := mark.CreatedAt
+				ip.Status = mark.Status
+				ip.Comments = mark.Comments
+				ip.LastMarked = &createdAt
+			}
+			if ip.Status == constants.SyllabusProgressSatisfactory {
+				p.Satisfactory++
+			}
+			p.Total++
+			p.Items = append(p.Items, ip)
+		}
+
+		ret = append(ret, p)
+	}
+
+	return ret
+}
+
+// NextCertification returns the first certification, in order, the student does not yet hold
+func NextCertification(progress []*CertificationProgress) *CertificationProgress {
+	for _, p := range progress {
+		if p.Value != constants.CertificationCertified && p.Value != constants.CertificationCanTrain {
+			return p
+		}
+	}
+
+	return nil
+}
+
+// IsStalled returns true if the student has not had a session within after of now
+func IsStalled(lastSession *time.Time, now time.Time, after time.Duration) bool {
+	return lastSession == nil || now.Sub(*lastSession) > after
+}
@@ -0,0 +1,92 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package training
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/adh-partnership/api/pkg/database/models"
+	"github.com/adh-partnership/api/pkg/database/models/constants"
+)
+
+func TestBuildProgress(t *testing.T) {
+	certifications := []models.Certification{
+		{Name: "tower", DisplayName: "Tower", Order: 2},
+		{Name: "ground", DisplayName: "Ground", Order: 1},
+	}
+	items := []*models.SyllabusItem{
+		{ID: 1, Certification: "ground", Order: 2, Title: "Taxi clearances"},
+		{ID: 2, Certification: "ground", Order: 1, Title: "IFR clearances"},
+		{ID: 3, Certification: "tower", Order: 1, Title: "Runway separation"},
+	}
+	marks := []*models.TrainingNoteProgress{
+		{SyllabusItemID: 2, Status: constants.SyllabusProgressProgressing, CreatedAt: date(8, 18, 0)},
+		{SyllabusItemID: 2, Status: constants.SyllabusProgressSatisfactory, CreatedAt: date(15, 18, 0)},
+		{SyllabusItemID: 1, Status: constants.SyllabusProgressIntroduced, CreatedAt: date(15, 18, 0)},
+	}
+
+	progress := BuildProgress(certifications, map[string]string{"ground": constants.CertificationTraining}, items, marks)
+	assert.Len(t, progress, 2)
+
+	assert.Equal(t, "ground", progress[0].Certification)
+	assert.Equal(t, constants.CertificationTraining, progress[0].Value)
+	assert.Equal(t, 1, progress[0].Satisfactory)
+	assert.Equal(t, 2, progress[0].Total)
+	assert.Equal(t, "IFR clearances", progress[0].Items[0].Item.Title)
+	assert.Equal(t, constants.SyllabusProgressSatisfactory, progress[0].Items[0].Status)
+	assert.Equal(t, ptr(date(15, 18, 0)), progress[0].Items[0].LastMarked)
+	assert.Equal(t, constants.SyllabusProgressIntroduced, progress[0].Items[1].Status)
+
+	assert.Equal(t, "tower", progress[1].Certification)
+	assert.Equal(t, constants.CertificationNone, progress[1].Value)
+	assert.Equal(t, constants.SyllabusProgressNone, progress[1].Items[0].Status)
+	assert.Nil(t, progress[1].Items[0].LastMarked)
+}
+
+func TestNextCertification(t *testing.T) {
+	progress := []*CertificationProgress{
+		{Certification: "ground", Value: constants.CertificationCertified},
+		{Certification: "tower", Value: constants.CertificationSolo},
+		{Certification: "approach", Value: constants.CertificationNone},
+	}
+	assert.Equal(t, "tower", NextCertification(progress).Certification)
+
+	progress[1].Value = constants.CertificationCanTrain
+	progress[2].Value = constants.CertificationCertified
+	assert.Nil(t, NextCertification(progress))
+}
+
+func TestIsStalled(t *testing.T) {
+	tests := []struct {
+		Name        string
+		LastSession *time.Time
+		Expected    bool
+	}{
+		{Name: "Never trained", LastSession: nil, Expected: true},
+		{Name: "Recent session", LastSession: ptr(date(24, 0, 0)), Expected: false},
+		{Name: "Old session", LastSession: ptr(date(1, 0, 0)), Expected: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			assert.Equal(t, test.Expected, IsStalled(test.LastSession, date(28, 0, 0), 7*24*time.Hour))
+		})
+	}
+}
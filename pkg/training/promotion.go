@@ -0,0 +1,90 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package training
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/adh-partnership/api/pkg/database/models"
+	"github.com/adh-partnership/api/pkg/database/models/constants"
+	"github.com/adh-partnership/api/pkg/network/vatusa"
+)
+
+const (
+	// Facilities can promote up to and including C1
+	minPromotionRating = 2
+	maxPromotionRating = 5
+)
+
+// CanTransitionPromotion returns true if a promotion request can move from one status to another. Approving an
+// approved request retries the VATUSA submission.
+func CanTransitionPromotion(from, to string) bool {
+	switch from {
+	case constants.PromotionStatusRecommended:
+		return to == constants.PromotionStatusApproved || to == constants.PromotionStatusRejected
+	case constants.PromotionStatusApproved:
+		return to == constants.PromotionStatusApproved || to == constants.PromotionStatusRejected
+	default:
+		return false
+	}
+}
+
+// ValidatePromotionRating checks the promotion is to the next rating and within what a facility can grant
+func ValidatePromotionRating(current, to int) error {
+	if to < minPromotionRating || to > maxPromotionRating {
+		return fmt.Errorf("facilities cannot promote to rating %d", to)
+	}
+	if to != current+1 {
+		return fmt.Errorf("promotion must be to the next rating")
+	}
+
+	return nil
+}
+
+// SubmitPromotion sends an approved promotion to VATUSA. The recommending instructor is the examiner and the exam
+// date is that of the latest linked OTS evaluation, or now. On success the request is marked as submitted, otherwise
+// it stays approved with the error recorded so it can be retried.
+func SubmitPromotion(client vatusa.Client, p *models.PromotionRequest, now time.Time) error {
+	examDate := now
+	var latest *time.Time
+	for _, evaluation := range p.OTSEvaluations {
+		if latest == nil || evaluation.CreatedAt.After(*latest) {
+			createdAt := evaluation.CreatedAt
+			latest = &createdAt
+		}
+	}
+	if latest != nil {
+		examDate = *latest
+	}
+
+	status, err := client.PromoteController(fmt.Sprint(p.StudentID), p.ToRatingID, p.RecommendedByID, p.Position, examDate)
+	if err == nil && status > 299 {
+		err = fmt.Errorf("VATUSA returned status code %d", status)
+	}
+	if err != nil {
+		p.Status = constants.PromotionStatusApproved
+		p.SubmissionError = err.Error()
+		return err
+	}
+
+	p.Status = constants.PromotionStatusSubmitted
+	p.SubmissionError = ""
+	p.SubmittedAt = &now
+
+	return nil
+}
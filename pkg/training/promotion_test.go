@@ -0,0 +1,94 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package training
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/adh-partnership/api/pkg/database/models"
+	"github.com/adh-partnership/api/pkg/database/models/constants"
+)
+
+type mockVATUSA struct {
+	status   int
+	err      error
+	cid      string
+	rating   int
+	examiner uint
+	position string
+	examDate time.Time
+}
+
+func (m *mockVATUSA) PromoteController(cid string, rating int, examiner uint, position string, examDate time.Time) (int, error) {
+	m.cid, m.rating, m.examiner, m.position, m.examDate = cid, rating, examiner, position, examDate
+	return m.status, m.err
+}
+
+func TestCanTransitionPromotion(t *testing.T) {
+	assert.True(t, CanTransitionPromotion(constants.PromotionStatusRecommended, constants.PromotionStatusApproved))
+	assert.True(t, CanTransitionPromotion(constants.PromotionStatusRecommended, constants.PromotionStatusRejected))
+	assert.True(t, CanTransitionPromotion(constants.PromotionStatusApproved, constants.PromotionStatusApproved))
+	assert.False(t, CanTransitionPromotion(constants.PromotionStatusRecommended, constants.PromotionStatusSubmitted))
+	assert.False(t, CanTransitionPromotion(constants.PromotionStatusSubmitted, constants.PromotionStatusRejected))
+	assert.False(t, CanTransitionPromotion(constants.PromotionStatusRejected, constants.PromotionStatusApproved))
+}
+
+func TestValidatePromotionRating(t *testing.T) {
+	assert.NoError(t, ValidatePromotionRating(3, 4))
+	assert.Error(t, ValidatePromotionRating(3, 5))
+	assert.Error(t, ValidatePromotionRating(5, 6))
+	assert.Error(t, ValidatePromotionRating(0, 1))
+}
+
+func TestSubmitPromotion(t *testing.T) {
+	p := &models.PromotionRequest{
+		StudentID:       1000,
+		ToRatingID:      4,
+		RecommendedByID: 2000,
+		Position:        "DEN_APP",
+		Status:          constants.PromotionStatusApproved,
+		OTSEvaluations: []*models.OTSEvaluation{
+			{CreatedAt: date(8, 18, 0)},
+			{CreatedAt: date(15, 18, 0)},
+		},
+	}
+
+	client := &mockVATUSA{status: 500}
+	assert.Error(t, SubmitPromotion(client, p, date(20, 0, 0)))
+	assert.Equal(t, constants.PromotionStatusApproved, p.Status)
+	assert.Equal(t, "VATUSA returned status code 500", p.SubmissionError)
+	assert.Nil(t, p.SubmittedAt)
+
+	client = &mockVATUSA{err: fmt.Errorf("connection refused")}
+	assert.Error(t, SubmitPromotion(client, p, date(20, 0, 0)))
+	assert.Equal(t, "connection refused", p.SubmissionError)
+
+	client = &mockVATUSA{status: 200}
+	assert.NoError(t, SubmitPromotion(client, p, date(20, 0, 0)))
+	assert.Equal(t, constants.PromotionStatusSubmitted, p.Status)
+	assert.Empty(t, p.SubmissionError)
+	assert.Equal(t, ptr(date(20, 0, 0)), p.SubmittedAt)
+	assert.Equal(t, "1000", client.cid)
+	assert.Equal(t, 4, client.rating)
+	assert.Equal(t, uint(2000), client.examiner)
+	assert.Equal(t, "DEN_APP", client.position)
+	assert.Equal(t, date(15, 18, 0), client.examDate)
+}
@@ -0,0 +1,363 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package training
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adh-partnership/api/pkg/database/models"
+	"github.com/adh-partnership/api/pkg/database/models/constants"
+)
+
+const noShow = "no-show"
+
+type InstructorWorkloadRow struct {
+	Month          string  `json:"month"`
+	InstructorID   uint    `json:"instructor_id"`
+	InstructorName string  `json:"instructor_name"`
+	Sessions       int     `json:"sessions"`
+	NoShows        int     `json:"no_shows"`
+	Hours          float64 `json:"hours"`
+}
+
+type StudentThroughputRow struct {
+	StudentID    uint       `json:"student_id"`
+	StudentName  string     `json:"student_name"`
+	Sessions     int        `json:"sessions"`
+	NoShows      int        `json:"no_shows"`
+	Hours        float64    `json:"hours"`
+	FirstSession *time.Time `json:"first_session"`
+	LastSession  *time.Time `json:"last_session"`
+}
+
+type NoShowRow struct {
+	Month    string  `json:"month"`
+	Sessions int     `json:"sessions"`
+	NoShows  int     `json:"no_shows"`
+	Rate     float64 `json:"rate"`
+}
+
+type TimeToCertificationRow struct {
+	StudentID     uint      `json:"student_id"`
+	StudentName   string    `json:"student_name"`
+	Certification string    `json:"certification"`
+	JoinDate      time.Time `json:"join_date"`
+	CertifiedDate time.Time `json:"certified_date"`
+	Days          int       `json:"days"`
+}
+
+type BacklogRow struct {
+	Position    string  `json:"position"`
+	Open        int     `json:"open"`
+	Accepted    int     `json:"accepted"`
+	OldestDays  int     `json:"oldest_days"`
+	AverageDays float64 `json:"average_days"`
+}
+
+// ParseSessionDuration parses a training note duration, either HH:MM, HH:MM:SS or a number of minutes
+func ParseSessionDuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+
+	parts := strings.Split(s, ":")
+	if len(parts) > 3 {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	if len(parts) == 1 {
+		minutes, err := strconv.Atoi(parts[0])
+		if err != nil || minutes < 0 {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		return time.Duration(minutes) * time.Minute, nil
+	}
+
+	units := []time.Duration{time.Hour, time.Minute, time.Second}
+	var d time.Duration
+	for i, part := range parts {
+		v, err := strconv.Atoi(part)
+		if err != nil || v < 0 {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		d += time.Duration(v) * units[i]
+	}
+
+	return d, nil
+}
+
+// InstructorWorkload totals the sessions and hours of each instructor per month
+func InstructorWorkload(notes []*models.TrainingNote) []*InstructorWorkloadRow {
+	rows := map[string]*InstructorWorkloadRow{}
+	for _, note := range notes {
+		if note.SessionDate == nil {
+			continue
+		}
+		month := note.SessionDate.UTC().Format("2006-01")
+		key := fmt.Sprintf("%s/%d", month, note.InstructorID)
+		row, ok := rows[key]
+		if !ok {
+			row = &InstructorWorkloadRow{
+				Month:          month,
+				InstructorID:   note.InstructorID,
+				InstructorName: userName(note.Instructor),
+			}
+			rows[key] = row
+		}
+
+		if note.Type == noShow {
+			row.NoShows++
+			continue
+		}
+		row.Sessions++
+		row.Hours += sessionHours(note)
+	}
+
+	ret := []*InstructorWorkloadRow{}
+	for _, row := range rows {
+		row.Hours = round(row.Hours)
+		ret = append(ret, row)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Month != ret[j].Month {
+			return ret[i].Month < ret[j].Month
+		}
+		return ret[i].InstructorID < ret[j].InstructorID
+	})
+
+	return ret
+}
+
+// StudentThroughput totals the sessions and hours of each student
+func StudentThroughput(notes []*models.TrainingNote) []*StudentThroughputRow {
+	rows := map[uint]*StudentThroughputRow{}
+	for _, note := range notes {
+		if note.SessionDate == nil {
+			continue
+		}
+		row, ok := rows[note.ControllerID]
+		if !ok {
+			row = &StudentThroughputRow{
+				StudentID:   note.ControllerID,
+				StudentName: userName(note.Controller),
+			}
+			rows[note.ControllerID] = row
+		}
+
+		if note.Type == noShow {
+			row.NoShows++
+			continue
+		}
+		row.Sessions++
+		row.Hours += sessionHours(note)
+		if row.FirstSession == nil || note.SessionDate.Before(*row.FirstSession) {
+			row.FirstSession = note.SessionDate
+		}
+		if row.LastSession == nil || note.SessionDate.After(*row.LastSession) {
+			row.LastSession = note.SessionDate
+		}
+	}
+
+	ret := []*StudentThroughputRow{}
+	for _, row := range rows {
+		row.Hours = round(row.Hours)
+		ret = append(ret, row)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Sessions != ret[j].Sessions {
+			return ret[i].Sessions > ret[j].Sessions
+		}
+		return ret[i].StudentID < ret[j].StudentID
+	})
+
+	return ret
+}
+
+// NoShowRates returns the share of booked sessions per month the student did not show up to
+func NoShowRates(notes []*models.TrainingNote) []*NoShowRow {
+	rows := map[string]*NoShowRow{}
+	for _, note := range notes {
+		if note.SessionDate == nil {
+			continue
+		}
+		month := note.SessionDate.UTC().Format("2006-01")
+		row, ok := rows[month]
+		if !ok {
+			row = &NoShowRow{Month: month}
+			rows[month] = row
+		}
+		row.Sessions++
+		if note.Type == noShow {
+			row.NoShows++
+		}
+	}
+
+	ret := []*NoShowRow{}
+	for _, row := range rows {
+		row.Rate = round(float64(row.NoShows) / float64(row.Sessions))
+		ret = append(ret, row)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Month < ret[j].Month
+	})
+
+	return ret
+}
+
+// TimeToCertification returns how long after joining the roster each user was certified. certified holds the date
+// each certification was granted, by CID and certification name.
+func TimeToCertification(users []*models.User, certified map[uint]map[string]time.Time) []*TimeToCertificationRow {
+	ret := []*TimeToCertificationRow{}
+	for _, user := range users {
+		if user.RosterJoinDate == nil {
+			continue
+		}
+		for cert, date := range certified[user.CID] {
+			if date.Before(*user.RosterJoinDate) {
+				continue
+			}
+			ret = append(ret, &TimeToCertificationRow{
+				StudentID:     user.CID,
+				StudentName:   userName(user),
+				Certification: cert,
+				JoinDate:      *user.RosterJoinDate,
+				CertifiedDate: date,
+				Days:          int(date.Sub(*user.RosterJoinDate).Hours() / 24),
+			})
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if !ret[i].CertifiedDate.Equal(ret[j].CertifiedDate) {
+			return ret[i].CertifiedDate.Before(ret[j].CertifiedDate)
+		}
+		return ret[i].Certification < ret[j].Certification
+	})
+
+	return ret
+}
+
+// RequestBacklog summarizes the open and accepted training requests per position as of now
+func RequestBacklog(requests []*models.TrainingRequest, now time.Time) []*BacklogRow {
+	rows := map[string]*BacklogRow{}
+	waiting := map[string]float64{}
+	for _, request := range requests {
+		if request.Status != constants.TrainingSessionStatusOpen && request.Status != constants.TrainingSessionStatusAccepted {
+			continue
+		}
+		row, ok := rows[request.Position]
+		if !ok {
+			row = &BacklogRow{Position: request.Position}
+			rows[request.Position] = row
+		}
+		if request.Status == constants.TrainingSessionStatusAccepted {
+			row.Accepted++
+			continue
+		}
+
+		row.Open++
+		if request.CreatedAt != nil {
+			days := now.Sub(*request.CreatedAt).Hours() / 24
+			waiting[request.Position] += days
+			if int(days) > row.OldestDays {
+				row.OldestDays = int(days)
+			}
+		}
+	}
+
+	ret := []*BacklogRow{}
+	for position, row := range rows {
+		if row.Open > 0 {
+			row.AverageDays = round(waiting[position] / float64(row.Open))
+		}
+		ret = append(ret, row)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Position < ret[j].Position
+	})
+
+	return ret
+}
+
+func (r *InstructorWorkloadRow) CSVRecord() []string {
+	return []string{r.Month, fmt.Sprint(r.InstructorID), r.InstructorName, fmt.Sprint(r.Sessions), fmt.Sprint(r.NoShows), formatFloat(r.Hours)}
+}
+
+func (r *StudentThroughputRow) CSVRecord() []string {
+	return []string{
+		fmt.Sprint(r.StudentID), r.StudentName, fmt.Sprint(r.Sessions), fmt.Sprint(r.NoShows), formatFloat(r.Hours),
+		formatDate(r.FirstSession), formatDate(r.LastSession),
+	}
+}
+
+func (r *NoShowRow) CSVRecord() []string {
+	return []string{r.Month, fmt.Sprint(r.Sessions), fmt.Sprint(r.NoShows), formatFloat(r.Rate)}
+}
+
+func (r *TimeToCertificationRow) CSVRecord() []string {
+	return []string{
+		fmt.Sprint(r.StudentID), r.StudentName, r.Certification, formatDate(&r.JoinDate), formatDate(&r.CertifiedDate), fmt.Sprint(r.Days),
+	}
+}
+
+func (r *BacklogRow) CSVRecord() []string {
+	return []string{r.Position, fmt.Sprint(r.Open), fmt.Sprint(r.Accepted), fmt.Sprint(r.OldestDays), formatFloat(r.AverageDays)}
+}
+
+var (
+	InstructorWorkloadHeader  = []string{"month", "instructor_id", "instructor_name", "sessions", "no_shows", "hours"}
+	StudentThroughputHeader   = []string{"student_id", "student_name", "sessions", "no_shows", "hours", "first_session", "last_session"}
+	NoShowHeader              = []string{"month", "sessions", "no_shows", "rate"}
+	TimeToCertificationHeader = []string{"student_id", "student_name", "certification", "join_date", "certified_date", "days"}
+	BacklogHeader             = []string{"position", "open", "accepted", "oldest_days", "average_days"}
+)
+
+func sessionHours(note *models.TrainingNote) float64 {
+	d, err := ParseSessionDuration(note.Duration)
+	if err != nil {
+		return 0
+	}
+
+	return d.Hours()
+}
+
+func userName(user *models.User) string {
+	if user == nil {
+		return ""
+	}
+
+	return user.FirstName + " " + user.LastName
+}
+
+func round(f float64) float64 {
+	return float64(int64(f*100+0.5)) / 100
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', 2, 64)
+}
+
+func formatDate(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+
+	return t.UTC().Format("2006-01-02")
+}
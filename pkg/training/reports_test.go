@@ -0,0 +1,131 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package training
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/adh-partnership/api/pkg/database/models"
+	"github.com/adh-partnership/api/pkg/database/models/constants"
+)
+
+func TestParseSessionDuration(t *testing.T) {
+	tests := []struct {
+		Input    string
+		Expected time.Duration
+		Err      bool
+	}{
+		{Input: "01:30", Expected: 90 * time.Minute},
+		{Input: "2:00:30", Expected: 2*time.Hour + 30*time.Second},
+		{Input: "45", Expected: 45 * time.Minute},
+		{Input: "", Expected: 0},
+		{Input: "1h", Err: true},
+		{Input: "1:-5", Err: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Input, func(t *testing.T) {
+			d, err := ParseSessionDuration(test.Input)
+			if test.Err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.Expected, d)
+		})
+	}
+}
+
+func reportNotes() []*models.TrainingNote {
+	instructor := &models.User{CID: 10, FirstName: "Ima", LastName: "Instructor"}
+	student := &models.User{CID: 20, FirstName: "Stu", LastName: "Dent"}
+
+	return []*models.TrainingNote{
+		{ControllerID: 20, Controller: student, InstructorID: 10, Instructor: instructor, Type: "live", Duration: "01:30", SessionDate: ptr(date(8, 18, 0))},
+		{ControllerID: 20, Controller: student, InstructorID: 10, Instructor: instructor, Type: "no-show", Duration: "00:00", SessionDate: ptr(date(15, 18, 0))},
+		{
+			ControllerID: 20, Controller: student, InstructorID: 10, Instructor: instructor,
+			Type: "simulation", Duration: "02:00", SessionDate: ptr(date(20, 18, 0)),
+		},
+		{
+			ControllerID: 21, InstructorID: 10, Instructor: instructor,
+			Type: "live", Duration: "01:00", SessionDate: ptr(time.Date(2024, time.February, 2, 0, 0, 0, 0, time.UTC)),
+		},
+	}
+}
+
+func TestInstructorWorkload(t *testing.T) {
+	rows := InstructorWorkload(reportNotes())
+	assert.Len(t, rows, 2)
+	assert.Equal(t, &InstructorWorkloadRow{
+		Month: "2024-01", InstructorID: 10, InstructorName: "Ima Instructor", Sessions: 2, NoShows: 1, Hours: 3.5,
+	}, rows[0])
+	assert.Equal(t, "2024-02", rows[1].Month)
+	assert.Equal(t, []string{"2024-01", "10", "Ima Instructor", "2", "1", "3.50"}, rows[0].CSVRecord())
+}
+
+func TestStudentThroughput(t *testing.T) {
+	rows := StudentThroughput(reportNotes())
+	assert.Len(t, rows, 2)
+	assert.Equal(t, uint(20), rows[0].StudentID)
+	assert.Equal(t, 2, rows[0].Sessions)
+	assert.Equal(t, 1, rows[0].NoShows)
+	assert.Equal(t, 3.5, rows[0].Hours)
+	assert.Equal(t, ptr(date(8, 18, 0)), rows[0].FirstSession)
+	assert.Equal(t, ptr(date(20, 18, 0)), rows[0].LastSession)
+}
+
+func TestNoShowRates(t *testing.T) {
+	rows := NoShowRates(reportNotes())
+	assert.Equal(t, []*NoShowRow{
+		{Month: "2024-01", Sessions: 3, NoShows: 1, Rate: 0.33},
+		{Month: "2024-02", Sessions: 1, NoShows: 0, Rate: 0},
+	}, rows)
+}
+
+func TestTimeToCertification(t *testing.T) {
+	users := []*models.User{
+		{CID: 1, FirstName: "A", LastName: "B", RosterJoinDate: ptr(date(1, 0, 0))},
+		{CID: 2, FirstName: "C", LastName: "D"},
+	}
+	certified := map[uint]map[string]time.Time{
+		1: {"ground": date(11, 0, 0)},
+		2: {"ground": date(11, 0, 0)},
+	}
+
+	rows := TimeToCertification(users, certified)
+	assert.Len(t, rows, 1)
+	assert.Equal(t, 10, rows[0].Days)
+	assert.Equal(t, "ground", rows[0].Certification)
+}
+
+func TestRequestBacklog(t *testing.T) {
+	requests := []*models.TrainingRequest{
+		{Position: "DEN_TWR", Status: constants.TrainingSessionStatusOpen, CreatedAt: ptr(date(1, 0, 0))},
+		{Position: "DEN_TWR", Status: constants.TrainingSessionStatusOpen, CreatedAt: ptr(date(5, 0, 0))},
+		{Position: "DEN_TWR", Status: constants.TrainingSessionStatusAccepted, CreatedAt: ptr(date(5, 0, 0))},
+		{Position: "DEN_GND", Status: constants.TrainingSessionStatusCompleted, CreatedAt: ptr(date(5, 0, 0))},
+	}
+
+	rows := RequestBacklog(requests, date(11, 0, 0))
+	assert.Equal(t, []*BacklogRow{
+		{Position: "DEN_TWR", Open: 2, Accepted: 1, OldestDays: 10, AverageDays: 8},
+	}, rows)
+}
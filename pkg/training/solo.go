@@ -0,0 +1,87 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package training
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/adh-partnership/api/pkg/database/models"
+	"github.com/adh-partnership/api/pkg/database/models/constants"
+	"github.com/adh-partnership/api/pkg/discord"
+)
+
+// SoloWebhook is the Discord webhook solo endorsement changes are posted to
+const SoloWebhook = "solo_endorsements"
+
+// SoloActive returns true if the endorsement is in effect at now
+func SoloActive(e *models.SoloEndorsement, now time.Time) bool {
+	return e.EndedAt == nil && !e.Pending && !now.Before(e.StartsAt) && now.Before(e.ExpiresAt)
+}
+
+// SoloDue returns true if the endorsement was issued for a later start that has now come, so the student should be
+// made solo
+func SoloDue(e *models.SoloEndorsement, now time.Time) bool {
+	return e.EndedAt == nil && e.Pending && !now.Before(e.StartsAt) && now.Before(e.ExpiresAt)
+}
+
+// SoloNeedsWarning returns true if the endorsement expires within warnBefore of now and nobody has been warned yet
+func SoloNeedsWarning(e *models.SoloEndorsement, now time.Time, warnBefore time.Duration) bool {
+	return e.WarnedAt == nil && SoloActive(e, now) && e.ExpiresAt.Sub(now) <= warnBefore
+}
+
+// SoloLapsed returns true if the endorsement has expired but has not been ended yet
+func SoloLapsed(e *models.SoloEndorsement, now time.Time) bool {
+	return e.EndedAt == nil && !now.Before(e.ExpiresAt)
+}
+
+// SoloRestoreValue returns the certification value to restore when the endorsement ends. If the endorsement never
+// started, or the student is no longer solo, for example because they were certified in the meantime, the
+// certification is left alone.
+func SoloRestoreValue(e *models.SoloEndorsement, current string) (string, bool) {
+	if e.Pending || current != constants.CertificationSolo {
+		return "", false
+	}
+	if e.PreviousValue == "" || e.PreviousValue == constants.CertificationSolo {
+		return constants.CertificationTraining, true
+	}
+
+	return e.PreviousValue, true
+}
+
+// NotifySolo posts a solo endorsement change to the training staff
+func NotifySolo(e *models.SoloEndorsement, content string) error {
+	embed := discord.NewEmbed().
+		AddField(discord.NewField().SetName("Student").SetValue(formatUser(e.Student, e.StudentID)).SetInline(true)).
+		AddField(discord.NewField().SetName("Position").SetValue(e.Position).SetInline(true)).
+		AddField(discord.NewField().SetName("Instructor").SetValue(formatUser(e.Instructor, e.InstructorID)).SetInline(true)).
+		AddField(discord.NewField().SetName("Expires").SetValue(e.ExpiresAt.UTC().Format("2006-01-02 15:04Z")).SetInline(true))
+
+	if e.Reason != "" {
+		embed.AddField(discord.NewField().SetName("Reason").SetValue(e.Reason).SetInline(false))
+	}
+
+	return discord.NewMessage().SetContent(content).AddEmbed(embed).Send(SoloWebhook)
+}
+
+func formatUser(user *models.User, cid uint) string {
+	if user == nil {
+		return fmt.Sprint(cid)
+	}
+
+	return fmt.Sprintf("%s %s (%d)", user.FirstName, user.LastName, user.CID)
+}
@@ -0,0 +1,102 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package training
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/adh-partnership/api/pkg/database/models"
+	"github.com/adh-partnership/api/pkg/database/models/constants"
+)
+
+func TestSoloLifecycle(t *testing.T) {
+	e := &models.SoloEndorsement{
+		StartsAt:  date(1, 0, 0),
+		ExpiresAt: date(15, 0, 0),
+	}
+	warnBefore := 5 * 24 * time.Hour
+
+	tests := []struct {
+		Name    string
+		Now     time.Time
+		Active  bool
+		Warning bool
+		Lapsed  bool
+	}{
+		{Name: "Not started", Now: date(1, 0, 0).Add(-time.Hour)},
+		{Name: "Active", Now: date(2, 0, 0), Active: true},
+		{Name: "Expiring", Now: date(12, 0, 0), Active: true, Warning: true},
+		{Name: "Expired", Now: date(15, 0, 0), Lapsed: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			assert.Equal(t, test.Active, SoloActive(e, test.Now))
+			assert.Equal(t, test.Warning, SoloNeedsWarning(e, test.Now, warnBefore))
+			assert.Equal(t, test.Lapsed, SoloLapsed(e, test.Now))
+		})
+	}
+
+	// An endorsement issued for a later start is due once it starts, and is not in effect until then
+	e.Pending = true
+	assert.False(t, SoloDue(e, date(1, 0, 0).Add(-time.Hour)))
+	assert.True(t, SoloDue(e, date(2, 0, 0)))
+	assert.False(t, SoloActive(e, date(2, 0, 0)))
+	assert.False(t, SoloDue(e, date(15, 0, 0)))
+	assert.True(t, SoloLapsed(e, date(15, 0, 0)))
+	e.Pending = false
+
+	// Only warn once, and never about an endorsement that has ended
+	e.WarnedAt = ptr(date(12, 0, 0))
+	assert.False(t, SoloNeedsWarning(e, date(13, 0, 0), warnBefore))
+	e.EndedAt = ptr(date(13, 0, 0))
+	assert.False(t, SoloActive(e, date(13, 0, 0)))
+	assert.False(t, SoloLapsed(e, date(16, 0, 0)))
+}
+
+func TestSoloRestoreValue(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Previous string
+		Current  string
+		Expected string
+		Restore  bool
+	}{
+		{
+			Name: "Back to training", Previous: constants.CertificationTraining, Current: constants.CertificationSolo,
+			Expected: constants.CertificationTraining, Restore: true,
+		},
+		{Name: "Back to none", Previous: constants.CertificationNone, Current: constants.CertificationSolo, Expected: constants.CertificationNone, Restore: true},
+		{Name: "Unknown previous", Previous: "", Current: constants.CertificationSolo, Expected: constants.CertificationTraining, Restore: true},
+		{Name: "Certified since", Previous: constants.CertificationTraining, Current: constants.CertificationCertified},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			value, ok := SoloRestoreValue(&models.SoloEndorsement{PreviousValue: test.Previous}, test.Current)
+			assert.Equal(t, test.Restore, ok)
+			assert.Equal(t, test.Expected, value)
+		})
+	}
+
+	// An endorsement that never started did not make the student solo
+	_, ok := SoloRestoreValue(&models.SoloEndorsement{Pending: true}, constants.CertificationSolo)
+	assert.False(t, ok)
+}
@@ -0,0 +1,89 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package training
+
+import (
+	"time"
+
+	"github.com/adh-partnership/api/pkg/database/models"
+	"github.com/adh-partnership/api/pkg/database/models/constants"
+)
+
+const (
+	// MaxSyncAttempts is the number of times a note is pushed to VATUSA before it is marked as failed
+	MaxSyncAttempts = 8
+	// MaxSyncBackoff caps the delay between two attempts
+	MaxSyncBackoff = 6 * time.Hour
+)
+
+// SyncBackoff returns the delay before the next attempt after attempts failed attempts, doubling from one minute
+func SyncBackoff(attempts int) time.Duration {
+	if attempts < 1 {
+		return 0
+	}
+	if attempts > 10 {
+		return MaxSyncBackoff
+	}
+
+	backoff := time.Duration(1<<(attempts-1)) * time.Minute
+	if backoff > MaxSyncBackoff {
+		return MaxSyncBackoff
+	}
+
+	return backoff
+}
+
+// QueueSync marks a note as pending so action is pushed to VATUSA. A note that has not been created in VATUSA yet
+// stays a create when it is edited.
+func QueueSync(note *models.TrainingNote, action string, now time.Time) {
+	if action == constants.TrainingNoteSyncActionUpdate && (note.VATUSAID == 0 || note.SyncAction == constants.TrainingNoteSyncActionCreate) {
+		action = constants.TrainingNoteSyncActionCreate
+	}
+
+	note.SyncStatus = constants.TrainingNoteSyncPending
+	note.SyncAction = action
+	note.SyncAttempts = 0
+	note.SyncError = ""
+	note.NextSyncAt = &now
+}
+
+// SyncFailed records a failed attempt, scheduling the next one or marking the note as failed once MaxSyncAttempts
+// is reached
+func SyncFailed(note *models.TrainingNote, err error, now time.Time) {
+	note.SyncAttempts++
+	note.SyncError = err.Error()
+
+	if note.SyncAttempts >= MaxSyncAttempts {
+		note.SyncStatus = constants.TrainingNoteSyncFailed
+		note.NextSyncAt = nil
+		return
+	}
+
+	next := now.Add(SyncBackoff(note.SyncAttempts))
+	note.SyncStatus = constants.TrainingNoteSyncPending
+	note.NextSyncAt = &next
+}
+
+// SyncSucceeded marks the note as in sync with VATUSA
+func SyncSucceeded(note *models.TrainingNote, now time.Time) {
+	note.SyncStatus = constants.TrainingNoteSyncSynced
+	note.SyncAction = ""
+	note.SyncAttempts = 0
+	note.SyncError = ""
+	note.NextSyncAt = nil
+	note.SyncedAt = &now
+}
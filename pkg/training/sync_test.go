@@ -0,0 +1,92 @@
+/*
+ * Copyright ADH Partnership
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package training
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/adh-partnership/api/pkg/database/models"
+	"github.com/adh-partnership/api/pkg/database/models/constants"
+)
+
+func TestSyncBackoff(t *testing.T) {
+	tests := []struct {
+		Attempts int
+		Expected time.Duration
+	}{
+		{Attempts: 0, Expected: 0},
+		{Attempts: 1, Expected: time.Minute},
+		{Attempts: 3, Expected: 4 * time.Minute},
+		{Attempts: 9, Expected: 256 * time.Minute},
+		{Attempts: 10, Expected: MaxSyncBackoff},
+		{Attempts: 64, Expected: MaxSyncBackoff},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprint(test.Attempts), func(t *testing.T) {
+			assert.Equal(t, test.Expected, SyncBackoff(test.Attempts))
+		})
+	}
+}
+
+func TestQueueSync(t *testing.T) {
+	now := date(8, 12, 0)
+
+	// Edits to a note VATUSA has not seen yet are still a create
+	note := &models.TrainingNote{}
+	QueueSync(note, constants.TrainingNoteSyncActionCreate, now)
+	QueueSync(note, constants.TrainingNoteSyncActionUpdate, now)
+	assert.Equal(t, constants.TrainingNoteSyncPending, note.SyncStatus)
+	assert.Equal(t, constants.TrainingNoteSyncActionCreate, note.SyncAction)
+	assert.Equal(t, &now, note.NextSyncAt)
+
+	note = &models.TrainingNote{VATUSAID: 10, SyncAttempts: 3, SyncError: "timeout"}
+	QueueSync(note, constants.TrainingNoteSyncActionUpdate, now)
+	assert.Equal(t, constants.TrainingNoteSyncActionUpdate, note.SyncAction)
+	assert.Zero(t, note.SyncAttempts)
+	assert.Empty(t, note.SyncError)
+
+	QueueSync(note, constants.TrainingNoteSyncActionDelete, now)
+	assert.Equal(t, constants.TrainingNoteSyncActionDelete, note.SyncAction)
+}
+
+func TestSyncFailed(t *testing.T) {
+	now := date(8, 12, 0)
+	note := &models.TrainingNote{}
+	QueueSync(note, constants.TrainingNoteSyncActionCreate, now)
+
+	SyncFailed(note, fmt.Errorf("VATUSA returned status code 500"), now)
+	assert.Equal(t, constants.TrainingNoteSyncPending, note.SyncStatus)
+	assert.Equal(t, 1, note.SyncAttempts)
+	assert.Equal(t, "VATUSA returned status code 500", note.SyncError)
+	assert.Equal(t, ptr(now.Add(time.Minute)), note.NextSyncAt)
+
+	for note.SyncAttempts < MaxSyncAttempts {
+		SyncFailed(note, fmt.Errorf("timeout"), now)
+	}
+	assert.Equal(t, constants.TrainingNoteSyncFailed, note.SyncStatus)
+	assert.Nil(t, note.NextSyncAt)
+
+	SyncSucceeded(note, now)
+	assert.Equal(t, constants.TrainingNoteSyncSynced, note.SyncStatus)
+	assert.Empty(t, note.SyncAction)
+	assert.Equal(t, &now, note.SyncedAt)
+}